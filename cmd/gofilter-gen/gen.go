@@ -0,0 +1,491 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// sourcePackage is a parsed package directory
+type sourcePackage struct {
+	name  string
+	specs map[string]*ast.TypeSpec // top-level type declarations by name
+	files map[string]*ast.File     // file declaring each type
+	order []string                 // type names in declaration order
+}
+
+// parsePackage parses the non-test Go files of a directory, skipping the
+// generator's own output
+func parsePackage(dir, output string) (*sourcePackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &sourcePackage{
+		specs: make(map[string]*ast.TypeSpec),
+		files: make(map[string]*ast.File),
+	}
+	fset := token.NewFileSet()
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == output {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.name == "" {
+			pkg.name = file.Name.Name
+		} else if pkg.name != file.Name.Name {
+			return nil, fmt.Errorf("%s: found packages %s and %s", dir, pkg.name, file.Name.Name)
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				pkg.specs[ts.Name.Name] = ts
+				pkg.files[ts.Name.Name] = file
+				pkg.order = append(pkg.order, ts.Name.Name)
+			}
+		}
+	}
+
+	if pkg.name == "" {
+		return nil, fmt.Errorf("%s: no Go files", dir)
+	}
+	return pkg, nil
+}
+
+// structType is a struct to generate a schema for
+type structType struct {
+	Name   string
+	Fields []structField
+}
+
+// structField is one tagged field of a struct
+type structField struct {
+	Name       string
+	Column     string
+	Type       string // Go source of the field type
+	Filterable bool
+	Sortable   bool
+	Searchable bool
+	// Layouts are the layout= options of a string field holding dates
+	Layouts []string
+
+	// Basic is the underlying basic type of the field, or "" when the field
+	// is read with reflection
+	Basic string
+	// ParseFunc, BitSize and Value build the field's coercion for Basic
+	// fields other than strings
+	ParseFunc string
+	BitSize   int
+	Value     string
+}
+
+// Ordered reports whether the field has an order, so it can be compared and sorted
+func (f structField) Ordered() bool {
+	return f.Basic != "" && f.Basic != "bool"
+}
+
+// basicTypes maps the basic types accessors are generated for to the
+// strconv function and bit size that parse them
+var basicTypes = map[string]struct {
+	parse   string
+	bitSize int
+}{
+	"string":  {"", 0},
+	"bool":    {"ParseBool", 0},
+	"int":     {"ParseInt", 64},
+	"int8":    {"ParseInt", 8},
+	"int16":   {"ParseInt", 16},
+	"int32":   {"ParseInt", 32},
+	"int64":   {"ParseInt", 64},
+	"uint":    {"ParseUint", 64},
+	"uint8":   {"ParseUint", 8},
+	"uint16":  {"ParseUint", 16},
+	"uint32":  {"ParseUint", 32},
+	"uint64":  {"ParseUint", 64},
+	"float32": {"ParseFloat", 32},
+	"float64": {"ParseFloat", 64},
+}
+
+// generator collects the structs and imports of the generated file
+type generator struct {
+	pkg     *sourcePackage
+	imports map[string]string // import path -> name
+}
+
+// generate returns the formatted source registering the schemas of the
+// named structs, or of every struct with a gofilter tag when types is empty
+func generate(pkg *sourcePackage, typeNames []string) ([]byte, error) {
+	g := &generator{pkg: pkg, imports: make(map[string]string)}
+
+	explicit := len(typeNames) > 0
+	if !explicit {
+		typeNames = pkg.order
+	}
+
+	var structs []structType
+	for _, name := range typeNames {
+		name = strings.TrimSpace(name)
+		st, err := g.structType(name)
+		if err != nil {
+			if explicit {
+				return nil, err
+			}
+			continue
+		}
+		if len(st.Fields) > 0 || explicit {
+			structs = append(structs, st)
+		}
+	}
+
+	if len(structs) == 0 {
+		return nil, fmt.Errorf("no structs with gofilter tags in package %s", pkg.name)
+	}
+
+	return g.render(structs)
+}
+
+// structType reads the tagged fields of a struct declaration
+func (g *generator) structType(name string) (structType, error) {
+	spec, ok := g.pkg.specs[name]
+	if !ok {
+		return structType{}, fmt.Errorf("type %s not found in package %s", name, g.pkg.name)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok || spec.TypeParams != nil {
+		return structType{}, fmt.Errorf("type %s is not a non-generic struct", name)
+	}
+
+	result := structType{Name: name}
+	file := g.pkg.files[name]
+
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tagValue, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return structType{}, err
+		}
+		tag := reflect.StructTag(tagValue).Get("gofilter")
+		if tag == "" {
+			continue
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{embeddedName(field.Type)}
+		}
+
+		typeSource, err := g.typeSource(field.Type, file)
+		if err != nil {
+			return structType{}, fmt.Errorf("%s: %w", name, err)
+		}
+
+		for _, ident := range names {
+			f := structField{
+				Name:   ident.Name,
+				Column: toSnakeCase(ident.Name),
+				Type:   typeSource,
+				Basic:  g.basicType(field.Type, 0),
+			}
+			parseTag(tag, &f)
+			g.coercion(&f)
+			result.Fields = append(result.Fields, f)
+		}
+	}
+
+	return result, nil
+}
+
+// parseTag applies the options of a gofilter tag, like query.parseStructTags
+func parseTag(tag string, f *structField) {
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "filterable":
+			f.Filterable = true
+		case part == "sortable":
+			f.Sortable = true
+		case part == "searchable":
+			f.Searchable = true
+		case strings.HasPrefix(part, "column="):
+			f.Column = strings.TrimPrefix(part, "column=")
+		case strings.HasPrefix(part, "layout="):
+			f.Layouts = append(f.Layouts, strings.TrimPrefix(part, "layout="))
+		}
+	}
+}
+
+// coercion fills in how a basic field is parsed from a query parameter
+func (g *generator) coercion(f *structField) {
+	if f.Basic == "" {
+		return
+	}
+
+	basic := basicTypes[f.Basic]
+	f.ParseFunc = basic.parse
+	f.BitSize = basic.bitSize
+
+	// strconv returns int64, uint64, float64 or bool
+	parsed := map[string]string{"ParseInt": "int64", "ParseUint": "uint64", "ParseFloat": "float64", "ParseBool": "bool"}[basic.parse]
+	source := "v"
+	if basic.parse == "" {
+		parsed, source = "string", "raw"
+	}
+	f.Value = source
+	if f.Type != parsed {
+		f.Value = f.Type + "(" + source + ")"
+	}
+
+	if f.ParseFunc != "" {
+		g.imports["strconv"] = "strconv"
+		g.imports["fmt"] = "fmt"
+	}
+}
+
+// basicType returns the underlying basic type of a field type declared in
+// the package, or "" when it is not a basic type accessors support
+func (g *generator) basicType(expr ast.Expr, depth int) string {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || depth > 10 {
+		return ""
+	}
+
+	switch ident.Name {
+	case "byte":
+		return "uint8"
+	case "rune":
+		return "int32"
+	}
+	if _, ok := basicTypes[ident.Name]; ok {
+		return ident.Name
+	}
+
+	if spec, ok := g.pkg.specs[ident.Name]; ok && spec.TypeParams == nil && !spec.Assign.IsValid() {
+		return g.basicType(spec.Type, depth+1)
+	}
+	return ""
+}
+
+// typeSource returns the Go source of a field type, recording the imports
+// it needs
+func (g *generator) typeSource(expr ast.Expr, file *ast.File) (string, error) {
+	var err error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		importPath, found := fileImport(file, x.Name)
+		if !found {
+			err = fmt.Errorf("cannot resolve package %s", x.Name)
+			return false
+		}
+		if existing, ok := g.imports[importPath]; ok && existing != x.Name {
+			err = fmt.Errorf("package %s is imported as both %s and %s", importPath, existing, x.Name)
+			return false
+		}
+		g.imports[importPath] = x.Name
+		return false
+	})
+
+	return types.ExprString(expr), err
+}
+
+// fileImport returns the path of the package a file imports under name
+func fileImport(file *ast.File, name string) (string, bool) {
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return importPath, true
+			}
+			continue
+		}
+		if path.Base(importPath) == name {
+			return importPath, true
+		}
+	}
+	return "", false
+}
+
+// embeddedName returns the field name of an embedded field
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.Ident:
+		return t
+	}
+	return ast.NewIdent("")
+}
+
+// toSnakeCase converts a field name to its default column name. It must
+// match the conversion used by the query package.
+func toSnakeCase(s string) string {
+	var result []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				if unicode.IsLower(prev) {
+					result = append(result, '_')
+				} else if unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+					result = append(result, '_')
+				}
+			}
+			result = append(result, unicode.ToLower(r))
+		} else {
+			result = append(result, r)
+		}
+	}
+	return string(result)
+}
+
+// render executes the output template and formats the result
+func (g *generator) render(structs []structType) ([]byte, error) {
+	g.imports["reflect"] = "reflect"
+	g.imports["github.com/sidneip/gofilter/query"] = "query"
+	for _, st := range structs {
+		for _, f := range st.Fields {
+			if f.Basic != "" {
+				g.imports["github.com/sidneip/gofilter/filter"] = "filter"
+			}
+			if f.Ordered() {
+				g.imports["cmp"] = "cmp"
+			}
+		}
+	}
+
+	var std, thirdParty []string
+	for importPath, name := range g.imports {
+		spec := strconv.Quote(importPath)
+		if name != path.Base(importPath) {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") {
+			thirdParty = append(thirdParty, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(thirdParty)
+
+	var buf bytes.Buffer
+	err := outputTemplate.Execute(&buf, map[string]interface{}{
+		"Package":    g.pkg.name,
+		"Std":        std,
+		"ThirdParty": thirdParty,
+		"Structs":    structs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+var outputTemplate = template.Must(template.New("output").Parse(`// Code generated by gofilter-gen; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Std}}
+	{{.}}
+{{- end}}
+{{if .ThirdParty}}
+{{- range .ThirdParty}}
+	{{.}}
+{{- end}}
+{{- end}}
+)
+{{range $st := .Structs}}
+func init() {
+	query.RegisterSchema(query.Schema[{{$st.Name}}]{
+		Fields: []query.SchemaField[{{$st.Name}}]{
+{{- range $st.Fields}}
+			{
+				Name:       {{printf "%q" .Name}},
+				Column:     {{printf "%q" .Column}},
+				Type:       reflect.TypeFor[{{.Type}}](),
+				Filterable: {{.Filterable}},
+				Sortable:   {{.Sortable}},
+				Searchable: {{.Searchable}},
+{{- if .Layouts}}
+				Layouts:    {{printf "%#v" .Layouts}},
+{{- end}}
+{{- if .Basic}}
+				Coerce: func(raw string) (interface{}, error) {
+{{- if .ParseFunc}}
+					v, err := strconv.{{.ParseFunc}}(raw{{if eq .ParseFunc "ParseInt" "ParseUint"}}, 10{{end}}{{if .BitSize}}, {{.BitSize}}{{end}})
+					if err != nil {
+						return nil, fmt.Errorf("cannot parse %q as {{.Basic}}: %w", raw, err)
+					}
+{{- end}}
+					return {{.Value}}, nil
+				},
+				Accessor: filter.Accessor[{{$st.Name}}]{
+					Type: reflect.TypeFor[{{.Type}}](),
+					Get: func(item {{$st.Name}}) interface{} {
+						return item.{{.Name}}
+					},
+					Equal: func(item {{$st.Name}}, value interface{}) bool {
+						return item.{{.Name}} == value.({{.Type}})
+					},
+{{- if .Ordered}}
+					Compare: func(item {{$st.Name}}, value interface{}) int {
+						return cmp.Compare(item.{{.Name}}, value.({{.Type}}))
+					},
+					CompareItems: func(a, b {{$st.Name}}) int {
+						return cmp.Compare(a.{{.Name}}, b.{{.Name}})
+					},
+{{- end}}
+				},
+{{- end}}
+			},
+{{- end}}
+		},
+	})
+}
+{{end}}`))
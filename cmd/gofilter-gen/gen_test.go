@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateExample(t *testing.T) {
+	dir := filepath.Join("..", "..", "examples", "codegen")
+
+	pkg, err := parsePackage(dir, "gofilter_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate(pkg, []string{"Product"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile(filepath.Join(dir, "gofilter_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("examples/codegen/gofilter_gen.go is out of date; run go generate ./examples/codegen")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	src := `package shop
+
+import (
+	"time"
+
+	money "example.com/currency"
+)
+
+type Level int8
+
+type Order struct {
+	ID       uint64         ` + "`gofilter:\"filterable,sortable,column=order_id\"`" + `
+	Level    Level          ` + "`gofilter:\"filterable\"`" + `
+	Code     byte           ` + "`gofilter:\"filterable\"`" + `
+	Total    money.Amount   ` + "`gofilter:\"filterable\"`" + `
+	PlacedAt *time.Time     ` + "`gofilter:\"sortable\"`" + `
+	PaidOn   string         ` + "`gofilter:\"filterable,layout=02/01/2006\"`" + `
+	Note     string
+}
+
+type Untagged struct {
+	Name string
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "shop.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := parsePackage(dir, "gofilter_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := generate(pkg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(out)
+
+	for _, want := range []string{
+		"package shop",
+		`money "example.com/currency"`,
+		`"time"`,
+		"query.Schema[Order]",
+		`Column:     "order_id"`,
+		"strconv.ParseUint(raw, 10, 64)",
+		"return Level(v), nil",
+		"strconv.ParseInt(raw, 10, 8)",
+		"return byte(v), nil",
+		"reflect.TypeFor[money.Amount]()",
+		"reflect.TypeFor[*time.Time]()",
+		`[]string{"02/01/2006"}`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("expected generated code to contain %q:\n%s", want, code)
+		}
+	}
+
+	for _, unwanted := range []string{"Untagged", `"Note"`, "item.Total", "item.PlacedAt"} {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("expected generated code not to contain %q", unwanted)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := t.TempDir()
+	src := "package shop\n\ntype Level int\n\ntype Box[T any] struct {\n\tValue T `gofilter:\"filterable\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "shop.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := parsePackage(dir, "gofilter_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, types := range [][]string{{"Missing"}, {"Level"}, {"Box"}, nil} {
+		if _, err := generate(pkg, types); err == nil {
+			t.Errorf("expected an error generating %v", types)
+		}
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Name":      "name",
+		"CreatedAt": "created_at",
+		"UserID":    "user_id",
+		"HTTPCode":  "http_code",
+	}
+	for in, want := range tests {
+		if got := toSnakeCase(in); got != want {
+			t.Errorf("toSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
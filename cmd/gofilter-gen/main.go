@@ -0,0 +1,69 @@
+// Command gofilter-gen generates reflection-free field accessors and query
+// schemas for structs with gofilter tags.
+//
+// For each struct it emits an init function that registers a query.Schema:
+// per-field coercion of query parameter values, and filter.Accessor functions
+// that read, compare and sort the field directly. Apply, BuildFilter and the
+// filter package operators use the generated code automatically and fall
+// back to reflection for types and fields it does not cover.
+//
+// Usage:
+//
+//	//go:generate go run github.com/sidneip/gofilter/cmd/gofilter-gen -type=User,Order
+//
+// Flags:
+//
+//	-type    comma-separated struct names (default: every struct with a gofilter tag)
+//	-output  output file name (default: gofilter_gen.go)
+//
+// The package directory defaults to the current directory, which is the
+// package directory when run by go generate.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct names; default is every struct with a gofilter tag")
+	output := flag.String("output", "gofilter_gen.go", "output file name, relative to the package directory")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gofilter-gen [-type T1,T2] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	if err := run(dir, *output, types); err != nil {
+		fmt.Fprintf(os.Stderr, "gofilter-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the accessors of a package directory into its output file
+func run(dir, output string, types []string) error {
+	pkg, err := parsePackage(dir, output)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg, types)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
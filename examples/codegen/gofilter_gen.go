@@ -0,0 +1,165 @@
+// Code generated by gofilter-gen; DO NOT EDIT.
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/sidneip/gofilter/filter"
+	"github.com/sidneip/gofilter/query"
+)
+
+func init() {
+	query.RegisterSchema(query.Schema[Product]{
+		Fields: []query.SchemaField[Product]{
+			{
+				Name:       "Name",
+				Column:     "name",
+				Type:       reflect.TypeFor[string](),
+				Filterable: true,
+				Sortable:   true,
+				Searchable: true,
+				Coerce: func(raw string) (interface{}, error) {
+					return raw, nil
+				},
+				Accessor: filter.Accessor[Product]{
+					Type: reflect.TypeFor[string](),
+					Get: func(item Product) interface{} {
+						return item.Name
+					},
+					Equal: func(item Product, value interface{}) bool {
+						return item.Name == value.(string)
+					},
+					Compare: func(item Product, value interface{}) int {
+						return cmp.Compare(item.Name, value.(string))
+					},
+					CompareItems: func(a, b Product) int {
+						return cmp.Compare(a.Name, b.Name)
+					},
+				},
+			},
+			{
+				Name:       "Category",
+				Column:     "category",
+				Type:       reflect.TypeFor[Category](),
+				Filterable: true,
+				Sortable:   false,
+				Searchable: false,
+				Coerce: func(raw string) (interface{}, error) {
+					return Category(raw), nil
+				},
+				Accessor: filter.Accessor[Product]{
+					Type: reflect.TypeFor[Category](),
+					Get: func(item Product) interface{} {
+						return item.Category
+					},
+					Equal: func(item Product, value interface{}) bool {
+						return item.Category == value.(Category)
+					},
+					Compare: func(item Product, value interface{}) int {
+						return cmp.Compare(item.Category, value.(Category))
+					},
+					CompareItems: func(a, b Product) int {
+						return cmp.Compare(a.Category, b.Category)
+					},
+				},
+			},
+			{
+				Name:       "Price",
+				Column:     "price",
+				Type:       reflect.TypeFor[float64](),
+				Filterable: true,
+				Sortable:   true,
+				Searchable: false,
+				Coerce: func(raw string) (interface{}, error) {
+					v, err := strconv.ParseFloat(raw, 64)
+					if err != nil {
+						return nil, fmt.Errorf("cannot parse %q as float64: %w", raw, err)
+					}
+					return v, nil
+				},
+				Accessor: filter.Accessor[Product]{
+					Type: reflect.TypeFor[float64](),
+					Get: func(item Product) interface{} {
+						return item.Price
+					},
+					Equal: func(item Product, value interface{}) bool {
+						return item.Price == value.(float64)
+					},
+					Compare: func(item Product, value interface{}) int {
+						return cmp.Compare(item.Price, value.(float64))
+					},
+					CompareItems: func(a, b Product) int {
+						return cmp.Compare(a.Price, b.Price)
+					},
+				},
+			},
+			{
+				Name:       "Stock",
+				Column:     "qty",
+				Type:       reflect.TypeFor[uint](),
+				Filterable: true,
+				Sortable:   true,
+				Searchable: false,
+				Coerce: func(raw string) (interface{}, error) {
+					v, err := strconv.ParseUint(raw, 10, 64)
+					if err != nil {
+						return nil, fmt.Errorf("cannot parse %q as uint: %w", raw, err)
+					}
+					return uint(v), nil
+				},
+				Accessor: filter.Accessor[Product]{
+					Type: reflect.TypeFor[uint](),
+					Get: func(item Product) interface{} {
+						return item.Stock
+					},
+					Equal: func(item Product, value interface{}) bool {
+						return item.Stock == value.(uint)
+					},
+					Compare: func(item Product, value interface{}) int {
+						return cmp.Compare(item.Stock, value.(uint))
+					},
+					CompareItems: func(a, b Product) int {
+						return cmp.Compare(a.Stock, b.Stock)
+					},
+				},
+			},
+			{
+				Name:       "Active",
+				Column:     "active",
+				Type:       reflect.TypeFor[bool](),
+				Filterable: true,
+				Sortable:   false,
+				Searchable: false,
+				Coerce: func(raw string) (interface{}, error) {
+					v, err := strconv.ParseBool(raw)
+					if err != nil {
+						return nil, fmt.Errorf("cannot parse %q as bool: %w", raw, err)
+					}
+					return v, nil
+				},
+				Accessor: filter.Accessor[Product]{
+					Type: reflect.TypeFor[bool](),
+					Get: func(item Product) interface{} {
+						return item.Active
+					},
+					Equal: func(item Product, value interface{}) bool {
+						return item.Active == value.(bool)
+					},
+				},
+			},
+			{
+				Name:       "CreatedAt",
+				Column:     "created_at",
+				Type:       reflect.TypeFor[time.Time](),
+				Filterable: true,
+				Sortable:   true,
+				Searchable: false,
+			},
+		},
+	})
+}
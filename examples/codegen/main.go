@@ -0,0 +1,54 @@
+package main
+
+//go:generate go run github.com/sidneip/gofilter/cmd/gofilter-gen -type=Product
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/sidneip/gofilter/query"
+)
+
+// Category is a named type; generated accessors compare it without reflection
+type Category string
+
+type Product struct {
+	Name      string    `json:"name" gofilter:"filterable,sortable,searchable"`
+	Category  Category  `json:"category" gofilter:"filterable"`
+	Price     float64   `json:"price" gofilter:"filterable,sortable"`
+	Stock     uint      `json:"stock" gofilter:"filterable,sortable,column=qty"`
+	Active    bool      `json:"active" gofilter:"filterable"`
+	CreatedAt time.Time `json:"created_at" gofilter:"filterable,sortable"`
+	Tags      []string  `json:"tags"`
+}
+
+func main() {
+	products := []Product{
+		{Name: "Keyboard", Category: "hardware", Price: 49.9, Stock: 12, Active: true},
+		{Name: "Mouse", Category: "hardware", Price: 19.9, Stock: 0, Active: true},
+		{Name: "Monitor", Category: "hardware", Price: 199, Stock: 3, Active: false},
+		{Name: "Editor", Category: "software", Price: 59, Stock: 100, Active: true},
+	}
+
+	// gofilter_gen.go registers a schema for Product, so this query is parsed,
+	// filtered and sorted without reflection
+	params := url.Values{
+		"category": {"hardware"},
+		"active":   {"true"},
+		"qty_gt":   {"0"},
+		"sort":     {"-price"},
+	}
+
+	fmt.Println("Query: ?category=hardware&active=true&qty_gt=0&sort=-price")
+	result, err := query.Apply(products, params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(data))
+}
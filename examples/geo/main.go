@@ -75,4 +75,32 @@ func main() {
 	for i, loc := range sortedLocations {
 		fmt.Printf("%d. %s\n", i+1, loc.Name)
 	}
+
+	// Example 5: Load zones from GeoJSON and find the locations in each
+	zones, err := filter.ParseGeoJSON([]byte(`{
+		"type": "FeatureCollection",
+		"features": [{
+			"type": "Feature",
+			"properties": {"name": "Western Europe"},
+			"geometry": {
+				"type": "Polygon",
+				"coordinates": [[[-10, 36], [10, 36], [10, 60], [-10, 60], [-10, 36]]]
+			}
+		}]
+	}`))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	for _, zone := range zones {
+		inZone := filter.Apply(locations,
+			filter.WithinMultiPolygon[Location]("Latitude", "Longitude", zone.Area),
+		)
+
+		fmt.Printf("\nLocations in %s:\n", zone.Properties["name"])
+		for _, loc := range inZone {
+			fmt.Printf("- %s\n", loc.Name)
+		}
+	}
 }
@@ -0,0 +1,115 @@
+package filter
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Accessor reads and compares one field of T without reflection. Accessors
+// are usually generated by cmd/gofilter-gen and registered through
+// query.RegisterSchema, but they can be written by hand and registered with
+// RegisterAccessors.
+//
+// Type, Get and Equal are required. Values passed to Equal and Compare have
+// already been converted to Type.
+type Accessor[T any] struct {
+	// Type is the type of the field
+	Type reflect.Type
+	// Get returns the value of the field
+	Get func(item T) interface{}
+	// Equal reports whether the field of item equals value
+	Equal func(item T, value interface{}) bool
+	// Compare compares the field of item with value and returns -1, 0 or +1.
+	// It is nil for fields without an order, such as bools.
+	Compare func(item T, value interface{}) int
+	// CompareItems compares the field of two items and returns -1, 0 or +1.
+	// It is nil for fields without an order, such as bools.
+	CompareItems func(a, b T) int
+}
+
+// accessors holds the registered accessors of each type
+var accessors sync.Map // reflect.Type -> map[string]Accessor[T]
+
+// RegisterAccessors registers the accessors of T's fields, keyed by field
+// name. Eq, Ne, Gt, Gte, Lt, Lte, Between, In, Sort, Asc and Desc built after
+// registration use them instead of reflection; fields without an accessor,
+// nested paths and values that cannot be converted to the field type still
+// go through reflection. Filters behave the same either way.
+//
+// Registering a type again replaces its accessors.
+//
+// Example:
+//
+//	filter.RegisterAccessors(map[string]filter.Accessor[User]{
+//	    "Age": {
+//	        Type:         reflect.TypeFor[int](),
+//	        Get:          func(u User) interface{} { return u.Age },
+//	        Equal:        func(u User, v interface{}) bool { return u.Age == v.(int) },
+//	        Compare:      func(u User, v interface{}) int { return cmp.Compare(u.Age, v.(int)) },
+//	        CompareItems: func(a, b User) int { return cmp.Compare(a.Age, b.Age) },
+//	    },
+//	})
+func RegisterAccessors[T any](fields map[string]Accessor[T]) {
+	registered := make(map[string]Accessor[T], len(fields))
+	for name, accessor := range fields {
+		registered[name] = accessor
+	}
+	accessors.Store(reflect.TypeFor[T](), registered)
+}
+
+// accessorFor returns the registered accessor of a field of T
+func accessorFor[T any](fieldName string) (Accessor[T], bool) {
+	fields, ok := accessors.Load(reflect.TypeFor[T]())
+	if !ok {
+		return Accessor[T]{}, false
+	}
+	accessor, ok := fields.(map[string]Accessor[T])[fieldName]
+	return accessor, ok
+}
+
+// compareAccessor returns the accessor of a field of T and value converted to
+// the field type, the same way compareValues converts it. ok is false when
+// the field has no accessor or the value cannot be converted.
+func compareAccessor[T any](fieldName string, value interface{}) (accessor Accessor[T], converted interface{}, ok bool) {
+	accessor, ok = accessorFor[T](fieldName)
+	if !ok {
+		return accessor, nil, false
+	}
+
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return accessor, nil, false
+	}
+	if v.Type() != accessor.Type {
+		// Values the field type cannot hold are left to reflection
+		if v, ok = convertExact(v, accessor.Type); !ok {
+			return accessor, nil, false
+		}
+	}
+
+	return accessor, v.Interface(), true
+}
+
+// boundAccessor returns a match function for a Gt, Gte, Lt or Lte bound that
+// uses a registered accessor, or nil when reflection is needed
+func boundAccessor[T any](op Op, fieldName string, value interface{}) func(item T) bool {
+	accessor, converted, ok := compareAccessor[T](fieldName, value)
+	if !ok {
+		return nil
+	}
+	if accessor.Compare == nil {
+		// Like compareValuesLess, fields without an order never match a bound
+		return func(T) bool { return false }
+	}
+
+	switch op {
+	case OpGt:
+		return func(item T) bool { return accessor.Compare(item, converted) > 0 }
+	case OpGte:
+		return func(item T) bool { return accessor.Compare(item, converted) >= 0 }
+	case OpLt:
+		return func(item T) bool { return accessor.Compare(item, converted) < 0 }
+	default:
+		return func(item T) bool { return accessor.Compare(item, converted) <= 0 }
+	}
+}
@@ -0,0 +1,129 @@
+package filter
+
+import (
+	"cmp"
+	"reflect"
+	"testing"
+)
+
+// generated is read through registered accessors, plain through reflection
+type generated struct {
+	Name   string
+	Score  float64
+	Active bool
+}
+
+type plain struct {
+	Name   string
+	Score  float64
+	Active bool
+}
+
+func init() {
+	RegisterAccessors(map[string]Accessor[generated]{
+		"Name": {
+			Type:         reflect.TypeFor[string](),
+			Get:          func(g generated) interface{} { return g.Name },
+			Equal:        func(g generated, v interface{}) bool { return g.Name == v.(string) },
+			Compare:      func(g generated, v interface{}) int { return cmp.Compare(g.Name, v.(string)) },
+			CompareItems: func(a, b generated) int { return cmp.Compare(a.Name, b.Name) },
+		},
+		"Score": {
+			Type:         reflect.TypeFor[float64](),
+			Get:          func(g generated) interface{} { return g.Score },
+			Equal:        func(g generated, v interface{}) bool { return g.Score == v.(float64) },
+			Compare:      func(g generated, v interface{}) int { return cmp.Compare(g.Score, v.(float64)) },
+			CompareItems: func(a, b generated) int { return cmp.Compare(a.Score, b.Score) },
+		},
+		"Active": {
+			Type:  reflect.TypeFor[bool](),
+			Get:   func(g generated) interface{} { return g.Active },
+			Equal: func(g generated, v interface{}) bool { return g.Active == v.(bool) },
+		},
+	})
+}
+
+func accessorFixtures() ([]generated, []plain) {
+	items := []plain{
+		{Name: "Ana", Score: 7.5, Active: true},
+		{Name: "Bia", Score: 9, Active: false},
+		{Name: "Caio", Score: 5, Active: true},
+		{Name: "Duda", Score: 9, Active: true},
+	}
+	gen := make([]generated, len(items))
+	for i, p := range items {
+		gen[i] = generated(p)
+	}
+	return gen, items
+}
+
+func TestAccessorFilters(t *testing.T) {
+	gen, items := accessorFixtures()
+
+	tests := []struct {
+		name      string
+		generated Filter[generated]
+		plain     Filter[plain]
+	}{
+		{"eq", Eq[generated]("Name", "Bia"), Eq[plain]("Name", "Bia")},
+		{"eq converted", Eq[generated]("Score", 9), Eq[plain]("Score", 9)},
+		{"eq unconvertible", Eq[generated]("Score", "9"), Eq[plain]("Score", "9")},
+		{"ne", Ne[generated]("Score", 9), Ne[plain]("Score", 9)},
+		{"gt", Gt[generated]("Score", 7), Gt[plain]("Score", 7)},
+		{"gte", Gte[generated]("Score", 7.5), Gte[plain]("Score", 7.5)},
+		{"lt", Lt[generated]("Name", "Caio"), Lt[plain]("Name", "Caio")},
+		{"lte", Lte[generated]("Name", "Caio"), Lte[plain]("Name", "Caio")},
+		{"bool eq", Eq[generated]("Active", true), Eq[plain]("Active", true)},
+		{"bool gt", Gt[generated]("Active", false), Gt[plain]("Active", false)},
+		{"in", In[generated]("Score", []interface{}{5, 9.0, "x"}), In[plain]("Score", []interface{}{5, 9.0, "x"})},
+		{"between", Between[generated]("Score", 6, 9), Between[plain]("Score", 6, 9)},
+	}
+
+	for _, tt := range tests {
+		want := Apply(items, tt.plain)
+		got := Apply(gen, tt.generated)
+		if len(got) != len(want) {
+			t.Errorf("%s: expected %d items, got %d", tt.name, len(want), len(got))
+			continue
+		}
+		for i := range got {
+			if plain(got[i]) != want[i] {
+				t.Errorf("%s: expected %v at %d, got %v", tt.name, want[i], i, got[i])
+			}
+		}
+		if !reflect.DeepEqual(Describe(tt.generated), Describe(tt.plain)) {
+			t.Errorf("%s: expected the same description, got %v and %v", tt.name, Describe(tt.generated), Describe(tt.plain))
+		}
+	}
+}
+
+func TestAccessorSort(t *testing.T) {
+	gen, items := accessorFixtures()
+
+	for _, asc := range []bool{true, false} {
+		got := Sort(gen, "Name", asc)
+		want := Sort(items, "Name", asc)
+		for i := range got {
+			if plain(got[i]) != want[i] {
+				t.Errorf("Sort ascending=%v: expected %v at %d, got %v", asc, want[i], i, got[i])
+			}
+		}
+	}
+
+	got := SortBy(gen, Desc[generated]("Score"), Asc[generated]("Name"))
+	want := SortBy(items, Desc[plain]("Score"), Asc[plain]("Name"))
+	for i := range got {
+		if plain(got[i]) != want[i] {
+			t.Errorf("SortBy: expected %v at %d, got %v", want[i], i, got[i])
+		}
+	}
+}
+
+func BenchmarkEqAccessor(b *testing.B) {
+	gen, _ := accessorFixtures()
+	f := Eq[generated]("Score", 9)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Apply(gen, f)
+	}
+}
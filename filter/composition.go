@@ -12,7 +12,7 @@ import "reflect"
 //	    filter.Eq[User]("Active", true),
 //	)  // users who are 18+ AND active
 func And[T any](filters ...Filter[T]) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
+	return newLogicalNode(OpAnd, filters, func(item T) bool {
 		for _, filter := range filters {
 			if !filter.Apply(item) {
 				return false
@@ -32,7 +32,7 @@ func And[T any](filters ...Filter[T]) Filter[T] {
 //	    filter.Eq[User]("City", "RJ"),
 //	)  // users from SP OR RJ
 func Or[T any](filters ...Filter[T]) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
+	return newLogicalNode(OpOr, filters, func(item T) bool {
 		for _, filter := range filters {
 			if filter.Apply(item) {
 				return true
@@ -49,7 +49,7 @@ func Or[T any](filters ...Filter[T]) Filter[T] {
 //
 //	filter.Not(filter.Eq[User]("Status", "banned"))  // users who are NOT banned
 func Not[T any](filter Filter[T]) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
+	return newLogicalNode(OpNot, []Filter[T]{filter}, func(item T) bool {
 		return !filter.Apply(item)
 	})
 }
@@ -61,19 +61,14 @@ func Not[T any](filter Filter[T]) Filter[T] {
 //
 //	filter.IsNil[User]("DeletedAt")  // users where DeletedAt is nil
 func IsNil[T any](fieldName string) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpIsNil}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		switch fieldValue.Kind() {
 		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func:
 			return fieldValue.IsNil()
 		default:
 			return false
 		}
-	})
+	}))
 }
 
 // IsNotNil returns a filter that checks if a field is not nil.
@@ -92,14 +87,9 @@ func IsNotNil[T any](fieldName string) Filter[T] {
 //
 //	filter.IsZero[User]("Score")  // users with Score == 0
 func IsZero[T any](fieldName string) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpIsZero}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		return fieldValue.IsZero()
-	})
+	}))
 }
 
 // IsNotZero returns a filter that checks if a field has a non-zero value.
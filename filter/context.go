@@ -0,0 +1,101 @@
+package filter
+
+import (
+	"context"
+	"slices"
+)
+
+// contextCheckInterval is the number of items or comparisons between two
+// checks of the context
+const contextCheckInterval = 1024
+
+// ApplyContext filters a slice like Apply, checking ctx every 1024 items so
+// that a slow filter stops soon after the context is canceled or its deadline
+// passes. It returns the context's cause in that case.
+//
+// Filtering is sequential unless Workers is given, in which case it runs like
+// ApplyParallel and every worker checks the context.
+//
+// Example:
+//
+//	adults, err := filter.ApplyContext(r.Context(), users, filter.Gte[User]("Age", 18))
+func ApplyContext[T any](ctx context.Context, items []T, filter Filter[T], opts ...ParallelOption) ([]T, error) {
+	o := parallelOptions{
+		workers:   1,
+		threshold: DefaultParallelThreshold,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	if ctx.Done() == nil {
+		// The context can never be canceled
+		return applyChunks(items, o, func(chunk []T) []T {
+			return Apply(chunk, filter)
+		}), nil
+	}
+
+	result := applyChunks(items, o, func(chunk []T) []T {
+		matched := make([]T, 0)
+		for i, item := range chunk {
+			if i%contextCheckInterval == 0 && ctx.Err() != nil {
+				return nil
+			}
+			if filter.Apply(item) {
+				matched = append(matched, item)
+			}
+		}
+		return matched
+	})
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SortByContext sorts like SortBy, checking ctx every 1024 comparisons. When
+// the context is canceled or its deadline passes, it stops sorting and
+// returns the context's cause.
+//
+// Example:
+//
+//	sorted, err := filter.SortByContext(ctx, users, filter.Desc[User]("Score"))
+func SortByContext[T any](ctx context.Context, items []T, keys ...SortKey[T]) (sorted []T, err error) {
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	if ctx.Done() == nil || len(keys) == 0 {
+		return SortBy(items, keys...), nil
+	}
+
+	result := make([]T, len(items))
+	copy(result, items)
+
+	// The sort cannot be interrupted, so a canceled comparison unwinds it with
+	// a panic recovered here
+	type canceled struct{ err error }
+	defer func() {
+		if r := recover(); r != nil {
+			c, ok := r.(canceled)
+			if !ok {
+				panic(r)
+			}
+			sorted, err = nil, c.err
+		}
+	}()
+
+	comparisons := 0
+	slices.SortStableFunc(result, func(a, b T) int {
+		comparisons++
+		if comparisons%contextCheckInterval == 0 && ctx.Err() != nil {
+			panic(canceled{context.Cause(ctx)})
+		}
+		return compareByKeys(a, b, keys)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyContext(t *testing.T) {
+	items := parallelItems(20_000)
+	f := Gte[Person]("Age", 45)
+	want := Apply(items, f)
+
+	got, err := ApplyContext(context.Background(), items, f)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %d items, got %d (%v)", len(want), len(got), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got, err = ApplyContext(ctx, items, f, Workers(4), Threshold(1))
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Workers(4): expected %d items, got %d (%v)", len(want), len(got), err)
+	}
+}
+
+func TestApplyContextCanceled(t *testing.T) {
+	items := parallelItems(20_000)
+	stop := errors.New("client went away")
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	calls := 0
+	f := Custom[Person](func(Person) bool {
+		calls++
+		if calls == 100 {
+			cancel(stop)
+		}
+		return true
+	})
+
+	got, err := ApplyContext(ctx, items, f)
+	if !errors.Is(err, stop) || got != nil {
+		t.Errorf("Expected the cancel cause, got %d items and %v", len(got), err)
+	}
+	if calls > 100+contextCheckInterval {
+		t.Errorf("Expected filtering to stop within %d items of cancellation, got %d calls", contextCheckInterval, calls)
+	}
+
+	if _, err := ApplyContext(ctx, items, f); !errors.Is(err, stop) {
+		t.Errorf("Expected a canceled context to fail before filtering, got %v", err)
+	}
+}
+
+func TestSortByContext(t *testing.T) {
+	items := parallelItems(5000)
+	keys := []SortKey[Person]{Desc[Person]("Age"), Asc[Person]("Name")}
+
+	got, err := SortByContext(context.Background(), items, keys...)
+	if err != nil || !reflect.DeepEqual(got, SortBy(items, keys...)) {
+		t.Errorf("Expected the same order as SortBy (%v)", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	comparisons := 0
+	counting := SortKey[Person]{Field: "Age", Ascending: true, compare: func(a, b Person) int {
+		comparisons++
+		if comparisons == 10 {
+			cancel()
+		}
+		return a.Age - b.Age
+	}}
+
+	got, err = SortByContext(ctx, items, counting)
+	if !errors.Is(err, context.Canceled) || got != nil {
+		t.Errorf("Expected context.Canceled, got %d items and %v", len(got), err)
+	}
+	if comparisons > contextCheckInterval {
+		t.Errorf("Expected sorting to stop within %d comparisons, got %d", contextCheckInterval, comparisons)
+	}
+}
@@ -9,7 +9,8 @@ import (
 )
 
 // ExportedGetFieldValue retrieves a field value from a struct by name.
-// Supports nested fields using dot notation (e.g., "Address.City").
+// Supports nested fields using dot notation (e.g., "Address.City"), map keys
+// and slice indexes (e.g., "tags.0").
 // This function is exported for use in custom filter implementations.
 //
 // Example:
@@ -22,6 +23,32 @@ func ExportedGetFieldValue(item interface{}, fieldPath string) (reflect.Value, e
 	return getFieldValue(item, fieldPath)
 }
 
+// ExportedFieldType returns the type of the values a field path reads from
+// items of type t, resolving json names for types registered with
+// UseJSONNames as filters do. Below an interface type, such as the values of
+// a map[string]interface{}, the type is the interface type.
+// This function is exported for packages that prepare structures per field,
+// such as indexes.
+//
+// Example:
+//
+//	t, err := filter.ExportedFieldType(reflect.TypeFor[User](), "Address.City")  // string
+func ExportedFieldType(t reflect.Type, fieldPath string) (reflect.Type, error) {
+	return fieldType(t, fieldPath)
+}
+
+// ExportedConvertExact converts v to t when the conversion keeps its value,
+// reporting false when a number would change sign, overflow or lose a
+// fraction. This function is exported for packages that look values up by
+// key, such as stores converting ids.
+//
+// Example:
+//
+//	id, ok := filter.ExportedConvertExact(reflect.ValueOf(1.9), reflect.TypeFor[int]())  // false
+func ExportedConvertExact(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	return convertExact(v, t)
+}
+
 // StringMatchMode defines different modes for string matching
 type StringMatchMode int
 
@@ -44,6 +71,51 @@ type StringMatchOptions struct {
 	IgnoreCase bool
 }
 
+// stringMatchOp returns the operator describing a StringMatch configuration
+func stringMatchOp(options StringMatchOptions) Op {
+	var op Op
+	switch options.Mode {
+	case ExactMatch:
+		op = OpExact
+	case ContainsMatch:
+		op = OpSubstring
+	case PrefixMatch:
+		op = OpPrefix
+	case SuffixMatch:
+		op = OpSuffix
+	default:
+		return OpCustom
+	}
+
+	if options.IgnoreCase {
+		return "i" + op
+	}
+	return op
+}
+
+// stringMatchOptions returns the StringMatch configuration described by an operator
+func stringMatchOptions(op Op) (StringMatchOptions, bool) {
+	var options StringMatchOptions
+	if strings.HasPrefix(string(op), "i") {
+		options.IgnoreCase = true
+		op = op[1:]
+	}
+
+	switch op {
+	case OpExact:
+		options.Mode = ExactMatch
+	case OpSubstring:
+		options.Mode = ContainsMatch
+	case OpPrefix:
+		options.Mode = PrefixMatch
+	case OpSuffix:
+		options.Mode = SuffixMatch
+	default:
+		return options, false
+	}
+	return options, true
+}
+
 // StringMatch returns a filter with configurable string matching behavior.
 // Supports exact match, contains, prefix, and suffix modes with optional case insensitivity.
 //
@@ -53,9 +125,8 @@ type StringMatchOptions struct {
 //	    Mode: filter.SuffixMatch, IgnoreCase: true,
 //	})  // users with email ending in "gmail.com" (case-insensitive)
 func StringMatch[T any](fieldName string, value string, options StringMatchOptions) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil || fieldValue.Kind() != reflect.String {
+	return newNode(Expr{Field: fieldName, Op: stringMatchOp(options), Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		if fieldValue.Kind() != reflect.String {
 			return false
 		}
 
@@ -85,17 +156,20 @@ func StringMatch[T any](fieldName string, value string, options StringMatchOptio
 		default:
 			return false
 		}
-	})
+	}))
+}
+
+// arrayContainsOp returns the operator describing an ArrayContains configuration
+func arrayContainsOp(ignoreCase bool) Op {
+	if ignoreCase {
+		return OpIArrayContains
+	}
+	return OpArrayContains
 }
 
 // ArrayContains checks if an array field contains a specific value
 func ArrayContains[T any](fieldName string, value interface{}, ignoreCase bool) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: arrayContainsOp(ignoreCase), Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Slice && fieldValue.Kind() != reflect.Array {
 			return false
 		}
@@ -103,7 +177,7 @@ func ArrayContains[T any](fieldName string, value interface{}, ignoreCase bool)
 		targetValue := reflect.ValueOf(value)
 
 		for i := 0; i < fieldValue.Len(); i++ {
-			elemValue := fieldValue.Index(i)
+			elemValue := unwrap(fieldValue.Index(i))
 
 			if ignoreCase && elemValue.Kind() == reflect.String && targetValue.Kind() == reflect.String {
 				if strings.EqualFold(elemValue.String(), targetValue.String()) {
@@ -118,17 +192,12 @@ func ArrayContains[T any](fieldName string, value interface{}, ignoreCase bool)
 		}
 
 		return false
-	})
+	}))
 }
 
 // ArrayContainsAny checks if an array contains any of the provided values
 func ArrayContainsAny[T any](fieldName string, values []interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpArrayContainsAny, Value: values}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Slice && fieldValue.Kind() != reflect.Array {
 			return false
 		}
@@ -146,17 +215,12 @@ func ArrayContainsAny[T any](fieldName string, values []interface{}) Filter[T] {
 		}
 
 		return false
-	})
+	}))
 }
 
 // ArrayContainsAll checks if an array contains all of the provided values
 func ArrayContainsAll[T any](fieldName string, values []interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpArrayContainsAll, Value: values}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Slice && fieldValue.Kind() != reflect.Array {
 			return false
 		}
@@ -180,7 +244,7 @@ func ArrayContainsAll[T any](fieldName string, values []interface{}) Filter[T] {
 		}
 
 		return true
-	})
+	}))
 }
 
 // Between returns a filter that checks if a field value is within a range (inclusive).
@@ -190,114 +254,55 @@ func ArrayContainsAll[T any](fieldName string, values []interface{}) Filter[T] {
 //
 //	filter.Between[User]("Age", 18, 65)  // users where 18 <= Age <= 65
 func Between[T any](fieldName string, min, max interface{}) Filter[T] {
-	return And[T](
+	expr := Expr{Field: fieldName, Op: OpBetween, Value: []interface{}{min, max}}
+
+	// Both bounds must hold for the same element of a wildcard
+	if hasWildcard(fieldName) {
+		minValue, maxValue := reflect.ValueOf(min), reflect.ValueOf(max)
+		return newNode(expr, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+			return compareBound(OpGte, fieldValue, minValue) && compareBound(OpLte, fieldValue, maxValue)
+		}))
+	}
+
+	inRange := And[T](
 		Gte[T](fieldName, min),
 		Lte[T](fieldName, max),
 	)
+	return newNode(expr, inRange.Apply)
 }
 
-// DateBefore returns a filter that checks if a date field is before the specified date
-func DateBefore[T any](fieldName string, date time.Time) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
-		// Extract time.Time from field
-		var fieldTime time.Time
-
-		switch fieldValue.Kind() {
-		case reflect.Struct:
-			if fieldValue.Type() == reflect.TypeOf(time.Time{}) {
-				fieldTime = fieldValue.Interface().(time.Time)
-			} else {
-				return false
-			}
-		case reflect.String:
-			// Try to parse string as date (try multiple formats)
-			formats := []string{
-				time.RFC3339,
-				"2006-01-02T15:04:05",
-				"2006-01-02",
-				"01/02/2006",
-				"02/01/2006",
-			}
-
-			for _, format := range formats {
-				parsedTime, err := time.Parse(format, fieldValue.String())
-				if err == nil {
-					fieldTime = parsedTime
-					break
-				}
-			}
-
-			// If we couldn't parse the date, return false
-			if fieldTime.IsZero() {
-				return false
-			}
-		default:
-			return false
-		}
-
-		return fieldTime.Before(date)
-	})
+// DateBefore returns a filter that checks if a date field is before the specified date.
+// The field can be a time.Time or a string, read with the layouts and time
+// zone of opts (see DateOptions).
+func DateBefore[T any](fieldName string, date time.Time, opts ...DateOptions) Filter[T] {
+	dates := dateOptions(opts)
+	parser := dates.parser()
+	return newNode(Expr{Field: fieldName, Op: OpDateBefore, Value: date, Dates: dates}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		fieldTime, ok := parser.timeOf(fieldValue)
+		return ok && fieldTime.Before(date)
+	}))
 }
 
-// DateAfter returns a filter that checks if a date field is after the specified date
-func DateAfter[T any](fieldName string, date time.Time) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
-		// Extract time.Time from field
-		var fieldTime time.Time
-
-		switch fieldValue.Kind() {
-		case reflect.Struct:
-			if fieldValue.Type() == reflect.TypeOf(time.Time{}) {
-				fieldTime = fieldValue.Interface().(time.Time)
-			} else {
-				return false
-			}
-		case reflect.String:
-			// Try to parse string as date (try multiple formats)
-			formats := []string{
-				time.RFC3339,
-				"2006-01-02T15:04:05",
-				"2006-01-02",
-				"01/02/2006",
-				"02/01/2006",
-			}
-
-			for _, format := range formats {
-				parsedTime, err := time.Parse(format, fieldValue.String())
-				if err == nil {
-					fieldTime = parsedTime
-					break
-				}
-			}
-
-			// If we couldn't parse the date, return false
-			if fieldTime.IsZero() {
-				return false
-			}
-		default:
-			return false
-		}
-
-		return fieldTime.After(date)
-	})
+// DateAfter returns a filter that checks if a date field is after the specified date.
+// String fields are read as in DateBefore.
+func DateAfter[T any](fieldName string, date time.Time, opts ...DateOptions) Filter[T] {
+	dates := dateOptions(opts)
+	parser := dates.parser()
+	return newNode(Expr{Field: fieldName, Op: OpDateAfter, Value: date, Dates: dates}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		fieldTime, ok := parser.timeOf(fieldValue)
+		return ok && fieldTime.After(date)
+	}))
 }
 
-// DateBetween returns a filter that checks if a date field is between two dates (inclusive)
-func DateBetween[T any](fieldName string, start, end time.Time) Filter[T] {
-	return And[T](
-		DateAfter[T](fieldName, start.Add(-1*time.Second)), // Make it inclusive of start time
-		DateBefore[T](fieldName, end.Add(1*time.Second)),   // Make it inclusive of end time
-	)
+// DateBetween returns a filter that checks if a date field is between two dates (inclusive).
+// String fields are read as in DateBefore. Use DateRange to exclude either end.
+func DateBetween[T any](fieldName string, start, end time.Time, opts ...DateOptions) Filter[T] {
+	dates := dateOptions(opts)
+	parser := dates.parser()
+	return newNode(Expr{Field: fieldName, Op: OpDateBetween, Value: []interface{}{start, end}, Dates: dates}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		fieldTime, ok := parser.timeOf(fieldValue)
+		return ok && !fieldTime.Before(start) && !fieldTime.After(end)
+	}))
 }
 
 // Sort returns a sorted copy of the slice based on a field value.
@@ -311,6 +316,17 @@ func Sort[T any](items []T, fieldName string, ascending bool) []T {
 	result := make([]T, len(items))
 	copy(result, items)
 
+	if compare := accessorCompare[T](fieldName); compare != nil {
+		sort.Slice(result, func(i, j int) bool {
+			less := compare(result[i], result[j]) < 0
+			if ascending {
+				return less
+			}
+			return !less
+		})
+		return result
+	}
+
 	sort.Slice(result, func(i, j int) bool {
 		fieldValueI, err := getFieldValue(result[i], fieldName)
 		if err != nil {
@@ -355,20 +371,21 @@ func Custom[T any](fn func(T) bool) Filter[T] {
 //
 //	filter.RegexMatch[User]("Email", `^[a-z]+@gmail\.com$`)  // Gmail users
 func RegexMatch[T any](fieldName, pattern string) Filter[T] {
+	expr := Expr{Field: fieldName, Op: OpRegex, Value: pattern}
+
 	regex, err := regexp.Compile(pattern)
 	if err != nil {
 		// If the pattern is invalid, the filter will never match
-		return FilterFunc[T](func(T) bool { return false })
+		return newNode(expr, func(T) bool { return false })
 	}
 
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil || fieldValue.Kind() != reflect.String {
+	return newNode(expr, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		if fieldValue.Kind() != reflect.String {
 			return false
 		}
 
 		return regex.MatchString(fieldValue.String())
-	})
+	}))
 }
 
 // NestedArrayAny filters based on a condition in any element of a nested array
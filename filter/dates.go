@@ -0,0 +1,411 @@
+package filter
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// TimePart is a calendar or clock component of a time, compared by DatePart.
+type TimePart string
+
+const (
+	// Year is the year, such as 2024
+	Year TimePart = "year"
+	// Month is the month of the year, 1 (January) to 12; time.Month values can be used
+	Month TimePart = "month"
+	// Day is the day of the month, 1 to 31
+	Day TimePart = "day"
+	// Weekday is the day of the week, 0 (Sunday) to 6; time.Weekday values can be used
+	Weekday TimePart = "weekday"
+	// Hour is the hour of the day, 0 to 23
+	Hour TimePart = "hour"
+	// Minute is the minute of the hour, 0 to 59
+	Minute TimePart = "minute"
+)
+
+// of returns the part of t
+func (p TimePart) of(t time.Time) int {
+	switch p {
+	case Year:
+		return t.Year()
+	case Month:
+		return int(t.Month())
+	case Day:
+		return t.Day()
+	case Weekday:
+		return int(t.Weekday())
+	case Hour:
+		return t.Hour()
+	default:
+		return t.Minute()
+	}
+}
+
+// repeats reports whether p cycles through the same values, as every part
+// but Year does
+func (p TimePart) repeats() bool {
+	return p != Year
+}
+
+// valid reports whether p is one of the TimePart constants
+func (p TimePart) valid() bool {
+	switch p {
+	case Year, Month, Day, Weekday, Hour, Minute:
+		return true
+	}
+	return false
+}
+
+// DatePartCondition is the operand of DatePart: the part of a time to
+// compare, how to compare it and in which time zone to read it.
+type DatePartCondition struct {
+	// Part is the component of the time to compare
+	Part TimePart
+	// Op is OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn or OpBetween
+	Op Op
+	// Value is an integer, a []interface{} of integers for OpIn or
+	// []interface{}{min, max} for OpBetween
+	Value interface{}
+	// Location is the time zone the part is read in; nil reads each time in
+	// its own location. JSON stores it by name, so zones made with
+	// time.FixedZone cannot be decoded.
+	Location *time.Location
+}
+
+// match returns the test the condition applies to a part, or false if the
+// condition is invalid
+func (c DatePartCondition) match() (func(n int) bool, bool) {
+	if !c.Part.valid() {
+		return nil, false
+	}
+
+	switch c.Op {
+	case OpIn:
+		values, ok := partValues(c.Value)
+		if !ok {
+			return nil, false
+		}
+		set := make(map[int]bool, len(values))
+		for _, v := range values {
+			set[v] = true
+		}
+		return func(n int) bool { return set[n] }, true
+
+	case OpBetween:
+		bounds, ok := partValues(c.Value)
+		if !ok || len(bounds) != 2 {
+			return nil, false
+		}
+		min, max := bounds[0], bounds[1]
+		// Ranges of repeating parts wrap around, as in 22 to 6 o'clock
+		if c.Part.repeats() && min > max {
+			return func(n int) bool { return n >= min || n <= max }, true
+		}
+		return func(n int) bool { return n >= min && n <= max }, true
+	}
+
+	v, ok := partValue(c.Value)
+	if !ok {
+		return nil, false
+	}
+	switch c.Op {
+	case OpEq:
+		return func(n int) bool { return n == v }, true
+	case OpNe:
+		return func(n int) bool { return n != v }, true
+	case OpGt:
+		return func(n int) bool { return n > v }, true
+	case OpGte:
+		return func(n int) bool { return n >= v }, true
+	case OpLt:
+		return func(n int) bool { return n < v }, true
+	case OpLte:
+		return func(n int) bool { return n <= v }, true
+	}
+	return nil, false
+}
+
+// partValue converts an integer operand, such as an int or a time.Weekday,
+// to int. Whole floats, as decoded from JSON, are accepted.
+func partValue(value interface{}) (int, bool) {
+	v := reflect.ValueOf(value)
+	switch {
+	case !v.IsValid():
+		return 0, false
+	case isInt(v.Kind()):
+		return int(v.Int()), true
+	case isUint(v.Kind()):
+		return int(v.Uint()), true
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		f := v.Float()
+		return int(f), f == float64(int(f))
+	}
+	return 0, false
+}
+
+// partValues converts a slice of integer operands to ints
+func partValues(value interface{}) ([]int, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	values := make([]int, v.Len())
+	for i := range values {
+		n, ok := partValue(v.Index(i).Interface())
+		if !ok {
+			return nil, false
+		}
+		values[i] = n
+	}
+	return values, true
+}
+
+// DatePart returns a filter that compares a component of a date field, such
+// as its weekday or hour, with value. Each time is read in its own location;
+// use DatePartIn to read it in a given time zone. op is OpEq, OpNe, OpGt,
+// OpGte, OpLt, OpLte, OpIn with a []interface{} of values or OpBetween with
+// []interface{}{min, max}. Between is inclusive, and wraps around for parts
+// that repeat when min is greater than max, so hours between 22 and 6 match
+// the night. Invalid conditions match nothing; Validate reports them.
+// String fields are read as in DateBefore.
+//
+// Example:
+//
+//	weekend := filter.DatePart[Order]("CreatedAt", filter.Weekday, filter.OpIn, []interface{}{time.Saturday, time.Sunday})
+//	q1 := filter.DatePart[Order]("CreatedAt", filter.Month, filter.OpLte, 3)
+func DatePart[T any](fieldName string, part TimePart, op Op, value interface{}, opts ...DateOptions) Filter[T] {
+	return DatePartIn[T](fieldName, part, nil, op, value, opts...)
+}
+
+// DatePartIn is DatePart with the part read in time zone loc, so that
+// "weekday" and "hour" mean what they mean to users in that zone.
+//
+// Example:
+//
+//	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+//	businessHours := filter.DatePartIn[Order]("CreatedAt", filter.Hour, saoPaulo, filter.OpBetween, []interface{}{9, 17})
+func DatePartIn[T any](fieldName string, part TimePart, loc *time.Location, op Op, value interface{}, opts ...DateOptions) Filter[T] {
+	condition := DatePartCondition{Part: part, Op: op, Value: value, Location: loc}
+	dates := dateOptions(opts)
+	expr := Expr{Field: fieldName, Op: OpDatePart, Value: condition, Dates: dates}
+	parser := dates.parser()
+
+	test, ok := condition.match()
+	if !ok {
+		return newNode(expr, func(T) bool { return false })
+	}
+	return newNode(expr, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		t, ok := parser.timeOf(fieldValue)
+		if !ok {
+			return false
+		}
+		if loc != nil {
+			t = t.In(loc)
+		}
+		return test(part.of(t))
+	}))
+}
+
+// TimeRange is a range of instants, the operand of DateRange. Both ends are
+// included unless excluded, and a zero Start or End leaves that end open.
+type TimeRange struct {
+	// Start is the earliest time in the range; zero for no limit
+	Start time.Time
+	// End is the latest time in the range; zero for no limit
+	End time.Time
+	// ExcludeStart leaves out times equal to Start
+	ExcludeStart bool
+	// ExcludeEnd leaves out times equal to End
+	ExcludeEnd bool
+}
+
+// contains reports whether t is in the range
+func (r TimeRange) contains(t time.Time) bool {
+	if !r.Start.IsZero() && (t.Before(r.Start) || (r.ExcludeStart && t.Equal(r.Start))) {
+		return false
+	}
+	if !r.End.IsZero() && (t.After(r.End) || (r.ExcludeEnd && t.Equal(r.End))) {
+		return false
+	}
+	return true
+}
+
+// DateRange returns a filter that checks if a date field is within r.
+// Times are compared as instants, to the nanosecond. Excluding the end gives
+// half-open ranges, which tile without overlap. String fields are read as
+// in DateBefore.
+//
+// Example:
+//
+//	march := filter.DateRange[Order]("CreatedAt", filter.TimeRange{
+//	    Start:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+//	    End:        time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+//	    ExcludeEnd: true,
+//	})
+func DateRange[T any](fieldName string, r TimeRange, opts ...DateOptions) Filter[T] {
+	dates := dateOptions(opts)
+	parser := dates.parser()
+	return newNode(Expr{Field: fieldName, Op: OpDateRange, Value: r, Dates: dates}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		t, ok := parser.timeOf(fieldValue)
+		return ok && r.contains(t)
+	}))
+}
+
+// SameDay returns a filter that checks if a date field falls on the calendar
+// day of day, in day's location. It is described as the DateRange from the
+// midnight that starts the day, included, to the next one, excluded.
+//
+// Example:
+//
+//	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+//	today := filter.SameDay[Order]("CreatedAt", time.Now().In(saoPaulo))
+func SameDay[T any](fieldName string, day time.Time, opts ...DateOptions) Filter[T] {
+	year, month, d := day.Date()
+	start := time.Date(year, month, d, 0, 0, 0, 0, day.Location())
+	return DateRange[T](fieldName, TimeRange{Start: start, End: start.AddDate(0, 0, 1), ExcludeEnd: true}, opts...)
+}
+
+// SameMonth returns a filter that checks if a date field falls in the
+// calendar month of month, in month's location. It is described as the
+// DateRange from the start of the month, included, to the start of the next,
+// excluded.
+//
+// Example:
+//
+//	thisMonth := filter.SameMonth[Order]("CreatedAt", time.Now())
+func SameMonth[T any](fieldName string, month time.Time, opts ...DateOptions) Filter[T] {
+	year, m, _ := month.Date()
+	start := time.Date(year, m, 1, 0, 0, 0, 0, month.Location())
+	return DateRange[T](fieldName, TimeRange{Start: start, End: start.AddDate(0, 1, 0), ExcludeEnd: true}, opts...)
+}
+
+// DateOptions sets how date filters read string fields. Pass it as the last
+// argument of a date filter; without it, strings are read with the default
+// layouts in UTC. The defaults accept both 01/02/2006 and 02/01/2006, so a
+// string such as 03/04/2024 reads as March 4th; set Layouts when a field
+// holds dates in one known format.
+//
+// Example:
+//
+//	european := filter.DateOptions{Layouts: []string{"02/01/2006"}}
+//	f := filter.DateAfter[Invoice]("IssuedOn", cutoff, european)
+type DateOptions struct {
+	// Layouts are the time.Parse layouts tried in order. The default layouts
+	// are RFC 3339, 2006-01-02T15:04:05, 2006-01-02, 01/02/2006 and 02/01/2006.
+	Layouts []string
+	// Location is the time zone of strings without a zone offset. The
+	// default is UTC. JSON stores it by name, as Location does in
+	// DatePartCondition.
+	Location *time.Location
+}
+
+// defaultDateLayouts are the layouts date filters parse string fields with
+// when DateOptions sets none
+var defaultDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+	"02/01/2006",
+}
+
+// maxCachedDates bounds the strings a date parser remembers
+const maxCachedDates = 10_000
+
+// dateParser reads string fields as times. Every date filter and ByDate sort
+// key has its own parser, which remembers the maxCachedDates strings it read
+// most recently, so repeated values are parsed once and the memory a filter
+// holds is bounded.
+type dateParser struct {
+	layouts []string
+	loc     *time.Location
+	limit   int
+
+	mu     sync.Mutex
+	recent *list.List // of *parsedDate, most recently used first
+	cache  map[string]*list.Element
+}
+
+// parsedDate is a cached parse result; ok is false for strings in no layout
+type parsedDate struct {
+	s  string
+	t  time.Time
+	ok bool
+}
+
+// dateOptions returns the options passed to a date filter, if any
+func dateOptions(opts []DateOptions) *DateOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	o := opts[0]
+	return &o
+}
+
+// parser returns a new parser for the options; nil options use the defaults
+func (o *DateOptions) parser() *dateParser {
+	p := &dateParser{
+		layouts: defaultDateLayouts,
+		loc:     time.UTC,
+		limit:   maxCachedDates,
+		recent:  list.New(),
+		cache:   make(map[string]*list.Element),
+	}
+	if o != nil && len(o.Layouts) > 0 {
+		p.layouts = o.Layouts
+	}
+	if o != nil && o.Location != nil {
+		p.loc = o.Location
+	}
+	return p
+}
+
+// parse reads s in the first layout that fits it, or returns the result
+// cached for s. The least recently used string is evicted once the parser
+// remembers more than its limit.
+func (p *dateParser) parse(s string) (time.Time, bool) {
+	p.mu.Lock()
+	if e, ok := p.cache[s]; ok {
+		p.recent.MoveToFront(e)
+		d := e.Value.(*parsedDate)
+		p.mu.Unlock()
+		return d.t, d.ok
+	}
+	p.mu.Unlock()
+
+	d := &parsedDate{s: s}
+	for _, layout := range p.layouts {
+		if t, err := time.ParseInLocation(layout, s, p.loc); err == nil {
+			d.t, d.ok = t, true
+			break
+		}
+	}
+
+	p.mu.Lock()
+	if _, ok := p.cache[s]; !ok {
+		p.cache[s] = p.recent.PushFront(d)
+		for p.recent.Len() > p.limit {
+			oldest := p.recent.Back()
+			p.recent.Remove(oldest)
+			delete(p.cache, oldest.Value.(*parsedDate).s)
+		}
+	}
+	p.mu.Unlock()
+	return d.t, d.ok
+}
+
+// timeOf returns the time a date field holds: a time.Time, or a string in
+// one of the parser's layouts
+func (p *dateParser) timeOf(fieldValue reflect.Value) (time.Time, bool) {
+	switch fieldValue.Kind() {
+	case reflect.Struct:
+		if fieldValue.Type() == timeType {
+			return fieldValue.Interface().(time.Time), true
+		}
+	case reflect.String:
+		return p.parse(fieldValue.String())
+	}
+	return time.Time{}, false
+}
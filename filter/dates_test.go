@@ -0,0 +1,287 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type Shift struct {
+	Name  string
+	Start time.Time
+	Day   string
+}
+
+func testShifts() []Shift {
+	return []Shift{
+		// Saturday
+		{Name: "weekend night", Start: time.Date(2024, 3, 16, 23, 0, 0, 0, time.UTC), Day: "2024-03-16"},
+		// Monday
+		{Name: "morning", Start: time.Date(2024, 3, 18, 9, 30, 0, 0, time.UTC), Day: "2024-03-18"},
+		// Monday
+		{Name: "early", Start: time.Date(2024, 3, 18, 2, 0, 0, 0, time.UTC), Day: "2024-03-18"},
+		// Wednesday
+		{Name: "april", Start: time.Date(2024, 4, 3, 14, 0, 0, 0, time.UTC), Day: "2024-04-03"},
+	}
+}
+
+func shiftNames(shifts []Shift) []string {
+	names := make([]string, len(shifts))
+	for i, s := range shifts {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func TestDatePart(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter[Shift]
+		want   []string
+	}{
+		{"weekday in", DatePart[Shift]("Start", Weekday, OpIn, []interface{}{time.Saturday, time.Sunday}), []string{"weekend night"}},
+		{"weekday eq", DatePart[Shift]("Start", Weekday, OpEq, time.Monday), []string{"morning", "early"}},
+		{"month", DatePart[Shift]("Start", Month, OpEq, time.April), []string{"april"}},
+		{"day of month", DatePart[Shift]("Start", Day, OpGt, 10), []string{"weekend night", "morning", "early"}},
+		{"year", DatePart[Shift]("Start", Year, OpNe, 2024), []string{}},
+		{"hour range", DatePart[Shift]("Start", Hour, OpBetween, []interface{}{9, 17}), []string{"morning", "april"}},
+		{"hour range wraps", DatePart[Shift]("Start", Hour, OpBetween, []interface{}{22, 6}), []string{"weekend night", "early"}},
+		{"minute", DatePart[Shift]("Start", Minute, OpGte, 30), []string{"morning"}},
+		{"string field", DatePart[Shift]("Day", Weekday, OpEq, time.Wednesday), []string{"april"}},
+		// In New York (UTC-4), the Monday 02:00 UTC shift starts on Sunday
+		{"in time zone", DatePartIn[Shift]("Start", Weekday, newYork, OpEq, time.Sunday), []string{"early"}},
+		{"hour in time zone", DatePartIn[Shift]("Start", Hour, newYork, OpLt, 6), []string{"morning"}},
+		{"invalid part", DatePart[Shift]("Start", TimePart("week"), OpEq, 1), []string{}},
+		{"invalid value", DatePart[Shift]("Start", Hour, OpEq, "nine"), []string{}},
+	}
+
+	for _, tt := range tests {
+		got := shiftNames(Apply(testShifts(), tt.filter))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	start := time.Date(2024, 3, 18, 9, 30, 0, 0, time.UTC)
+	end := time.Date(2024, 4, 3, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		r    TimeRange
+		want int
+	}{
+		{TimeRange{Start: start, End: end}, 2},
+		{TimeRange{Start: start, End: end, ExcludeStart: true}, 1},
+		{TimeRange{Start: start, End: end, ExcludeEnd: true}, 1},
+		{TimeRange{Start: start, End: end, ExcludeStart: true, ExcludeEnd: true}, 0},
+	}
+	for _, tt := range tests {
+		if got := Apply(testShifts(), DateRange[Shift]("Start", tt.r)); len(got) != tt.want {
+			t.Errorf("%s: expected %d shifts, got %v", Describe(DateRange[Shift]("Start", tt.r)), tt.want, shiftNames(got))
+		}
+	}
+
+	// Sub-second timestamps are compared exactly
+	precise := []Shift{{Name: "late", Start: end.Add(500 * time.Millisecond)}, {Name: "on time", Start: end}}
+	if got := Apply(precise, DateBetween[Shift]("Start", start, end)); len(got) != 1 || got[0].Name != "on time" {
+		t.Errorf("expected only the shift at the end to be between, got %v", shiftNames(got))
+	}
+	if got := Apply(precise, DateBetween[Shift]("Start", end.Add(time.Millisecond), end.Add(time.Second))); len(got) != 1 || got[0].Name != "late" {
+		t.Errorf("expected only the late shift to be between, got %v", shiftNames(got))
+	}
+}
+
+func TestSameDayAndMonth(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	monday := time.Date(2024, 3, 18, 12, 0, 0, 0, time.UTC)
+	if got := shiftNames(Apply(testShifts(), SameDay[Shift]("Start", monday))); len(got) != 2 {
+		t.Errorf("expected both Monday shifts, got %v", got)
+	}
+
+	// Monday in New York runs from 04:00 UTC Monday to 04:00 UTC Tuesday
+	got := shiftNames(Apply(testShifts(), SameDay[Shift]("Start", monday.In(newYork))))
+	if len(got) != 1 || got[0] != "morning" {
+		t.Errorf("expected only the morning shift on Monday in New York, got %v", got)
+	}
+
+	if got := shiftNames(Apply(testShifts(), SameMonth[Shift]("Start", monday))); len(got) != 3 {
+		t.Errorf("expected the March shifts, got %v", got)
+	}
+	if got := shiftNames(Apply(testShifts(), SameMonth[Shift]("Day", time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)))); len(got) != 1 || got[0] != "april" {
+		t.Errorf("expected the April shift, got %v", got)
+	}
+}
+
+func TestValidateDateFilters(t *testing.T) {
+	valid := And(
+		DatePart[Shift]("Start", Weekday, OpIn, []time.Weekday{time.Saturday, time.Sunday}),
+		DatePart[Shift]("Day", Month, OpBetween, []interface{}{11, 2}),
+		SameDay[Shift]("Start", time.Now()),
+	)
+	if err := Validate(valid); err != nil {
+		t.Errorf("expected date filters to validate, got %v", err)
+	}
+
+	for _, f := range []Filter[Shift]{
+		DatePart[Shift]("Finish", Hour, OpEq, 1),
+		DatePart[Shift]("Start", TimePart("week"), OpEq, 1),
+		DatePart[Shift]("Start", Hour, OpRegex, 1),
+		DatePart[Shift]("Start", Hour, OpBetween, []interface{}{1}),
+		DatePart[Shift]("Start", Hour, OpEq, 1.5),
+	} {
+		if err := Validate(f); err == nil {
+			t.Errorf("expected %s to be invalid", Describe(f))
+		}
+	}
+
+	for _, f := range []Filter[Person]{
+		DatePart[Person]("Age", Hour, OpEq, 1),
+		DateRange[Person]("Age", TimeRange{}),
+	} {
+		err := Validate(f)
+		var invalid *ErrInvalidOperand
+		if !errors.As(err, &invalid) {
+			t.Errorf("expected %s to be invalid on an int field, got %v", Describe(f), err)
+		}
+	}
+}
+
+type Invoice struct {
+	Number   int
+	IssuedOn string
+}
+
+func TestDateOptions(t *testing.T) {
+	invoices := []Invoice{
+		{Number: 1, IssuedOn: "03/04/2024"}, // 3 April
+		{Number: 2, IssuedOn: "25/03/2024"}, // 25 March
+		{Number: 3, IssuedOn: "not a date"},
+	}
+	april := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	european := DateOptions{Layouts: []string{"02/01/2006"}}
+
+	// The default layouts read 03/04/2024 as March 4th
+	if got := Apply(invoices, DateAfter[Invoice]("IssuedOn", april)); len(got) != 0 {
+		t.Errorf("expected no invoice after April 1st with the default layouts, got %v", got)
+	}
+	if got := Apply(invoices, DateAfter[Invoice]("IssuedOn", april, european)); len(got) != 1 || got[0].Number != 1 {
+		t.Errorf("expected invoice 1 after April 1st, got %v", got)
+	}
+	if got := Apply(invoices, DatePart[Invoice]("IssuedOn", Month, OpEq, time.April, european)); len(got) != 1 || got[0].Number != 1 {
+		t.Errorf("expected invoice 1 in April, got %v", got)
+	}
+	if got := Apply(invoices, SameMonth[Invoice]("IssuedOn", april, european)); len(got) != 1 || got[0].Number != 1 {
+		t.Errorf("expected invoice 1 in April, got %v", got)
+	}
+
+	// Strings without a zone are read in Location
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	inNewYork := DateOptions{Layouts: european.Layouts, Location: newYork}
+	midnight := time.Date(2024, 3, 25, 0, 0, 0, 0, newYork)
+	if got := Apply(invoices, DateBetween[Invoice]("IssuedOn", midnight, midnight, inNewYork)); len(got) != 1 || got[0].Number != 2 {
+		t.Errorf("expected invoice 2 at midnight in New York, got %v", got)
+	}
+	if got := Apply(invoices, DateBetween[Invoice]("IssuedOn", midnight, midnight, european)); len(got) != 0 {
+		t.Errorf("expected no invoice at midnight in New York when read in UTC, got %v", got)
+	}
+
+	checkRoundTrip(t, invoices, DateRange[Invoice]("IssuedOn", TimeRange{Start: april}, inNewYork))
+	data, _ := MarshalJSON(DateBefore[Invoice]("IssuedOn", april, inNewYork))
+	want := `{"field":"IssuedOn","op":"date_before","value":"2024-04-01T00:00:00Z","dates":{"layouts":["02/01/2006"],"location":"America/New_York"}}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+}
+
+func TestOpenTimeRange(t *testing.T) {
+	march18 := time.Date(2024, 3, 18, 9, 30, 0, 0, time.UTC)
+
+	since := DateRange[Shift]("Start", TimeRange{Start: march18})
+	if got := shiftNames(Apply(testShifts(), since)); len(got) != 2 {
+		t.Errorf("expected the shifts since the morning shift, got %v", got)
+	}
+	if got := Describe(since).String(); got != `Start IN ["2024-03-18T09:30:00Z", +inf]` {
+		t.Errorf("unexpected description %s", got)
+	}
+
+	until := DateRange[Shift]("Start", TimeRange{End: march18, ExcludeEnd: true})
+	if got := shiftNames(Apply(testShifts(), until)); len(got) != 2 {
+		t.Errorf("expected the shifts before the morning shift, got %v", got)
+	}
+}
+
+func TestSortByDate(t *testing.T) {
+	invoices := []Invoice{
+		{Number: 1, IssuedOn: "03/04/2024"},
+		{Number: 2, IssuedOn: "-"},
+		{Number: 3, IssuedOn: "25/03/2024"},
+		{Number: 4, IssuedOn: "01/12/2023"},
+	}
+	european := DateOptions{Layouts: []string{"02/01/2006"}}
+
+	numbers := func(invoices []Invoice) []int {
+		out := make([]int, len(invoices))
+		for i, inv := range invoices {
+			out[i] = inv.Number
+		}
+		return out
+	}
+
+	asc := numbers(SortBy(invoices, Asc[Invoice]("IssuedOn").ByDate(european)))
+	if fmt.Sprint(asc) != "[4 3 1 2]" {
+		t.Errorf("expected invoices by date with the undated one last, got %v", asc)
+	}
+	desc := numbers(SortBy(invoices, Desc[Invoice]("IssuedOn").ByDate(european)))
+	if fmt.Sprint(desc) != "[1 3 4 2]" {
+		t.Errorf("expected invoices by date descending with the undated one last, got %v", desc)
+	}
+}
+
+func TestDateParserCache(t *testing.T) {
+	opts := &DateOptions{Layouts: []string{"2006.01.02"}}
+	p := opts.parser()
+	for i := 0; i < 3; i++ {
+		for _, s := range []string{"2024.01.05", "2024.01.05", "2024.02.01", "never"} {
+			p.parse(s)
+		}
+	}
+	if size := len(p.cache); size != 3 {
+		t.Errorf("expected 3 distinct strings to be cached, got %d", size)
+	}
+
+	// Parsers are not shared between filters
+	if opts.parser() == p {
+		t.Error("expected each filter to get its own parser")
+	}
+
+	// The least recently used string is evicted
+	p.limit = 2
+	p.parse("2024.03.01")
+	if _, ok := p.cache["2024.02.01"]; ok || len(p.cache) != 2 {
+		t.Errorf("expected the least recently used strings to be evicted, got %d cached", len(p.cache))
+	}
+	if tm, ok := p.parse("2024.03.01"); !ok || tm.Month() != time.March {
+		t.Errorf("expected a cached date in March, got %v, %v", tm, ok)
+	}
+}
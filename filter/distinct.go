@@ -0,0 +1,110 @@
+package filter
+
+import (
+	"reflect"
+	"time"
+)
+
+// timeKey is the key of a time.Time value, which equals the key of any time
+// for the same instant
+type timeKey struct {
+	sec  int64
+	nsec int
+}
+
+// valueKey returns a comparable key for a field value, normalized so that
+// values considered equal by compareValues map to the same key.
+// Returns false for kinds compareValues does not support.
+func valueKey(v reflect.Value) (interface{}, bool) {
+	v = unwrap(v)
+	if v.IsValid() && v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		return timeKey{sec: t.Unix(), nsec: t.Nanosecond()}, true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Bool:
+		return v.Bool(), true
+	default:
+		return nil, false
+	}
+}
+
+// Distinct returns the distinct values of a field in the order they first appear.
+// Values are considered equal under the same rules as Eq. Items whose field is
+// missing or of a type Eq cannot compare are skipped.
+//
+// Example:
+//
+//	cities := filter.Distinct(users, "City")  // []interface{}{"SP", "RJ", "MG"}
+func Distinct[T any](items []T, fieldName string) []interface{} {
+	seen := make(map[interface{}]bool)
+	result := make([]interface{}, 0)
+
+	for _, item := range items {
+		fieldValue, err := getFieldValue(item, fieldName)
+		if err != nil {
+			continue
+		}
+
+		key, ok := valueKey(fieldValue)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if fieldValue.CanInterface() {
+			result = append(result, fieldValue.Interface())
+		} else {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
+
+// DistinctOn returns one item per distinct value of a field.
+// Items are first sorted by the given keys, and the first item of each group
+// is kept, so the keys decide which item represents its group. Without keys,
+// the first occurrence in the original order is kept.
+// The result is ordered by the sort keys. Items whose field is missing or of
+// a type Eq cannot compare are skipped.
+//
+// Example:
+//
+//	// the highest scoring user of each city
+//	best := filter.DistinctOn(users, "City", filter.Desc[User]("Score"))
+func DistinctOn[T any](items []T, fieldName string, keys ...SortKey[T]) []T {
+	sorted := items
+	if len(keys) > 0 {
+		sorted = SortBy(items, keys...)
+	}
+
+	seen := make(map[interface{}]bool)
+	result := make([]T, 0)
+
+	for _, item := range sorted {
+		fieldValue, err := getFieldValue(item, fieldName)
+		if err != nil {
+			continue
+		}
+
+		key, ok := valueKey(fieldValue)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		result = append(result, item)
+	}
+
+	return result
+}
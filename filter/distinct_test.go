@@ -0,0 +1,87 @@
+package filter
+
+import (
+	"testing"
+)
+
+type Player struct {
+	Name  string
+	City  string
+	Score int
+	Tags  []string
+}
+
+func testPlayers() []Player {
+	return []Player{
+		{Name: "Ana", City: "SP", Score: 70},
+		{Name: "Bob", City: "RJ", Score: 90},
+		{Name: "Carla", City: "SP", Score: 95},
+		{Name: "Diego", City: "MG", Score: 60},
+		{Name: "Elena", City: "RJ", Score: 80},
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	result := Distinct(testPlayers(), "City")
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 distinct cities, got %d", len(result))
+	}
+
+	expected := []string{"SP", "RJ", "MG"}
+	for i, city := range expected {
+		if result[i] != city {
+			t.Errorf("Expected city %d to be %s, got %v", i, city, result[i])
+		}
+	}
+}
+
+func TestDistinctSkipsUnsupportedFields(t *testing.T) {
+	players := []Player{
+		{Name: "Ana", Tags: []string{"a"}},
+		{Name: "Bob", Tags: []string{"a"}},
+	}
+
+	if result := Distinct(players, "Tags"); len(result) != 0 {
+		t.Errorf("Expected slice fields to be skipped, got %v", result)
+	}
+
+	if result := Distinct(players, "Missing"); len(result) != 0 {
+		t.Errorf("Expected missing fields to be skipped, got %v", result)
+	}
+}
+
+func TestDistinctOn(t *testing.T) {
+	result := DistinctOn(testPlayers(), "City", Desc[Player]("Score"))
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 players (one per city), got %d", len(result))
+	}
+
+	expected := []string{"Carla", "Bob", "Diego"}
+	for i, name := range expected {
+		if result[i].Name != name {
+			t.Errorf("Expected player %d to be %s, got %s", i, name, result[i].Name)
+		}
+	}
+}
+
+func TestDistinctOnKeepsFirstWithoutKeys(t *testing.T) {
+	result := DistinctOn(testPlayers(), "City")
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 players (one per city), got %d", len(result))
+	}
+
+	if result[0].Name != "Ana" || result[1].Name != "Bob" || result[2].Name != "Diego" {
+		t.Errorf("Expected first occurrence of each city, got %v", result)
+	}
+}
+
+func TestSortByMultipleKeys(t *testing.T) {
+	result := SortBy(testPlayers(), Asc[Player]("City"), Desc[Player]("Score"))
+
+	expected := []string{"Diego", "Bob", "Elena", "Carla", "Ana"}
+	for i, name := range expected {
+		if result[i].Name != name {
+			t.Errorf("Expected player %d to be %s, got %s", i, name, result[i].Name)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package filter
+
+import "fmt"
+
+// ErrUnknownField is returned when an expression refers to a field
+// that does not exist on the item type.
+type ErrUnknownField struct{ Field string }
+
+func (e *ErrUnknownField) Error() string {
+	return fmt.Sprintf("unknown field %q", e.Field)
+}
+
+// ErrInvalidPath is returned when a field path does not follow the path
+// grammar, such as "Items[0" or "Orders[x]".
+type ErrInvalidPath struct {
+	Path   string
+	Reason string
+}
+
+func (e *ErrInvalidPath) Error() string {
+	return fmt.Sprintf("invalid field path %q: %s", e.Path, e.Reason)
+}
+
+// ErrUnknownOperator is returned when an expression uses an operator
+// that is not a built-in Op.
+type ErrUnknownOperator struct{ Op Op }
+
+func (e *ErrUnknownOperator) Error() string {
+	return fmt.Sprintf("unknown operator %q", e.Op)
+}
+
+// ErrInvalidOperand is returned when the value of an expression cannot be
+// used with its operator or with the type of its field.
+type ErrInvalidOperand struct {
+	Field  string
+	Op     Op
+	Reason string
+}
+
+func (e *ErrInvalidOperand) Error() string {
+	return fmt.Sprintf("invalid operand for %s on field %q: %s", e.Op, e.Field, e.Reason)
+}
+
+// ErrNotSerializable is returned when marshaling a filter that cannot
+// describe itself, such as a Custom filter or a FilterFunc.
+type ErrNotSerializable struct{}
+
+func (e *ErrNotSerializable) Error() string {
+	return "custom filters cannot be serialized"
+}
+
+// ErrInvalidGeoJSON is returned by ParseGeoJSON when a document is not valid
+// GeoJSON or its polygons cannot bound an area.
+type ErrInvalidGeoJSON struct{ Reason string }
+
+func (e *ErrInvalidGeoJSON) Error() string {
+	return "invalid GeoJSON: " + e.Reason
+}
@@ -127,3 +127,58 @@ func ExampleCustom() {
 	// Carlos: 35, active: true
 	// Diana: 28, active: true
 }
+
+func ExampleDistinct() {
+	// All distinct cities, in order of first appearance
+	fmt.Println(filter.Distinct(users, "City"))
+	// Output:
+	// [SP RJ MG]
+}
+
+func ExampleDistinctOn() {
+	// The oldest user of each city
+	result := filter.DistinctOn(users, "City", filter.Desc[User]("Age"))
+
+	for _, u := range result {
+		fmt.Printf("%s: %d (%s)\n", u.Name, u.Age, u.City)
+	}
+	// Output:
+	// Carlos: 35 (SP)
+	// Bob: 30 (RJ)
+	// Diana: 28 (MG)
+}
+
+func ExampleParseJSON() {
+	// Save a filter as JSON and load it back later
+	data, _ := filter.MarshalJSON(filter.And(
+		filter.Gt[User]("Age", 26),
+		filter.Eq[User]("Active", true),
+	))
+	fmt.Println(string(data))
+
+	f, err := filter.ParseJSON[User](data)
+	if err != nil {
+		panic(err)
+	}
+	for _, u := range filter.Apply(users, f) {
+		fmt.Println(u.Name)
+	}
+	// Output:
+	// {"and":[{"field":"Age","op":"gt","value":26},{"field":"Active","op":"eq","value":true}]}
+	// Carlos
+	// Diana
+}
+
+func ExampleField() {
+	// Typed accessors are checked by the compiler and skip reflection
+	age := filter.Field(func(u User) int { return u.Age })
+	city := filter.Field(func(u User) string { return u.City })
+
+	result := filter.Apply(users, filter.And(age.Between(25, 30), city.In("SP", "RJ")))
+	for _, u := range filter.SortBy(result, age.Desc()) {
+		fmt.Println(u.Name, u.Age)
+	}
+	// Output:
+	// Bob 30
+	// Ana 25
+}
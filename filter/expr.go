@@ -0,0 +1,242 @@
+package filter
+
+// Op identifies the operator of a built-in filter.
+type Op string
+
+const (
+	// OpEq is the operator of Eq
+	OpEq Op = "eq"
+	// OpNe is the operator of Ne
+	OpNe Op = "ne"
+	// OpGt is the operator of Gt
+	OpGt Op = "gt"
+	// OpGte is the operator of Gte
+	OpGte Op = "gte"
+	// OpLt is the operator of Lt
+	OpLt Op = "lt"
+	// OpLte is the operator of Lte
+	OpLte Op = "lte"
+	// OpIn is the operator of In; Value is a []interface{}
+	OpIn Op = "in"
+	// OpBetween is the operator of Between; Value is a []interface{}{min, max}
+	OpBetween Op = "between"
+
+	// OpExact is StringMatch with ExactMatch
+	OpExact Op = "exact"
+	// OpIExact is StringMatch with ExactMatch and IgnoreCase
+	OpIExact Op = "iexact"
+	// OpSubstring is StringMatch with ContainsMatch
+	OpSubstring Op = "substring"
+	// OpISubstring is StringMatch with ContainsMatch and IgnoreCase
+	OpISubstring Op = "isubstring"
+	// OpPrefix is StringMatch with PrefixMatch
+	OpPrefix Op = "prefix"
+	// OpIPrefix is StringMatch with PrefixMatch and IgnoreCase
+	OpIPrefix Op = "iprefix"
+	// OpSuffix is StringMatch with SuffixMatch
+	OpSuffix Op = "suffix"
+	// OpISuffix is StringMatch with SuffixMatch and IgnoreCase
+	OpISuffix Op = "isuffix"
+
+	// OpContains is the operator of Contains
+	OpContains Op = "contains"
+	// OpIsNil is the operator of IsNil; it has no Value
+	OpIsNil Op = "is_nil"
+	// OpIsZero is the operator of IsZero; it has no Value
+	OpIsZero Op = "is_zero"
+	// OpRegex is the operator of RegexMatch; Value is the pattern
+	OpRegex Op = "regex"
+
+	// OpArrayContains is the operator of ArrayContains
+	OpArrayContains Op = "array_contains"
+	// OpIArrayContains is the operator of ArrayContains with ignoreCase
+	OpIArrayContains Op = "iarray_contains"
+	// OpArrayContainsAny is the operator of ArrayContainsAny; Value is a []interface{}
+	OpArrayContainsAny Op = "array_contains_any"
+	// OpArrayContainsAll is the operator of ArrayContainsAll; Value is a []interface{}
+	OpArrayContainsAll Op = "array_contains_all"
+
+	// OpDateBefore is the operator of DateBefore; Value is a time.Time
+	OpDateBefore Op = "date_before"
+	// OpDateAfter is the operator of DateAfter; Value is a time.Time
+	OpDateAfter Op = "date_after"
+	// OpDateBetween is the operator of DateBetween; Value is a []interface{}{start, end}
+	OpDateBetween Op = "date_between"
+	// OpDateRange is the operator of DateRange, SameDay and SameMonth; Value is a TimeRange
+	OpDateRange Op = "date_range"
+	// OpDatePart is the operator of DatePart and DatePartIn; Value is a DatePartCondition
+	OpDatePart Op = "date_part"
+
+	// OpHasKey is the operator of HasKey; Value is the key
+	OpHasKey Op = "has_key"
+	// OpHasValue is the operator of HasValue
+	OpHasValue Op = "has_value"
+	// OpKeyValue is the operator of KeyValueEquals; Value is a []interface{}{key, value}
+	OpKeyValue Op = "key_value"
+	// OpMapContainsAll is the operator of MapContainsAll; Value is a map[interface{}]interface{}
+	OpMapContainsAll Op = "map_contains_all"
+	// OpMapContainsAny is the operator of MapContainsAny; Value is a map[interface{}]interface{}
+	OpMapContainsAny Op = "map_contains_any"
+	// OpMapSizeEq is the operator of MapSizeEquals; Value is an int
+	OpMapSizeEq Op = "map_size_eq"
+	// OpMapSizeGt is the operator of MapSizeGreaterThan; Value is an int
+	OpMapSizeGt Op = "map_size_gt"
+	// OpMapSizeLt is the operator of MapSizeLessThan; Value is an int
+	OpMapSizeLt Op = "map_size_lt"
+
+	// OpWithinRadius is the operator of WithinRadius; Field is "lat,lng" and Value is a Circle
+	OpWithinRadius Op = "within_radius"
+	// OpWithinBox is the operator of WithinBoundingBox; Field is "lat,lng" and Value is a BoundingBox
+	OpWithinBox Op = "within_box"
+	// OpWithinPolygon is the operator of WithinPolygon and WithinMultiPolygon; Field is "lat,lng" and Value is a MultiPolygon
+	OpWithinPolygon Op = "within_polygon"
+
+	// OpSearch is the operator of Search; Field lists the searched fields separated by commas
+	OpSearch Op = "search"
+	// OpFuzzy is the operator of Fuzzy; Value is a []interface{}{term, maxDistance}
+	OpFuzzy Op = "fuzzy"
+	// OpSimilar is the operator of Similar; Value is a []interface{}{term, threshold}
+	OpSimilar Op = "similar"
+
+	// OpAnd is the operator of And; operands are in Children
+	OpAnd Op = "and"
+	// OpOr is the operator of Or; operands are in Children
+	OpOr Op = "or"
+	// OpNot is the operator of Not; the negated filter is the only child
+	OpNot Op = "not"
+
+	// OpCustom describes a filter that cannot describe itself, such as a FilterFunc
+	OpCustom Op = "custom"
+)
+
+// Expr describes a filter: the field it reads, its operator and operand,
+// and, for logical operators, the expressions it combines.
+type Expr struct {
+	// Field is the field path the filter reads, empty for logical operators.
+	// Filters reading several fields list them separated by commas.
+	Field string
+	// Op is the operator of the filter
+	Op Op
+	// Value is the operand the field is compared with
+	Value interface{}
+	// Dates holds the DateOptions passed to a date filter, or nil
+	Dates *DateOptions
+	// Children holds the operands of And, Or and Not
+	Children []Expr
+}
+
+// Describer is implemented by filters that can describe themselves.
+// All built-in operators implement it, except Custom, NestedArrayAny and
+// NestedArrayAll, whose behavior lives in user code.
+type Describer interface {
+	// Expr returns the expression the filter evaluates
+	Expr() Expr
+}
+
+// Describe returns the expression of a filter.
+// Filters that do not implement Describer are described as OpCustom.
+//
+// Example:
+//
+//	e := filter.Describe(filter.Gt[User]("Age", 18))
+//	fmt.Println(e.Field, e.Op, e.Value)  // Age gt 18
+func Describe[T any](f Filter[T]) Expr {
+	if d, ok := f.(Describer); ok {
+		return d.Expr()
+	}
+	return Expr{Op: OpCustom}
+}
+
+// Operands returns the filters combined by an And, Or or Not filter,
+// or nil for any other filter.
+//
+// Example:
+//
+//	for _, f := range filter.Operands(f) {
+//	    fmt.Println(filter.Describe(f).Op)
+//	}
+func Operands[T any](f Filter[T]) []Filter[T] {
+	if n, ok := f.(*node[T]); ok {
+		return n.children
+	}
+	return nil
+}
+
+// node is a built-in filter that knows the expression it evaluates
+type node[T any] struct {
+	expr     Expr
+	children []Filter[T]
+	match    func(item T) bool
+}
+
+// newNode creates a leaf filter described by expr
+func newNode[T any](expr Expr, match func(item T) bool) Filter[T] {
+	return &node[T]{expr: expr, match: match}
+}
+
+// newLogicalNode creates a filter combining children with a logical operator
+func newLogicalNode[T any](op Op, children []Filter[T], match func(item T) bool) Filter[T] {
+	return &node[T]{expr: Expr{Op: op}, children: children, match: match}
+}
+
+// Apply implements the Filter interface for node.
+func (n *node[T]) Apply(item T) bool {
+	return n.match(item)
+}
+
+// Expr implements the Describer interface for node.
+func (n *node[T]) Expr() Expr {
+	e := n.expr
+	if len(n.children) > 0 {
+		e.Children = make([]Expr, len(n.children))
+		for i, child := range n.children {
+			e.Children[i] = Describe(child)
+		}
+	}
+	return e
+}
+
+// Visitor visits the expressions of a filter tree with Walk.
+type Visitor interface {
+	// Visit is called for each expression. If it returns a non-nil visitor w,
+	// Walk visits the children of e with w.
+	Visit(e Expr) (w Visitor)
+}
+
+// Walk traverses an expression tree in depth-first order, calling v.Visit
+// for e and then walking its children with the visitor Visit returned.
+func Walk(v Visitor, e Expr) {
+	if v = v.Visit(e); v == nil {
+		return
+	}
+	for _, child := range e.Children {
+		Walk(v, child)
+	}
+}
+
+// inspector adapts a function to the Visitor interface
+type inspector func(Expr) bool
+
+// Visit implements the Visitor interface for inspector.
+func (f inspector) Visit(e Expr) Visitor {
+	if f(e) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an expression tree in depth-first order, calling f for
+// each expression. The children of e are skipped when f returns false.
+//
+// Example:
+//
+//	var fields []string
+//	filter.Inspect(filter.Describe(f), func(e filter.Expr) bool {
+//	    if e.Field != "" {
+//	        fields = append(fields, e.Field)
+//	    }
+//	    return true
+//	})
+func Inspect(e Expr, f func(Expr) bool) {
+	Walk(inspector(f), e)
+}
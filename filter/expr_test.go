@@ -0,0 +1,97 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescribeLeaf(t *testing.T) {
+	e := Describe(Gt[Person]("Age", 18))
+	if e.Field != "Age" || e.Op != OpGt || e.Value != 18 {
+		t.Errorf("Unexpected expression: %+v", e)
+	}
+
+	e = Describe(Between[Person]("Age", 18, 30))
+	if e.Op != OpBetween || !reflect.DeepEqual(e.Value, []interface{}{18, 30}) {
+		t.Errorf("Unexpected expression for Between: %+v", e)
+	}
+}
+
+func TestDescribeStringMatch(t *testing.T) {
+	tests := []struct {
+		options StringMatchOptions
+		want    Op
+	}{
+		{StringMatchOptions{Mode: ExactMatch}, OpExact},
+		{StringMatchOptions{Mode: ContainsMatch, IgnoreCase: true}, OpISubstring},
+		{StringMatchOptions{Mode: PrefixMatch}, OpPrefix},
+		{StringMatchOptions{Mode: SuffixMatch, IgnoreCase: true}, OpISuffix},
+	}
+	for _, tt := range tests {
+		e := Describe(StringMatch[Person]("Name", "an", tt.options))
+		if e.Op != tt.want {
+			t.Errorf("StringMatch(%+v) described as %q, want %q", tt.options, e.Op, tt.want)
+		}
+	}
+}
+
+func TestDescribeComposite(t *testing.T) {
+	f := And[Person](
+		Eq[Person]("Name", "Alice"),
+		Not(Lt[Person]("Age", 18)),
+		Custom(func(p Person) bool { return true }),
+	)
+
+	e := Describe(f)
+	if e.Op != OpAnd || len(e.Children) != 3 {
+		t.Fatalf("Expected an And with 3 children, got %+v", e)
+	}
+	if e.Children[1].Op != OpNot || e.Children[1].Children[0].Op != OpLt {
+		t.Errorf("Expected Not(Lt), got %+v", e.Children[1])
+	}
+	if e.Children[2].Op != OpCustom {
+		t.Errorf("Expected custom filter to be described as custom, got %+v", e.Children[2])
+	}
+
+	if len(Operands(f)) != 3 {
+		t.Errorf("Expected 3 operands, got %d", len(Operands(f)))
+	}
+	if Operands(Eq[Person]("Name", "Alice")) != nil {
+		t.Error("Expected leaf filters to have no operands")
+	}
+}
+
+type fieldCollector struct{ fields []string }
+
+func (c *fieldCollector) Visit(e Expr) Visitor {
+	if e.Op == OpNot {
+		return nil
+	}
+	if e.Field != "" {
+		c.fields = append(c.fields, e.Field)
+	}
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	e := Describe(And(
+		Eq[Person]("Name", "Alice"),
+		Or(Gt[Person]("Age", 18), Not(IsNil[Person]("Address"))),
+		Contains[Person]("Hobbies", "chess"),
+	))
+
+	c := &fieldCollector{}
+	Walk(c, e)
+	if !reflect.DeepEqual(c.fields, []string{"Name", "Age", "Hobbies"}) {
+		t.Errorf("Expected fields outside Not in order, got %v", c.fields)
+	}
+
+	var ops []Op
+	Inspect(e, func(e Expr) bool {
+		ops = append(ops, e.Op)
+		return e.Op != OpOr
+	})
+	if !reflect.DeepEqual(ops, []Op{OpAnd, OpEq, OpOr, OpContains}) {
+		t.Errorf("Expected Inspect to skip the children of Or, got %v", ops)
+	}
+}
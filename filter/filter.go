@@ -58,3 +58,92 @@ func Apply[T any](items []T, filter Filter[T]) []T {
 
 	return result
 }
+
+// Count returns the number of items that pass the filter without building a
+// result slice.
+//
+// Example:
+//
+//	adults := filter.Count(users, filter.Gte[User]("Age", 18))
+func Count[T any](items []T, filter Filter[T]) int {
+	count := 0
+	for _, item := range items {
+		if filter.Apply(item) {
+			count++
+		}
+	}
+	return count
+}
+
+// Any reports whether at least one item passes the filter. It stops at the
+// first match.
+//
+// Example:
+//
+//	hasAdmins := filter.Any(users, filter.Eq[User]("Role", "admin"))
+func Any[T any](items []T, filter Filter[T]) bool {
+	return FindIndex(items, filter) >= 0
+}
+
+// All reports whether every item passes the filter. It stops at the first
+// item that does not, and returns true for an empty slice.
+//
+// Example:
+//
+//	allVerified := filter.All(users, filter.Eq[User]("Verified", true))
+func All[T any](items []T, filter Filter[T]) bool {
+	for _, item := range items {
+		if !filter.Apply(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// First returns the first item that passes the filter. The boolean is false,
+// and the item the zero value, when no item matches.
+//
+// Example:
+//
+//	u, ok := filter.First(users, filter.Eq[User]("Email", email))
+func First[T any](items []T, filter Filter[T]) (T, bool) {
+	if i := FindIndex(items, filter); i >= 0 {
+		return items[i], true
+	}
+	var zero T
+	return zero, false
+}
+
+// FindIndex returns the index of the first item that passes the filter, or -1
+// when no item matches.
+//
+// Example:
+//
+//	i := filter.FindIndex(users, filter.Eq[User]("ID", 42))
+func FindIndex[T any](items []T, filter Filter[T]) int {
+	for i, item := range items {
+		if filter.Apply(item) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Partition splits a slice into the items that pass the filter and the rest,
+// in a single pass. Both slices keep the original order.
+//
+// Example:
+//
+//	adults, minors := filter.Partition(users, filter.Gte[User]("Age", 18))
+func Partition[T any](items []T, filter Filter[T]) (matched, rest []T) {
+	matched = make([]T, 0)
+	rest = make([]T, 0)
+	for _, item := range items {
+		if filter.Apply(item) {
+			matched = append(matched, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+	return matched, rest
+}
@@ -1,7 +1,9 @@
 package filter
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 )
 
 type Person struct {
@@ -55,6 +57,41 @@ func TestGt(t *testing.T) {
 	}
 }
 
+func TestBoundsAcrossNumberTypes(t *testing.T) {
+	type Reading struct {
+		U  uint
+		I8 int8
+		I  int
+		F  float64
+	}
+	readings := []Reading{{U: 5, I8: 100, I: 7, F: 7.5}}
+
+	tests := []struct {
+		name string
+		f    Filter[Reading]
+		want bool
+	}{
+		{"uint gt negative", Gt[Reading]("U", -1), true},
+		{"uint gte negative", Gte[Reading]("U", -1), true},
+		{"uint lt negative", Lt[Reading]("U", -1), false},
+		{"uint lte negative", Lte[Reading]("U", -1), false},
+		{"uint gt int", Gt[Reading]("U", 4), true},
+		{"int8 gt overflow", Gt[Reading]("I8", 300), false},
+		{"int8 lt overflow", Lt[Reading]("I8", 300), true},
+		{"int gt fraction", Gt[Reading]("I", 6.5), true},
+		{"int lte fraction", Lte[Reading]("I", 6.5), false},
+		{"int lt fraction", Lt[Reading]("I", 7.5), true},
+		{"float gt int", Gt[Reading]("F", 7), true},
+		{"float lt int", Lt[Reading]("F", 8), true},
+	}
+
+	for _, tt := range tests {
+		if got := len(Apply(readings, tt.f)) == 1; got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
 func TestLt(t *testing.T) {
 	people := []Person{
 		{Name: "Alice", Age: 30},
@@ -172,6 +209,106 @@ func TestNested(t *testing.T) {
 	}
 }
 
+func TestDynamicDocuments(t *testing.T) {
+	var docs []map[string]interface{}
+	err := json.Unmarshal([]byte(`[
+		{"name": "Alice", "age": 30, "address": {"city": "New York"}, "tags": ["admin", "dev"]},
+		{"name": "Bob", "age": 17, "address": {"city": "London"}, "tags": ["dev"], "manager": null},
+		{"name": "Carol", "age": 45, "tags": []}
+	]`), &docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(result []map[string]interface{}) []interface{} {
+		out := make([]interface{}, len(result))
+		for i, doc := range result {
+			out[i] = doc["name"]
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter[map[string]interface{}]
+		want   []interface{}
+	}{
+		{"int bound on JSON number", Gte[map[string]interface{}]("age", 18), []interface{}{"Alice", "Carol"}},
+		{"nested object", Eq[map[string]interface{}]("address.city", "London"), []interface{}{"Bob"}},
+		{"array index", Eq[map[string]interface{}]("tags.0", "dev"), []interface{}{"Bob"}},
+		{"array elements", Contains[map[string]interface{}]("tags", "admin"), []interface{}{"Alice"}},
+		{"array contains", ArrayContains[map[string]interface{}]("tags", "DEV", true), []interface{}{"Alice", "Bob"}},
+		{"in", In[map[string]interface{}]("name", []interface{}{"Alice", "Carol"}), []interface{}{"Alice", "Carol"}},
+		{"null value", IsNil[map[string]interface{}]("manager"), []interface{}{"Bob"}},
+		{"missing key", Ne[map[string]interface{}]("address.city", "London"), []interface{}{"Alice"}},
+	}
+
+	for _, tt := range tests {
+		got := names(Apply(docs, tt.filter))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+				break
+			}
+		}
+	}
+
+	sorted := names(SortBy(docs, Desc[map[string]interface{}]("age")))
+	if sorted[0] != "Carol" || sorted[2] != "Bob" {
+		t.Errorf("expected documents by age descending, got %v", sorted)
+	}
+
+	if err := Validate(And(Gte[map[string]interface{}]("age", 18), Contains[map[string]interface{}]("address.city", "York"))); err != nil {
+		t.Errorf("expected dynamic fields to validate, got %v", err)
+	}
+	if err := Validate(StringMatch[map[string]interface{}]("age", "3", StringMatchOptions{Mode: PrefixMatch})); err != nil {
+		t.Errorf("expected string matches on dynamic fields to validate, got %v", err)
+	}
+}
+
+func TestTimeFields(t *testing.T) {
+	type Event struct {
+		Name string
+		At   time.Time
+	}
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	events := []Event{
+		{"Launch", day.Add(9 * time.Hour)},
+		{"Review", day.AddDate(0, 0, 1)},
+		// the same instant as Launch, in another zone
+		{"Launch (SP)", day.Add(9 * time.Hour).In(time.FixedZone("UTC-3", -3*60*60))},
+	}
+
+	if got := Apply(events, Eq[Event]("At", day.Add(9*time.Hour))); len(got) != 2 {
+		t.Errorf("expected equal instants to match across zones, got %v", got)
+	}
+	if got := Apply(events, Gt[Event]("At", day.Add(12*time.Hour))); len(got) != 1 || got[0].Name != "Review" {
+		t.Errorf("expected Review, got %v", got)
+	}
+	if got := Apply(events, Between[Event]("At", day, day.Add(10*time.Hour))); len(got) != 2 {
+		t.Errorf("expected both launches, got %v", got)
+	}
+	if got := Apply(events, In[Event]("At", []interface{}{day.AddDate(0, 0, 1)})); len(got) != 1 || got[0].Name != "Review" {
+		t.Errorf("expected Review, got %v", got)
+	}
+	if got := Distinct(events, "At"); len(got) != 2 {
+		t.Errorf("expected 2 distinct instants, got %v", got)
+	}
+
+	sorted := SortBy(events, Desc[Event]("At"))
+	if sorted[0].Name != "Review" {
+		t.Errorf("expected Review first, got %v", sorted)
+	}
+
+	if err := Validate(Gte[Event]("At", day)); err != nil {
+		t.Errorf("expected time bounds to validate, got %v", err)
+	}
+}
+
 func TestNot(t *testing.T) {
 	people := []Person{
 		{Name: "Alice", Age: 30},
@@ -191,3 +328,50 @@ func TestNot(t *testing.T) {
 		}
 	}
 }
+
+func TestShortCircuitHelpers(t *testing.T) {
+	people := []Person{
+		{Name: "Alice", Age: 30},
+		{Name: "Bob", Age: 17},
+		{Name: "Charlie", Age: 35},
+	}
+	adult := Gte[Person]("Age", 18)
+
+	if n := Count(people, adult); n != 2 {
+		t.Errorf("Expected 2 adults, got %d", n)
+	}
+	if !Any(people, adult) || Any(people, Eq[Person]("Name", "Dave")) {
+		t.Error("Unexpected Any result")
+	}
+	if All(people, adult) || !All(people, Gt[Person]("Age", 10)) || !All([]Person{}, adult) {
+		t.Error("Unexpected All result")
+	}
+	if p, ok := First(people, Lt[Person]("Age", 18)); !ok || p.Name != "Bob" {
+		t.Errorf("Expected Bob, got %v", p)
+	}
+	if p, ok := First(people, Eq[Person]("Name", "Dave")); ok || p.Name != "" {
+		t.Errorf("Expected no match, got %v", p)
+	}
+	if i := FindIndex(people, Eq[Person]("Name", "Charlie")); i != 2 {
+		t.Errorf("Expected index 2, got %d", i)
+	}
+	if i := FindIndex(people, Eq[Person]("Name", "Dave")); i != -1 {
+		t.Errorf("Expected index -1, got %d", i)
+	}
+
+	adults, minors := Partition(people, adult)
+	if len(adults) != 2 || adults[0].Name != "Alice" || adults[1].Name != "Charlie" || len(minors) != 1 || minors[0].Name != "Bob" {
+		t.Errorf("Unexpected partition %v / %v", adults, minors)
+	}
+
+	// Any and First stop at the first match
+	calls := 0
+	counting := Custom[Person](func(p Person) bool {
+		calls++
+		return p.Age >= 18
+	})
+	Any(people, counting)
+	if calls != 1 {
+		t.Errorf("Expected Any to stop after 1 call, got %d", calls)
+	}
+}
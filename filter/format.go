@@ -0,0 +1,116 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// opSymbols are the symbols String uses for comparison operators
+var opSymbols = map[Op]string{
+	OpEq:  "=",
+	OpNe:  "!=",
+	OpGt:  ">",
+	OpGte: ">=",
+	OpLt:  "<",
+	OpLte: "<=",
+}
+
+// String returns a human-readable form of the expression for logs and tests.
+// Logical operators are written in upper case and parenthesized, comparisons
+// use symbols and other operators their upper-cased name. Values are written
+// as JSON.
+//
+// Example:
+//
+//	fmt.Println(filter.Describe(f))  // (Age > 18 AND City IN ["SP","RJ"])
+func (e Expr) String() string {
+	switch e.Op {
+	case OpAnd, OpOr:
+		if len(e.Children) == 0 {
+			if e.Op == OpAnd {
+				return "TRUE"
+			}
+			return "FALSE"
+		}
+		parts := make([]string, len(e.Children))
+		for i, child := range e.Children {
+			parts[i] = child.String()
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(string(e.Op))+" ") + ")"
+	case OpNot:
+		if len(e.Children) != 1 {
+			return "NOT ()"
+		}
+		child := e.Children[0]
+		if (child.Op == OpAnd || child.Op == OpOr) && len(child.Children) > 0 {
+			return "NOT " + child.String()
+		}
+		return "NOT (" + child.String() + ")"
+	case OpCustom:
+		return "<custom>"
+	case OpIsNil, OpIsZero:
+		return e.Field + " " + opName(e.Op)
+	case OpBetween, OpDateBetween:
+		if bounds, ok := e.Value.([]interface{}); ok && len(bounds) == 2 {
+			return fmt.Sprintf("%s %s %s AND %s", e.Field, opName(e.Op), formatValue(bounds[0]), formatValue(bounds[1]))
+		}
+	case OpDateRange:
+		// Written in interval notation, with open ends as infinities: [start, +inf)
+		if r, ok := e.Value.(TimeRange); ok {
+			left, right := "[", "]"
+			if r.ExcludeStart {
+				left = "("
+			}
+			if r.ExcludeEnd {
+				right = ")"
+			}
+			start, end := "-inf", "+inf"
+			if !r.Start.IsZero() {
+				start = formatValue(r.Start)
+			}
+			if !r.End.IsZero() {
+				end = formatValue(r.End)
+			}
+			return fmt.Sprintf("%s IN %s%s, %s%s", e.Field, left, start, end, right)
+		}
+	case OpDatePart:
+		// Written as a comparison of the part: weekday(CreatedAt) IN [0,6]
+		if c, ok := e.Value.(DatePartCondition); ok {
+			field := string(c.Part) + "(" + e.Field
+			if c.Location != nil {
+				field += " in " + c.Location.String()
+			}
+			return Expr{Field: field + ")", Op: c.Op, Value: c.Value}.String()
+		}
+	}
+
+	symbol, ok := opSymbols[e.Op]
+	if !ok {
+		symbol = opName(e.Op)
+	}
+	return e.Field + " " + symbol + " " + formatValue(e.Value)
+}
+
+// String implements fmt.Stringer for node, so built-in filters print as
+// their expression.
+func (n *node[T]) String() string {
+	return n.Expr().String()
+}
+
+// opName returns the upper-cased name of an operator, with words separated by spaces
+func opName(op Op) string {
+	return strings.ToUpper(strings.ReplaceAll(string(op), "_", " "))
+}
+
+// formatValue writes a value as compact JSON, without HTML escaping
+func formatValue(value interface{}) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(encodeOperand(value)); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,49 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestExprString(t *testing.T) {
+	tests := []struct {
+		filter Filter[Person]
+		want   string
+	}{
+		{
+			And(Gt[Person]("Age", 18), In[Person]("Address.City", []interface{}{"SP", "RJ"})),
+			`(Age > 18 AND Address.City IN ["SP","RJ"])`,
+		},
+		{Or(Eq[Person]("Name", "Ana"), Lte[Person]("Age", 3)), `(Name = "Ana" OR Age <= 3)`},
+		{Not(Ne[Person]("Name", "Bob")), `NOT (Name != "Bob")`},
+		{Not(Or(Gte[Person]("Age", 1), Lt[Person]("Age", 0))), `NOT (Age >= 1 OR Age < 0)`},
+		{Between[Person]("Age", 18, 30), `Age BETWEEN 18 AND 30`},
+		{IsNil[Person]("Address"), `Address IS NIL`},
+		{IsNotZero[Person]("Hobbies"), `NOT (Hobbies IS ZERO)`},
+		{ArrayContains[Person]("Hobbies", "chess", true), `Hobbies IARRAY CONTAINS "chess"`},
+		{StringMatch[Person]("Name", "<a>", StringMatchOptions{Mode: PrefixMatch}), `Name PREFIX "<a>"`},
+		{Search[Person]("sao paulo", "Name", "Address.City"), `Name,Address.City SEARCH "sao paulo"`},
+		{DateAfter[Person]("Born", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)), `Born DATE AFTER "2020-01-02T00:00:00Z"`},
+		{
+			DateRange[Person]("Born", TimeRange{Start: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), ExcludeEnd: true}),
+			`Born IN ["2020-01-01T00:00:00Z", "2021-01-01T00:00:00Z")`,
+		},
+		{DatePart[Person]("Born", Weekday, OpIn, []interface{}{time.Saturday, time.Sunday}), `weekday(Born) IN [6,0]`},
+		{DatePartIn[Person]("Born", Hour, time.UTC, OpBetween, []interface{}{22, 6}), `hour(Born in UTC) BETWEEN 22 AND 6`},
+		{And[Person](), `TRUE`},
+		{Or[Person](), `FALSE`},
+		{And(Custom(func(Person) bool { return true })), `(<custom>)`},
+	}
+
+	for _, tt := range tests {
+		if got := Describe(tt.filter).String(); got != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, got)
+		}
+	}
+
+	// Built-in filters print as their expression
+	if got := fmt.Sprint(Gt[Person]("Age", 18)); got != "Age > 18" {
+		t.Errorf("Expected filter to print as Age > 18, got %s", got)
+	}
+}
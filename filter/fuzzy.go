@@ -0,0 +1,189 @@
+package filter
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Levenshtein returns the edit distance between two strings: the minimum
+// number of single-character insertions, deletions and substitutions needed
+// to turn a into b. Characters are compared as Unicode code points, so "São"
+// and "Sao" are one edit apart. Use Fold first for a case- and
+// accent-insensitive distance.
+//
+// Example:
+//
+//	filter.Levenshtein("anna", "ana")  // 1
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// trigrams returns the set of trigrams of a folded string.
+// Each word is padded with two spaces in front and one behind, so short
+// words and word boundaries still produce trigrams.
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(Fold(s)) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// TrigramSimilarity returns how similar two strings are, from 0 (no trigram
+// in common) to 1 (same trigrams). Strings are folded first, so the measure
+// ignores case and accents. It tolerates typos and reordered words better
+// than edit distance on long strings.
+//
+// Example:
+//
+//	filter.TrigramSimilarity("Sao Paulo", "são paulo")  // 1
+func TrigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 && len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// AutoDistance returns a maximum edit distance suited to the length of a
+// search term: 0 for terms up to 2 characters, 1 up to 5 and 2 beyond that.
+//
+// Example:
+//
+//	filter.Fuzzy[User]("Name", term, filter.AutoDistance(term))
+func AutoDistance(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzyDistance returns the smallest edit distance between a folded term and
+// either the whole folded text or any of its words.
+func fuzzyDistance(text, foldedTerm string) int {
+	folded := Fold(text)
+	best := Levenshtein(folded, foldedTerm)
+
+	for _, word := range strings.Fields(folded) {
+		if d := Levenshtein(word, foldedTerm); d < best {
+			best = d
+		}
+	}
+
+	return best
+}
+
+// Fuzzy returns a filter that checks if a string field approximately matches a
+// term, allowing up to maxDistance edits (see Levenshtein). The comparison
+// ignores case and accents, and the term may match the whole field or any
+// single word in it, so "anna" finds "Ana Souza" with a distance of 1.
+//
+// Example:
+//
+//	filter.Fuzzy[User]("Name", "anna", 1)  // matches "Ana", "Hanna", "Anna Lima"
+func Fuzzy[T any](fieldName string, term string, maxDistance int) Filter[T] {
+	foldedTerm := Fold(term)
+
+	return newNode(Expr{Field: fieldName, Op: OpFuzzy, Value: []interface{}{term, maxDistance}}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		if fieldValue.Kind() != reflect.String {
+			return false
+		}
+
+		return fuzzyDistance(fieldValue.String(), foldedTerm) <= maxDistance
+	}))
+}
+
+// Similar returns a filter that checks if a string field is similar to a term,
+// with a TrigramSimilarity of at least threshold (between 0 and 1).
+// A threshold around 0.3 is a good starting point for names.
+//
+// Example:
+//
+//	filter.Similar[User]("Name", "jonh smith", 0.3)  // matches "John Smith"
+func Similar[T any](fieldName string, term string, threshold float64) Filter[T] {
+	return newNode(Expr{Field: fieldName, Op: OpSimilar, Value: []interface{}{term, threshold}}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		if fieldValue.Kind() != reflect.String {
+			return false
+		}
+
+		return TrigramSimilarity(fieldValue.String(), term) >= threshold
+	}))
+}
+
+// ClosestTo returns a sort key that orders items by how closely a string field
+// matches a term, using the same distance as Fuzzy. Closest matches come first;
+// items whose field is missing or not a string come last.
+//
+// Example:
+//
+//	matches := filter.Apply(users, filter.Fuzzy[User]("Name", "anna", 2))
+//	sorted := filter.SortBy(matches, filter.ClosestTo[User]("Name", "anna"))
+func ClosestTo[T any](fieldName string, term string) SortKey[T] {
+	foldedTerm := Fold(term)
+
+	distance := func(item T) int {
+		fieldValue, err := getFieldValue(item, fieldName)
+		if err != nil || fieldValue.Kind() != reflect.String {
+			return math.MaxInt
+		}
+		return fuzzyDistance(fieldValue.String(), foldedTerm)
+	}
+
+	return SortKey[T]{
+		Field:     fieldName,
+		Ascending: true,
+		compare: func(a, b T) int {
+			da, db := distance(a), distance(b)
+			switch {
+			case da < db:
+				return -1
+			case da > db:
+				return 1
+			default:
+				return 0
+			}
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"anna", "ana", 1},
+		{"kitten", "sitting", 3},
+		{"São", "Sao", 1},
+		{"flaw", "lawn", 2},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	if got := TrigramSimilarity("Sao Paulo", "são paulo"); got != 1 {
+		t.Errorf("Expected identical folded strings to have similarity 1, got %f", got)
+	}
+	if got := TrigramSimilarity("abc", "xyz"); got != 0 {
+		t.Errorf("Expected unrelated strings to have similarity 0, got %f", got)
+	}
+
+	close := TrigramSimilarity("John Smith", "jonh smith")
+	far := TrigramSimilarity("John Smith", "Mary Jones")
+	if close <= far {
+		t.Errorf("Expected typo to be more similar (%f) than a different name (%f)", close, far)
+	}
+}
+
+func TestAutoDistance(t *testing.T) {
+	tests := []struct {
+		term string
+		want int
+	}{
+		{"ab", 0},
+		{"ana", 1},
+		{"sãoxy", 1},
+		{"carlos", 2},
+	}
+	for _, tt := range tests {
+		if got := AutoDistance(tt.term); got != tt.want {
+			t.Errorf("AutoDistance(%q) = %d, want %d", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzy(t *testing.T) {
+	people := []Person{
+		{Name: "Ana Souza"},
+		{Name: "Hanna"},
+		{Name: "Bruno"},
+		{Name: "Anne"},
+	}
+
+	result := Apply(people, Fuzzy[Person]("Name", "ANNA", 1))
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 people within 1 edit of 'anna', got %d", len(result))
+	}
+	for _, p := range result {
+		if p.Name == "Bruno" {
+			t.Errorf("Expected Bruno not to match 'anna'")
+		}
+	}
+
+	result = Apply(people, Fuzzy[Person]("Age", "anna", 1))
+	if len(result) != 0 {
+		t.Errorf("Expected non-string fields to never match, got %d", len(result))
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	people := []Person{
+		{Name: "John Smith"},
+		{Name: "Mary Jones"},
+	}
+
+	result := Apply(people, Similar[Person]("Name", "jonh smith", 0.3))
+	if len(result) != 1 || result[0].Name != "John Smith" {
+		t.Errorf("Expected only John Smith, got %v", result)
+	}
+}
+
+func TestClosestTo(t *testing.T) {
+	people := []Person{
+		{Name: "Bruno"},
+		{Name: "Anne"},
+		{Name: "Anna"},
+		{Name: "Ana"},
+	}
+
+	result := SortBy(people, ClosestTo[Person]("Name", "anna"))
+	expected := []string{"Anna", "Anne", "Ana", "Bruno"}
+	for i, name := range expected {
+		if result[i].Name != name {
+			t.Errorf("Expected person %d to be %s, got %s", i, name, result[i].Name)
+		}
+	}
+
+	key := ClosestTo[Person]("Missing", "anna")
+	if key.Compare(people[0], people[1]) != 0 {
+		t.Error("Expected items without the field to compare equal")
+	}
+	if d := fuzzyDistance("", "anna"); d != 4 {
+		t.Errorf("Expected an empty string to be 4 edits from anna, got %d", d)
+	}
+}
@@ -45,7 +45,7 @@ func calculateDistance(p1, p2 Point) float64 {
 // centerPoint is the center point to compare against
 // radiusKm is the radius in kilometers
 func WithinRadius[T any](latField, lngField string, centerPoint Point, radiusKm float64) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
+	return newNode(Expr{Field: latField + "," + lngField, Op: OpWithinRadius, Value: Circle{Center: centerPoint, RadiusKm: radiusKm}}, func(item T) bool {
 		latValue, err := getFieldValue(item, latField)
 		if err != nil {
 			return false
@@ -101,9 +101,16 @@ type BoundingBox struct {
 	NorthEast Point // Upper-right corner of the box
 }
 
+// Circle is a geographic area defined by a center point and a radius.
+// It describes WithinRadius filters.
+type Circle struct {
+	Center   Point   // Center of the circle
+	RadiusKm float64 // Radius in kilometers
+}
+
 // WithinBoundingBox returns a filter that checks if a location is within a bounding box
 func WithinBoundingBox[T any](latField, lngField string, box BoundingBox) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
+	return newNode(Expr{Field: latField + "," + lngField, Op: OpWithinBox, Value: box}, func(item T) bool {
 		latValue, err := getFieldValue(item, latField)
 		if err != nil {
 			return false
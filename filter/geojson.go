@@ -0,0 +1,192 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Zone is an area read from a GeoJSON feature, such as a delivery zone.
+type Zone struct {
+	ID         interface{}            // The feature's id, or nil
+	Properties map[string]interface{} // The feature's properties, such as its name
+	Area       MultiPolygon           // The feature's polygons
+}
+
+// ParseGeoJSON reads the polygons of a GeoJSON document (RFC 7946): a
+// FeatureCollection, a single Feature, or a bare Polygon, MultiPolygon or
+// GeometryCollection. Each feature with polygons becomes a Zone; features
+// whose geometry has no polygons, such as points, are skipped. A bare
+// geometry becomes one Zone without id or properties.
+//
+// Example:
+//
+//	zones, err := filter.ParseGeoJSON(data)
+//	for _, zone := range zones {
+//	    orders := filter.Apply(orders, filter.WithinMultiPolygon[Order]("Lat", "Lng", zone.Area))
+//	    fmt.Println(zone.Properties["name"], len(orders))
+//	}
+func ParseGeoJSON(data []byte) ([]Zone, error) {
+	var doc geoJSONObject
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, &ErrInvalidGeoJSON{Reason: err.Error()}
+	}
+
+	var zones []Zone
+	switch doc.Type {
+	case "FeatureCollection":
+		for i, feature := range doc.Features {
+			zone, ok, err := feature.zone()
+			if err != nil {
+				return nil, &ErrInvalidGeoJSON{Reason: fmt.Sprintf("feature %d: %v", i, err)}
+			}
+			if ok {
+				zones = append(zones, zone)
+			}
+		}
+	case "Feature":
+		zone, ok, err := doc.zone()
+		if err != nil {
+			return nil, &ErrInvalidGeoJSON{Reason: err.Error()}
+		}
+		if ok {
+			zones = append(zones, zone)
+		}
+	default:
+		polygons, err := doc.polygons()
+		if err != nil {
+			return nil, &ErrInvalidGeoJSON{Reason: err.Error()}
+		}
+		if len(polygons) == 0 {
+			return nil, &ErrInvalidGeoJSON{Reason: fmt.Sprintf("%s has no polygons", doc.Type)}
+		}
+		zones = append(zones, Zone{Area: polygons})
+	}
+	return zones, nil
+}
+
+// geoJSONObject is any GeoJSON object: a geometry, a Feature or a
+// FeatureCollection. Members of other objects are left empty.
+type geoJSONObject struct {
+	Type        string                 `json:"type"`
+	Coordinates json.RawMessage        `json:"coordinates,omitempty"`
+	Geometries  []geoJSONObject        `json:"geometries,omitempty"`
+	Geometry    *geoJSONObject         `json:"geometry,omitempty"`
+	Features    []geoJSONObject        `json:"features,omitempty"`
+	ID          interface{}            `json:"id,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	BBox        []float64              `json:"bbox,omitempty"`
+}
+
+// zone reads a Feature, reporting false when its geometry has no polygons
+func (f geoJSONObject) zone() (Zone, bool, error) {
+	if f.Type != "Feature" {
+		return Zone{}, false, fmt.Errorf("expected a Feature, got %q", f.Type)
+	}
+	if f.Geometry == nil {
+		return Zone{}, false, nil
+	}
+	polygons, err := f.Geometry.polygons()
+	if err != nil || len(polygons) == 0 {
+		return Zone{}, false, err
+	}
+	return Zone{ID: f.ID, Properties: f.Properties, Area: polygons}, true, nil
+}
+
+// polygons reads the polygons of a geometry. Points and lines have none.
+func (g geoJSONObject) polygons() (MultiPolygon, error) {
+	switch g.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("Polygon coordinates: %v", err)
+		}
+		polygon, err := polygonOf(rings)
+		if err != nil {
+			return nil, err
+		}
+		return MultiPolygon{polygon}, nil
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("MultiPolygon coordinates: %v", err)
+		}
+		result := make(MultiPolygon, len(polygons))
+		for i, rings := range polygons {
+			polygon, err := polygonOf(rings)
+			if err != nil {
+				return nil, fmt.Errorf("polygon %d: %v", i, err)
+			}
+			result[i] = polygon
+		}
+		return result, nil
+	case "GeometryCollection":
+		var result MultiPolygon
+		for _, geometry := range g.Geometries {
+			polygons, err := geometry.polygons()
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, polygons...)
+		}
+		return result, nil
+	case "Point", "MultiPoint", "LineString", "MultiLineString":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown geometry type %q", g.Type)
+	}
+}
+
+// polygonOf reads the rings of a Polygon, the first of which is its boundary
+func polygonOf(rings [][][]float64) (Polygon, error) {
+	if len(rings) == 0 {
+		return Polygon{}, fmt.Errorf("a polygon needs an outer ring")
+	}
+
+	var polygon Polygon
+	for i, positions := range rings {
+		points := make([]Point, len(positions))
+		for j, position := range positions {
+			if len(position) < 2 {
+				return Polygon{}, fmt.Errorf("position %d of ring %d needs a longitude and a latitude", j, i)
+			}
+			points[j] = Point{Lat: position[1], Lng: position[0]}
+		}
+		if err := checkRing(points); err != nil {
+			return Polygon{}, fmt.Errorf("ring %d: %v", i, err)
+		}
+		if i == 0 {
+			polygon.Outer = points
+		} else {
+			polygon.Holes = append(polygon.Holes, points)
+		}
+	}
+	return polygon, nil
+}
+
+// geometryOf encodes polygons as a GeoJSON Polygon, or a MultiPolygon when
+// there are several. Rings are closed and positions are [longitude, latitude].
+func geometryOf(polygons MultiPolygon) geoJSONObject {
+	coordinates := make([][][][]float64, len(polygons))
+	for i, polygon := range polygons {
+		rings := [][]Point{polygon.Outer}
+		rings = append(rings, polygon.Holes...)
+		coordinates[i] = make([][][]float64, len(rings))
+		for j, points := range rings {
+			positions := make([][]float64, 0, len(points)+1)
+			for _, p := range points {
+				positions = append(positions, []float64{p.Lng, p.Lat})
+			}
+			if len(points) > 0 && points[0] != points[len(points)-1] {
+				positions = append(positions, positions[0])
+			}
+			coordinates[i][j] = positions
+		}
+	}
+
+	if len(coordinates) == 1 {
+		data, _ := json.Marshal(coordinates[0])
+		return geoJSONObject{Type: "Polygon", Coordinates: data}
+	}
+	data, _ := json.Marshal(coordinates)
+	return geoJSONObject{Type: "MultiPolygon", Coordinates: data}
+}
@@ -0,0 +1,95 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+)
+
+const deliveryZones = `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "id": "downtown",
+      "properties": {"name": "Downtown", "fee": 5},
+      "geometry": {
+        "type": "Polygon",
+        "coordinates": [
+          [[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]],
+          [[4, 4], [4, 6], [6, 6], [6, 4], [4, 4]]
+        ]
+      }
+    },
+    {
+      "type": "Feature",
+      "properties": {"name": "Islands"},
+      "geometry": {
+        "type": "MultiPolygon",
+        "coordinates": [
+          [[[20, 20], [25, 20], [25, 25], [20, 20]]],
+          [[[30, 30], [35, 30], [35, 35], [30, 30]]]
+        ]
+      }
+    },
+    {"type": "Feature", "properties": {"name": "Depot"}, "geometry": {"type": "Point", "coordinates": [1, 1]}},
+    {"type": "Feature", "properties": {"name": "Unmapped"}, "geometry": null}
+  ]
+}`
+
+func TestParseGeoJSON(t *testing.T) {
+	zones, err := ParseGeoJSON([]byte(deliveryZones))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(zones) != 2 {
+		t.Fatalf("expected 2 zones, got %d", len(zones))
+	}
+
+	downtown := zones[0]
+	if downtown.ID != "downtown" || downtown.Properties["name"] != "Downtown" {
+		t.Errorf("expected the downtown feature, got %+v", downtown)
+	}
+	if len(downtown.Area) != 1 || len(downtown.Area[0].Holes) != 1 {
+		t.Fatalf("expected one polygon with a hole, got %+v", downtown.Area)
+	}
+	if p := downtown.Area[0].Outer[1]; p != (Point{Lat: 0, Lng: 10}) {
+		t.Errorf("expected positions read as [lng, lat], got %+v", p)
+	}
+	if len(zones[1].Area) != 2 {
+		t.Errorf("expected 2 islands, got %d", len(zones[1].Area))
+	}
+
+	locations := []Location{
+		{Name: "center", Latitude: 5, Longitude: 5},
+		{Name: "corner", Latitude: 1, Longitude: 2},
+		{Name: "island", Latitude: 31, Longitude: 33},
+	}
+	if result := Apply(locations, WithinMultiPolygon[Location]("Latitude", "Longitude", downtown.Area)); len(result) != 1 || result[0].Name != "corner" {
+		t.Errorf("expected only the corner downtown, got %v", result)
+	}
+	if result := Apply(locations, WithinMultiPolygon[Location]("Latitude", "Longitude", zones[1].Area)); len(result) != 1 || result[0].Name != "island" {
+		t.Errorf("expected only the island, got %v", result)
+	}
+
+	bare, err := ParseGeoJSON([]byte(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}`))
+	if err != nil || len(bare) != 1 || bare[0].ID != nil {
+		t.Errorf("expected one zone from a bare polygon, got %+v, %v", bare, err)
+	}
+}
+
+func TestParseGeoJSONErrors(t *testing.T) {
+	for _, doc := range []string{
+		`{"type": "Polygon"`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 0]]]}`,
+		`{"type": "Polygon", "coordinates": [[[0], [1, 0], [1, 1], [0, 0]]]}`,
+		`{"type": "Polygon", "coordinates": []}`,
+		`{"type": "Point", "coordinates": [0, 0]}`,
+		`{"type": "Circle", "coordinates": [0, 0]}`,
+		`{"type": "FeatureCollection", "features": [{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}]}`,
+	} {
+		var invalid *ErrInvalidGeoJSON
+		if _, err := ParseGeoJSON([]byte(doc)); !errors.As(err, &invalid) {
+			t.Errorf("%s: expected ErrInvalidGeoJSON, got %v", doc, err)
+		}
+	}
+}
@@ -0,0 +1,921 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// timeType is the reflect type of time.Time
+var timeType = reflect.TypeOf(time.Time{})
+
+// Types of the values of decoded JSON documents
+var (
+	stringType   = reflect.TypeOf("")
+	anySliceType = reflect.TypeOf([]interface{}{})
+	anyMapType   = reflect.TypeOf(map[string]interface{}{})
+)
+
+// MarshalJSON encodes a filter as JSON. And, Or and Not are encoded as
+// {"and": [...]}, {"or": [...]} and {"not": {...}}, and every other built-in
+// filter as {"field": ..., "op": ..., "value": ...}. Filters that cannot
+// describe themselves, such as Custom or a FilterFunc, return ErrNotSerializable.
+//
+// Example:
+//
+//	data, err := filter.MarshalJSON(filter.And(filter.Gt[User]("Age", 18)))
+//	// {"and":[{"field":"Age","op":"gt","value":18}]}
+func MarshalJSON[T any](f Filter[T]) ([]byte, error) {
+	return Describe(f).MarshalJSON()
+}
+
+// ParseJSON decodes a filter encoded by MarshalJSON for items of type T.
+// Field paths are checked against T and values are converted to the type of
+// their field, so unknown fields, unknown operators and mismatched values are
+// reported as errors instead of producing a filter that never matches.
+//
+// Example:
+//
+//	f, err := filter.ParseJSON[User]([]byte(`{"field":"City","op":"in","value":["SP","RJ"]}`))
+func ParseJSON[T any](data []byte) (Filter[T], error) {
+	var e Expr
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return Compile[T](e)
+}
+
+// MarshalJSON implements json.Marshaler, so built-in filters can be stored
+// inside larger JSON documents such as saved searches.
+func (n *node[T]) MarshalJSON() ([]byte, error) {
+	return n.Expr().MarshalJSON()
+}
+
+// jsonLeaf is the JSON encoding of an expression that is not And, Or or Not
+type jsonLeaf struct {
+	Field string           `json:"field"`
+	Op    Op               `json:"op"`
+	Value interface{}      `json:"value,omitempty"`
+	Dates *jsonDateOptions `json:"dates,omitempty"`
+}
+
+// jsonDateOptions is the JSON encoding of DateOptions
+type jsonDateOptions struct {
+	Layouts  []string `json:"layouts,omitempty"`
+	Location string   `json:"location,omitempty"`
+}
+
+// jsonPoint is the JSON encoding of a Point
+type jsonPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// jsonCircle is the JSON encoding of a Circle
+type jsonCircle struct {
+	Center   jsonPoint `json:"center"`
+	RadiusKm float64   `json:"radius_km"`
+}
+
+// jsonBox is the JSON encoding of a BoundingBox
+type jsonBox struct {
+	SouthWest jsonPoint `json:"south_west"`
+	NorthEast jsonPoint `json:"north_east"`
+}
+
+// jsonTimeRange is the JSON encoding of a TimeRange
+type jsonTimeRange struct {
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	ExcludeStart bool      `json:"exclude_start,omitempty"`
+	ExcludeEnd   bool      `json:"exclude_end,omitempty"`
+}
+
+// jsonDatePart is the JSON encoding of a DatePartCondition. Location is the
+// name of the time zone, as accepted by time.LoadLocation.
+type jsonDatePart struct {
+	Part     TimePart    `json:"part"`
+	Op       Op          `json:"op"`
+	Value    interface{} `json:"value"`
+	Location string      `json:"location,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler for Expr, using the encoding
+// described in MarshalJSON.
+func (e Expr) MarshalJSON() ([]byte, error) {
+	switch e.Op {
+	case OpAnd, OpOr:
+		children := e.Children
+		if children == nil {
+			children = []Expr{}
+		}
+		return json.Marshal(map[Op][]Expr{e.Op: children})
+	case OpNot:
+		if len(e.Children) != 1 {
+			return nil, fmt.Errorf("filter: not expects exactly one operand, got %d", len(e.Children))
+		}
+		return json.Marshal(map[Op]Expr{OpNot: e.Children[0]})
+	case OpCustom:
+		return nil, &ErrNotSerializable{}
+	}
+
+	leaf := jsonLeaf{Field: e.Field, Op: e.Op, Value: encodeOperand(e.Value)}
+	if e.Dates != nil {
+		leaf.Dates = &jsonDateOptions{Layouts: e.Dates.Layouts}
+		if e.Dates.Location != nil {
+			leaf.Dates.Location = e.Dates.Location.String()
+		}
+	}
+	return json.Marshal(leaf)
+}
+
+// encodeOperand converts operands that have no natural JSON encoding
+func encodeOperand(value interface{}) interface{} {
+	switch v := value.(type) {
+	case Circle:
+		return jsonCircle{Center: jsonPoint(v.Center), RadiusKm: v.RadiusKm}
+	case BoundingBox:
+		return jsonBox{SouthWest: jsonPoint(v.SouthWest), NorthEast: jsonPoint(v.NorthEast)}
+	case MultiPolygon:
+		return geometryOf(v)
+	case TimeRange:
+		return jsonTimeRange(v)
+	case DatePartCondition:
+		part := jsonDatePart{Part: v.Part, Op: v.Op, Value: encodeOperand(v.Value)}
+		if v.Location != nil {
+			part.Location = v.Location.String()
+		}
+		return part
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = val
+		}
+		return m
+	default:
+		return value
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler for Expr. Numbers in Value are
+// decoded as json.Number; Compile converts them to the type of the field.
+// Unknown keys are reported as errors.
+func (e *Expr) UnmarshalJSON(data []byte) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	for _, op := range []Op{OpAnd, OpOr, OpNot} {
+		raw, ok := obj[string(op)]
+		if !ok {
+			continue
+		}
+		if len(obj) != 1 {
+			return fmt.Errorf("filter: %q cannot be combined with other keys", op)
+		}
+
+		*e = Expr{Op: op}
+		if op == OpNot {
+			var child Expr
+			if err := json.Unmarshal(raw, &child); err != nil {
+				return err
+			}
+			e.Children = []Expr{child}
+			return nil
+		}
+		return json.Unmarshal(raw, &e.Children)
+	}
+
+	*e = Expr{}
+	for key, raw := range obj {
+		var err error
+		switch key {
+		case "field":
+			err = json.Unmarshal(raw, &e.Field)
+		case "op":
+			err = json.Unmarshal(raw, &e.Op)
+		case "value":
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.UseNumber()
+			err = decoder.Decode(&e.Value)
+		case "dates":
+			err = e.unmarshalDates(raw)
+		default:
+			return fmt.Errorf("filter: unknown key %q", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if e.Op == "" {
+		return fmt.Errorf("filter: missing \"op\"")
+	}
+	return nil
+}
+
+// unmarshalDates decodes the date options of an expression
+func (e *Expr) unmarshalDates(raw json.RawMessage) error {
+	var dates jsonDateOptions
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&dates); err != nil {
+		return err
+	}
+
+	e.Dates = &DateOptions{Layouts: dates.Layouts}
+	if dates.Location != "" {
+		loc, err := time.LoadLocation(dates.Location)
+		if err != nil {
+			return fmt.Errorf("filter: unknown time zone %q", dates.Location)
+		}
+		e.Dates.Location = loc
+	}
+	return nil
+}
+
+// Compile builds the filter an expression describes, for items of type T.
+// It reverses Describe: Compile(Describe(f)) behaves like f for every
+// built-in filter. Fields and values are checked as in ParseJSON.
+//
+// Example:
+//
+//	f, err := filter.Compile[User](filter.Expr{Field: "Age", Op: filter.OpGt, Value: 18})
+func Compile[T any](e Expr) (Filter[T], error) {
+	return compile[T](e, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// compile builds the filter of an expression, checking it against itemType
+func compile[T any](e Expr, itemType reflect.Type) (Filter[T], error) {
+	switch e.Op {
+	case OpAnd, OpOr:
+		children := make([]Filter[T], len(e.Children))
+		for i, child := range e.Children {
+			f, err := compile[T](child, itemType)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = f
+		}
+		if e.Op == OpAnd {
+			return And(children...), nil
+		}
+		return Or(children...), nil
+	case OpNot:
+		if len(e.Children) != 1 {
+			return nil, fmt.Errorf("filter: not expects exactly one operand, got %d", len(e.Children))
+		}
+		child, err := compile[T](e.Children[0], itemType)
+		if err != nil {
+			return nil, err
+		}
+		return Not(child), nil
+	case OpCustom:
+		return nil, &ErrNotSerializable{}
+	case OpSearch:
+		return compileSearch[T](e, itemType)
+	case OpWithinRadius, OpWithinBox, OpWithinPolygon:
+		return compileGeo[T](e, itemType)
+	}
+
+	ft, err := lookupField(itemType, e.Field)
+	if err != nil {
+		return nil, err
+	}
+	if ft.Kind() == reflect.Interface {
+		ft = dynamicType(e, ft)
+	}
+
+	switch e.Op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+		if !isComparable(ft) {
+			return nil, invalidOperand(e, fmt.Sprintf("fields of type %s cannot be compared", ft))
+		}
+		value, err := coerceOperand(e, e.Value, ft)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case OpEq:
+			return Eq[T](e.Field, value), nil
+		case OpNe:
+			return Ne[T](e.Field, value), nil
+		case OpGt:
+			return Gt[T](e.Field, value), nil
+		case OpGte:
+			return Gte[T](e.Field, value), nil
+		case OpLt:
+			return Lt[T](e.Field, value), nil
+		default:
+			return Lte[T](e.Field, value), nil
+		}
+
+	case OpIn, OpBetween:
+		if !isComparable(ft) {
+			return nil, invalidOperand(e, fmt.Sprintf("fields of type %s cannot be compared", ft))
+		}
+		if e.Op == OpBetween {
+			values, err := coerceList(e, ft, 2)
+			if err != nil {
+				return nil, err
+			}
+			return Between[T](e.Field, values[0], values[1]), nil
+		}
+
+		values, err := coerceList(e, ft, -1)
+		if err != nil {
+			return nil, err
+		}
+		return In[T](e.Field, values), nil
+
+	case OpExact, OpIExact, OpSubstring, OpISubstring, OpPrefix, OpIPrefix, OpSuffix, OpISuffix:
+		if ft.Kind() != reflect.String {
+			return nil, invalidOperand(e, "field is not a string")
+		}
+		value, err := stringOperand(e, e.Value)
+		if err != nil {
+			return nil, err
+		}
+		options, _ := stringMatchOptions(e.Op)
+		return StringMatch[T](e.Field, value, options), nil
+
+	case OpContains:
+		switch ft.Kind() {
+		case reflect.String:
+			value, err := stringOperand(e, e.Value)
+			if err != nil {
+				return nil, err
+			}
+			return Contains[T](e.Field, value), nil
+		case reflect.Slice, reflect.Array:
+			value, err := coerceOperand(e, e.Value, ft.Elem())
+			if err != nil {
+				return nil, err
+			}
+			return Contains[T](e.Field, value), nil
+		}
+		return nil, invalidOperand(e, "field is not a string, slice or array")
+
+	case OpIsNil:
+		return IsNil[T](e.Field), nil
+
+	case OpIsZero:
+		return IsZero[T](e.Field), nil
+
+	case OpRegex:
+		pattern, err := stringOperand(e, e.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, invalidOperand(e, err.Error())
+		}
+		return RegexMatch[T](e.Field, pattern), nil
+
+	case OpArrayContains, OpIArrayContains, OpArrayContainsAny, OpArrayContainsAll:
+		if ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array {
+			return nil, invalidOperand(e, "field is not a slice or array")
+		}
+		if e.Op == OpArrayContains || e.Op == OpIArrayContains {
+			value, err := coerceOperand(e, e.Value, ft.Elem())
+			if err != nil {
+				return nil, err
+			}
+			return ArrayContains[T](e.Field, value, e.Op == OpIArrayContains), nil
+		}
+		values, err := coerceList(e, ft.Elem(), -1)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == OpArrayContainsAny {
+			return ArrayContainsAny[T](e.Field, values), nil
+		}
+		return ArrayContainsAll[T](e.Field, values), nil
+
+	case OpDateBefore, OpDateAfter, OpDateBetween:
+		if ft.Kind() != reflect.String && ft != timeType {
+			return nil, invalidOperand(e, "field is not a string or time.Time")
+		}
+		if e.Op == OpDateBetween {
+			dates, err := coerceList(e, timeType, 2)
+			if err != nil {
+				return nil, err
+			}
+			return DateBetween[T](e.Field, dates[0].(time.Time), dates[1].(time.Time), e.dateOptions()...), nil
+		}
+
+		date, err := coerceOperand(e, e.Value, timeType)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == OpDateBefore {
+			return DateBefore[T](e.Field, date.(time.Time), e.dateOptions()...), nil
+		}
+		return DateAfter[T](e.Field, date.(time.Time), e.dateOptions()...), nil
+
+	case OpDateRange:
+		if ft.Kind() != reflect.String && ft != timeType {
+			return nil, invalidOperand(e, "field is not a string or time.Time")
+		}
+		r, ok := e.Value.(TimeRange)
+		if !ok {
+			var jr jsonTimeRange
+			if err := decodeOperand(e, &jr); err != nil {
+				return nil, err
+			}
+			r = TimeRange(jr)
+		}
+		return DateRange[T](e.Field, r, e.dateOptions()...), nil
+
+	case OpDatePart:
+		if ft.Kind() != reflect.String && ft != timeType {
+			return nil, invalidOperand(e, "field is not a string or time.Time")
+		}
+		return compileDatePart[T](e)
+
+	case OpHasKey, OpHasValue, OpKeyValue, OpMapContainsAll, OpMapContainsAny, OpMapSizeEq, OpMapSizeGt, OpMapSizeLt:
+		if ft.Kind() != reflect.Map {
+			return nil, invalidOperand(e, "field is not a map")
+		}
+		return compileMap[T](e, ft)
+
+	case OpFuzzy, OpSimilar:
+		if ft.Kind() != reflect.String {
+			return nil, invalidOperand(e, "field is not a string")
+		}
+		args, ok := e.Value.([]interface{})
+		if !ok || len(args) != 2 {
+			return nil, invalidOperand(e, "expected [term, limit]")
+		}
+		term, err := stringOperand(e, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == OpFuzzy {
+			maxDistance, err := coerceOperand(e, args[1], reflect.TypeOf(0))
+			if err != nil {
+				return nil, err
+			}
+			return Fuzzy[T](e.Field, term, maxDistance.(int)), nil
+		}
+		threshold, err := coerceOperand(e, args[1], reflect.TypeOf(0.0))
+		if err != nil {
+			return nil, err
+		}
+		return Similar[T](e.Field, term, threshold.(float64)), nil
+	}
+
+	return nil, &ErrUnknownOperator{Op: e.Op}
+}
+
+// compileDatePart builds a DatePartIn filter
+func compileDatePart[T any](e Expr) (Filter[T], error) {
+	c, ok := e.Value.(DatePartCondition)
+	if !ok {
+		var jc jsonDatePart
+		if err := decodeOperand(e, &jc); err != nil {
+			return nil, err
+		}
+		c = DatePartCondition{Part: jc.Part, Op: jc.Op, Value: jc.Value}
+		if jc.Location != "" {
+			loc, err := time.LoadLocation(jc.Location)
+			if err != nil {
+				return nil, invalidOperand(e, fmt.Sprintf("unknown time zone %q", jc.Location))
+			}
+			c.Location = loc
+		}
+	}
+
+	if !c.Part.valid() {
+		return nil, invalidOperand(e, fmt.Sprintf("unknown date part %q", c.Part))
+	}
+	switch c.Op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn, OpBetween:
+	default:
+		return nil, invalidOperand(e, fmt.Sprintf("date parts cannot be compared with %s", c.Op))
+	}
+	if _, ok := c.match(); !ok {
+		return nil, invalidOperand(e, "expected an integer, a list of integers for in or [min, max] for between")
+	}
+	return DatePartIn[T](e.Field, c.Part, c.Location, c.Op, c.Value, e.dateOptions()...), nil
+}
+
+// dateOptions returns the date options of an expression as date filters take them
+func (e Expr) dateOptions() []DateOptions {
+	if e.Dates == nil {
+		return nil
+	}
+	return []DateOptions{*e.Dates}
+}
+
+// compileMap builds the map filter of an expression on a map field of type ft
+func compileMap[T any](e Expr, ft reflect.Type) (Filter[T], error) {
+	switch e.Op {
+	case OpHasKey:
+		key, err := coerceOperand(e, e.Value, ft.Key())
+		if err != nil {
+			return nil, err
+		}
+		return HasKey[T](e.Field, key), nil
+
+	case OpHasValue:
+		value, err := coerceOperand(e, e.Value, ft.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return HasValue[T](e.Field, value), nil
+
+	case OpKeyValue:
+		pair, ok := e.Value.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, invalidOperand(e, "expected [key, value]")
+		}
+		key, err := coerceMapKey(e, pair[0], ft.Key())
+		if err != nil {
+			return nil, err
+		}
+		value, err := coerceOperand(e, pair[1], ft.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return KeyValueEquals[T](e.Field, key, value), nil
+
+	case OpMapContainsAll, OpMapContainsAny:
+		kvPairs := make(map[interface{}]interface{})
+		switch m := e.Value.(type) {
+		case map[interface{}]interface{}:
+			for k, v := range m {
+				if err := addPair(e, kvPairs, k, v, ft); err != nil {
+					return nil, err
+				}
+			}
+		case map[string]interface{}:
+			for k, v := range m {
+				if err := addPair(e, kvPairs, k, v, ft); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, invalidOperand(e, "expected an object")
+		}
+		if e.Op == OpMapContainsAll {
+			return MapContainsAll[T](e.Field, kvPairs), nil
+		}
+		return MapContainsAny[T](e.Field, kvPairs), nil
+	}
+
+	size, err := coerceOperand(e, e.Value, reflect.TypeOf(0))
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case OpMapSizeEq:
+		return MapSizeEquals[T](e.Field, size.(int)), nil
+	case OpMapSizeGt:
+		return MapSizeGreaterThan[T](e.Field, size.(int)), nil
+	default:
+		return MapSizeLessThan[T](e.Field, size.(int)), nil
+	}
+}
+
+// addPair converts a key-value pair to the key and value types of a map
+// field of type ft and adds it to kvPairs
+func addPair(e Expr, kvPairs map[interface{}]interface{}, k, v interface{}, ft reflect.Type) error {
+	key, err := coerceMapKey(e, k, ft.Key())
+	if err != nil {
+		return err
+	}
+	value, err := coerceOperand(e, v, ft.Elem())
+	if err != nil {
+		return err
+	}
+	kvPairs[key] = value
+	return nil
+}
+
+// compileSearch builds a Search filter, checking every searched field
+func compileSearch[T any](e Expr, itemType reflect.Type) (Filter[T], error) {
+	fields := strings.Split(e.Field, ",")
+	for _, field := range fields {
+		if _, err := lookupField(itemType, field); err != nil {
+			return nil, err
+		}
+	}
+
+	text, err := stringOperand(e, e.Value)
+	if err != nil {
+		return nil, err
+	}
+	return Search[T](text, fields...), nil
+}
+
+// compileGeo builds a WithinRadius, WithinBoundingBox or WithinMultiPolygon filter.
+// Field holds the latitude and longitude fields separated by a comma.
+func compileGeo[T any](e Expr, itemType reflect.Type) (Filter[T], error) {
+	fields := strings.Split(e.Field, ",")
+	if len(fields) != 2 {
+		return nil, invalidOperand(e, "expected latitude and longitude fields separated by a comma")
+	}
+	for _, field := range fields {
+		ft, err := lookupField(itemType, field)
+		if err != nil {
+			return nil, err
+		}
+		if !isNumber(ft.Kind()) && ft.Kind() != reflect.Interface {
+			return nil, invalidOperand(e, fmt.Sprintf("field %s is not a number", field))
+		}
+	}
+	latField, lngField := fields[0], fields[1]
+
+	if e.Op == OpWithinRadius {
+		circle, ok := e.Value.(Circle)
+		if !ok {
+			var c jsonCircle
+			if err := decodeOperand(e, &c); err != nil {
+				return nil, err
+			}
+			circle = Circle{Center: Point(c.Center), RadiusKm: c.RadiusKm}
+		}
+		return WithinRadius[T](latField, lngField, circle.Center, circle.RadiusKm), nil
+	}
+
+	if e.Op == OpWithinPolygon {
+		polygons, ok := e.Value.(MultiPolygon)
+		if !ok {
+			var g geoJSONObject
+			if err := decodeOperand(e, &g); err != nil {
+				return nil, err
+			}
+			var err error
+			if polygons, err = g.polygons(); err != nil {
+				return nil, invalidOperand(e, err.Error())
+			}
+		}
+		if err := checkPolygons(polygons); err != nil {
+			return nil, invalidOperand(e, err.Error())
+		}
+		return WithinMultiPolygon[T](latField, lngField, polygons), nil
+	}
+
+	box, ok := e.Value.(BoundingBox)
+	if !ok {
+		var b jsonBox
+		if err := decodeOperand(e, &b); err != nil {
+			return nil, err
+		}
+		box = BoundingBox{SouthWest: Point(b.SouthWest), NorthEast: Point(b.NorthEast)}
+	}
+	return WithinBoundingBox[T](latField, lngField, box), nil
+}
+
+// decodeOperand decodes a structured operand into target through its JSON form
+func decodeOperand(e Expr, target interface{}) error {
+	data, err := json.Marshal(e.Value)
+	if err == nil {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(target)
+	}
+	if err != nil {
+		return invalidOperand(e, err.Error())
+	}
+	return nil
+}
+
+// lookupField returns the type of a field path of itemType
+func lookupField(itemType reflect.Type, field string) (reflect.Type, error) {
+	t, err := fieldType(itemType, field)
+	if err != nil {
+		var invalid *ErrInvalidPath
+		if errors.As(err, &invalid) {
+			return nil, invalid
+		}
+		return nil, &ErrUnknownField{Field: field}
+	}
+	return t, nil
+}
+
+// invalidOperand returns an ErrInvalidOperand for an expression
+func invalidOperand(e Expr, reason string) error {
+	return &ErrInvalidOperand{Field: e.Field, Op: e.Op, Reason: reason}
+}
+
+// stringOperand returns an operand that must be a string
+func stringOperand(e Expr, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", invalidOperand(e, fmt.Sprintf("expected a string, got %v", value))
+	}
+	return s, nil
+}
+
+// coerceList converts a list operand to elements of type t.
+// When n is not negative the list must have exactly n elements.
+func coerceList(e Expr, t reflect.Type, n int) ([]interface{}, error) {
+	list, ok := e.Value.([]interface{})
+	if !ok {
+		return nil, invalidOperand(e, "expected a list")
+	}
+	if n >= 0 && len(list) != n {
+		return nil, invalidOperand(e, fmt.Sprintf("expected %d values, got %d", n, len(list)))
+	}
+
+	values := make([]interface{}, len(list))
+	for i, v := range list {
+		value, err := coerceOperand(e, v, t)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// coerceMapKey converts a map key operand to type t. JSON object keys are
+// always strings, so numeric keys are parsed from their text.
+func coerceMapKey(e Expr, key interface{}, t reflect.Type) (interface{}, error) {
+	if s, ok := key.(string); ok && isNumber(t.Kind()) {
+		key = json.Number(s)
+	}
+	return coerceOperand(e, key, t)
+}
+
+// coerceOperand converts an operand to type t.
+// Numbers convert between numeric types as long as no precision is lost,
+// strings convert to time.Time when they hold an RFC 3339 time, and any value
+// is accepted for interface types, with whole JSON numbers becoming int.
+func coerceOperand(e Expr, value interface{}, t reflect.Type) (interface{}, error) {
+	if n, ok := value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			value = i
+		} else if f, err := n.Float64(); err == nil {
+			value = f
+		} else {
+			return nil, invalidOperand(e, fmt.Sprintf("invalid number %s", n))
+		}
+		if i, ok := value.(int64); ok && t.Kind() == reflect.Interface && i == int64(int(i)) {
+			value = int(i)
+		}
+	}
+
+	if value == nil {
+		return nil, invalidOperand(e, "missing value")
+	}
+	if t.Kind() == reflect.Interface {
+		return value, nil
+	}
+
+	if t == timeType {
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			date, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, invalidOperand(e, fmt.Sprintf("%q is not an RFC 3339 time", v))
+			}
+			return date, nil
+		}
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case t.Kind() == reflect.String && v.Kind() == reflect.String,
+		t.Kind() == reflect.Bool && v.Kind() == reflect.Bool:
+		return v.Convert(t).Interface(), nil
+	case isNumber(t.Kind()) && isNumber(v.Kind()):
+		if converted, ok := convertNumber(v, t); ok {
+			return converted, nil
+		}
+		return nil, invalidOperand(e, fmt.Sprintf("%v does not fit in %s", value, t))
+	}
+
+	return nil, invalidOperand(e, fmt.Sprintf("cannot use %v as %s", value, t))
+}
+
+// convertNumber converts a numeric value to numeric type t.
+// Returns false if the value would overflow or lose its fractional part.
+func convertNumber(v reflect.Value, t reflect.Type) (interface{}, bool) {
+	out := reflect.New(t).Elem()
+
+	switch {
+	case isInt(t.Kind()):
+		var i int64
+		switch {
+		case isInt(v.Kind()):
+			i = v.Int()
+		case isUint(v.Kind()):
+			if v.Uint() > math.MaxInt64 {
+				return nil, false
+			}
+			i = int64(v.Uint())
+		default:
+			f := v.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return nil, false
+			}
+			i = int64(f)
+		}
+		if out.OverflowInt(i) {
+			return nil, false
+		}
+		out.SetInt(i)
+
+	case isUint(t.Kind()):
+		var u uint64
+		switch {
+		case isInt(v.Kind()):
+			if v.Int() < 0 {
+				return nil, false
+			}
+			u = uint64(v.Int())
+		case isUint(v.Kind()):
+			u = v.Uint()
+		default:
+			f := v.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return nil, false
+			}
+			u = uint64(f)
+		}
+		if out.OverflowUint(u) {
+			return nil, false
+		}
+		out.SetUint(u)
+
+	default:
+		var f float64
+		switch {
+		case isInt(v.Kind()):
+			f = float64(v.Int())
+		case isUint(v.Kind()):
+			f = float64(v.Uint())
+		default:
+			f = v.Float()
+		}
+		if out.OverflowFloat(f) {
+			return nil, false
+		}
+		out.SetFloat(f)
+	}
+
+	return out.Interface(), true
+}
+
+// isComparable reports whether compareValues supports fields of type t.
+// Interface fields hold values whose type is only known per item.
+func isComparable(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t.Kind() == reflect.Bool || isNumber(t.Kind()) || t.Kind() == reflect.Interface || t == timeType
+}
+
+// dynamicType returns the type an operator works on, for fields declared with
+// an interface type such as the values of a map[string]interface{}. Their
+// values only have a type per item, so operands are checked against the type
+// the operator needs; comparisons accept any operand.
+func dynamicType(e Expr, ft reflect.Type) reflect.Type {
+	switch e.Op {
+	case OpExact, OpIExact, OpSubstring, OpISubstring, OpPrefix, OpIPrefix, OpSuffix, OpISuffix,
+		OpRegex, OpFuzzy, OpSimilar, OpDateBefore, OpDateAfter, OpDateBetween, OpDateRange, OpDatePart:
+		return stringType
+	case OpContains:
+		if _, ok := e.Value.(string); ok {
+			return stringType
+		}
+		return anySliceType
+	case OpArrayContains, OpIArrayContains, OpArrayContainsAny, OpArrayContainsAll:
+		return anySliceType
+	case OpHasKey, OpHasValue, OpKeyValue, OpMapContainsAll, OpMapContainsAny, OpMapSizeEq, OpMapSizeGt, OpMapSizeLt:
+		return anyMapType
+	}
+	return ft
+}
+
+// isNumber reports whether k is a numeric kind
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+// isInt reports whether k is a signed integer kind
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// isUint reports whether k is an unsigned integer kind
+func isUint(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
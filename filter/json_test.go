@@ -0,0 +1,212 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	f := And(Gt[Person]("Age", 18), Not(In[Person]("Address.City", []interface{}{"SP", "RJ"})))
+
+	data, err := MarshalJSON(f)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `{"and":[{"field":"Age","op":"gt","value":18},{"not":{"field":"Address.City","op":"in","value":["SP","RJ"]}}]}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	// Filters can be embedded in other documents
+	saved, err := json.Marshal(map[string]Filter[Person]{"adults": Gte[Person]("Age", 18)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(saved) != `{"adults":{"field":"Age","op":"gte","value":18}}` {
+		t.Errorf("Unexpected embedded encoding: %s", saved)
+	}
+
+	_, err = MarshalJSON(Or(Eq[Person]("Name", "Alice"), Custom(func(Person) bool { return true })))
+	var notSerializable *ErrNotSerializable
+	if !errors.As(err, &notSerializable) {
+		t.Errorf("Expected ErrNotSerializable for a custom filter, got %v", err)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	people := []Person{
+		{Name: "Alice", Age: 25, Hobbies: []string{"reading"}, Address: &Address{City: "SP"}},
+		{Name: "Bob", Age: 17, Hobbies: []string{"gaming"}, Address: &Address{City: "RJ"}},
+		{Name: "Carol", Age: 30, Address: &Address{City: "MG"}},
+	}
+
+	f, err := ParseJSON[Person]([]byte(`{"and":[
+		{"field":"Age","op":"gt","value":18},
+		{"not":{"field":"Address.City","op":"eq","value":"MG"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := Apply(people, f)
+	if len(result) != 1 || result[0].Name != "Alice" {
+		t.Errorf("Expected only Alice, got %v", result)
+	}
+
+	// JSON numbers are converted to the type of the field
+	if v := Describe(f).Children[0].Value; v != 18 {
+		t.Errorf("Expected value 18 as int, got %T %v", v, v)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	people := []Person{
+		{Name: "Alice", Age: 25, Hobbies: []string{"Reading", "chess"}, Address: &Address{City: "SP"}},
+		{Name: "Bob", Age: 17, Hobbies: []string{"gaming"}, Address: &Address{City: "RJ"}},
+		{Name: "Carol", Age: 30},
+	}
+
+	filters := []Filter[Person]{
+		Eq[Person]("Name", "Bob"),
+		Ne[Person]("Age", 17),
+		Lte[Person]("Age", 25),
+		Between[Person]("Age", 18, 30),
+		In[Person]("Name", []interface{}{"Alice", "Carol"}),
+		Contains[Person]("Hobbies", "chess"),
+		StringMatch[Person]("Name", "AL", StringMatchOptions{Mode: PrefixMatch, IgnoreCase: true}),
+		RegexMatch[Person]("Name", "^[AB]"),
+		IsNil[Person]("Address"),
+		IsNotZero[Person]("Hobbies"),
+		ArrayContains[Person]("Hobbies", "reading", true),
+		ArrayContainsAny[Person]("Hobbies", []interface{}{"gaming", "chess"}),
+		Search[Person]("sp", "Name", "Address.City"),
+		Fuzzy[Person]("Name", "alise", 1),
+		Similar[Person]("Name", "carl", 0.2),
+		Or(Eq[Person]("Age", 17), Eq[Person]("Age", 30)),
+	}
+
+	for _, f := range filters {
+		data, err := MarshalJSON(f)
+		if err != nil {
+			t.Errorf("MarshalJSON(%+v): %v", Describe(f), err)
+			continue
+		}
+
+		parsed, err := ParseJSON[Person](data)
+		if err != nil {
+			t.Errorf("ParseJSON(%s): %v", data, err)
+			continue
+		}
+
+		want, got := Apply(people, f), Apply(people, parsed)
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: expected %v, got %v", data, want, got)
+		}
+	}
+}
+
+func TestJSONRoundTripStructuredOperands(t *testing.T) {
+	locations := []Location{
+		{Name: "New York", Latitude: 40.7128, Longitude: -74.0060},
+		{Name: "Los Angeles", Latitude: 34.0522, Longitude: -118.2437},
+	}
+	products := []Product{
+		{Name: "Laptop", Attributes: map[string]string{"color": "silver"}, Counts: map[string]int{"stock": 3}},
+		{Name: "Phone", Attributes: map[string]string{"color": "black", "brand": "X"}},
+	}
+	type Event struct {
+		Name string
+		At   time.Time
+	}
+	events := []Event{
+		{Name: "past", At: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "future", At: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	sf := Point{Lat: 37.7749, Lng: -122.4194}
+	checkRoundTrip(t, locations, WithinRadius[Location]("Latitude", "Longitude", sf, 1000))
+	checkRoundTrip(t, locations, WithinBoundingBox[Location]("Latitude", "Longitude", BoundingBox{
+		SouthWest: Point{Lat: 30, Lng: -125}, NorthEast: Point{Lat: 45, Lng: -100},
+	}))
+	checkRoundTrip(t, locations, WithinMultiPolygon[Location]("Latitude", "Longitude", MultiPolygon{
+		{Outer: []Point{{Lat: 30, Lng: -125}, {Lat: 30, Lng: -100}, {Lat: 45, Lng: -100}, {Lat: 45, Lng: -125}}},
+		{Outer: []Point{{Lat: 50, Lng: 0}, {Lat: 50, Lng: 10}, {Lat: 55, Lng: 5}}},
+	}))
+	checkRoundTrip(t, products, KeyValueEquals[Product]("Attributes", "color", "black"))
+	checkRoundTrip(t, products, MapContainsAll[Product]("Attributes", map[interface{}]interface{}{"color": "black", "brand": "X"}))
+	checkRoundTrip(t, products, HasKey[Product]("Counts", "stock"))
+	checkRoundTrip(t, products, MapSizeGreaterThan[Product]("Attributes", 1))
+	checkRoundTrip(t, events, DateAfter[Event]("At", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	checkRoundTrip(t, events, DateBetween[Event]("At", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)))
+	checkRoundTrip(t, events, DateRange[Event]("At", TimeRange{Start: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), ExcludeEnd: true}))
+	checkRoundTrip(t, events, SameDay[Event]("At", time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)))
+	if saoPaulo, err := time.LoadLocation("America/Sao_Paulo"); err == nil {
+		// 2020-01-01 00:00 UTC is still 2019 in São Paulo
+		checkRoundTrip(t, events, DatePartIn[Event]("At", Year, saoPaulo, OpIn, []interface{}{2019}))
+	}
+	checkRoundTrip(t, events, DatePart[Event]("At", Weekday, OpEq, time.Tuesday))
+}
+
+func checkRoundTrip[T any](t *testing.T, items []T, f Filter[T]) {
+	t.Helper()
+
+	data, err := MarshalJSON(f)
+	if err != nil {
+		t.Errorf("MarshalJSON(%+v): %v", Describe(f), err)
+		return
+	}
+
+	parsed, err := ParseJSON[T](data)
+	if err != nil {
+		t.Errorf("ParseJSON(%s): %v", data, err)
+		return
+	}
+
+	want, got := Apply(items, f), Apply(items, parsed)
+	if len(want) == 0 || !reflect.DeepEqual(want, got) {
+		t.Errorf("%s: expected %v, got %v", data, want, got)
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	var unknownField *ErrUnknownField
+	var unknownOp *ErrUnknownOperator
+	var invalid *ErrInvalidOperand
+
+	tests := []struct {
+		input  string
+		target interface{}
+	}{
+		{`{"field":"Agee","op":"gt","value":18}`, &unknownField},
+		{`{"field":"Address.Street","op":"eq","value":"x"}`, &unknownField},
+		{`{"or":[{"field":"Name","op":"eq","value":"a"},{"field":"Age","op":"bigger","value":1}]}`, &unknownOp},
+		{`{"field":"Age","op":"gt","value":"eighteen"}`, &invalid},
+		{`{"field":"Age","op":"gt","value":18.5}`, &invalid},
+		{`{"field":"Age","op":"between","value":[1]}`, &invalid},
+		{`{"field":"Name","op":"regex","value":"("}`, &invalid},
+		{`{"field":"Age","op":"prefix","value":"1"}`, &invalid},
+		{`{"field":"Address","op":"eq","value":"SP"}`, &invalid},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseJSON[Person]([]byte(tt.input))
+		if err == nil || !errors.As(err, tt.target) {
+			t.Errorf("ParseJSON(%s): expected %T, got %v", tt.input, tt.target, err)
+		}
+	}
+
+	for _, input := range []string{
+		`{"field":"Age","op":"gt","value":18,"extra":true}`,
+		`{"and":[],"field":"Age"}`,
+		`{"field":"Age"}`,
+		`[1, 2]`,
+	} {
+		if _, err := ParseJSON[Person]([]byte(input)); err == nil {
+			t.Errorf("ParseJSON(%s): expected an error", input)
+		}
+	}
+}
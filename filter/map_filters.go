@@ -10,12 +10,7 @@ import (
 //
 //	filter.HasKey[Product]("Attrs", "color")  // products with "color" attribute
 func HasKey[T any](fieldName string, key interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpHasKey, Value: key}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Map {
 			return false
 		}
@@ -28,7 +23,7 @@ func HasKey[T any](fieldName string, key interface{}) Filter[T] {
 		}
 
 		return fieldValue.MapIndex(keyValue).IsValid()
-	})
+	}))
 }
 
 // HasValue returns a filter that checks if a map field contains the specified value.
@@ -37,12 +32,7 @@ func HasKey[T any](fieldName string, key interface{}) Filter[T] {
 //
 //	filter.HasValue[Product]("Attrs", "red")  // products with any attribute = "red"
 func HasValue[T any](fieldName string, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpHasValue, Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Map {
 			return false
 		}
@@ -60,7 +50,7 @@ func HasValue[T any](fieldName string, value interface{}) Filter[T] {
 		}
 
 		return false
-	})
+	}))
 }
 
 // KeyValueEquals returns a filter that checks if a specific key in a map has a specific value.
@@ -69,12 +59,7 @@ func HasValue[T any](fieldName string, value interface{}) Filter[T] {
 //
 //	filter.KeyValueEquals[Product]("Attrs", "color", "red")  // products where color = "red"
 func KeyValueEquals[T any](fieldName string, key, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpKeyValue, Value: []interface{}{key, value}}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Map {
 			return false
 		}
@@ -110,17 +95,12 @@ func KeyValueEquals[T any](fieldName string, key, value interface{}) Filter[T] {
 		}
 
 		return equal
-	})
+	}))
 }
 
 // MapContainsAll checks if a map contains all the specified key-value pairs
 func MapContainsAll[T any](fieldName string, kvPairs map[interface{}]interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpMapContainsAll, Value: kvPairs}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Map {
 			return false
 		}
@@ -146,17 +126,12 @@ func MapContainsAll[T any](fieldName string, kvPairs map[interface{}]interface{}
 		}
 
 		return true
-	})
+	}))
 }
 
 // MapContainsAny checks if a map contains any of the specified key-value pairs
 func MapContainsAny[T any](fieldName string, kvPairs map[interface{}]interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpMapContainsAny, Value: kvPairs}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Map {
 			return false
 		}
@@ -182,53 +157,38 @@ func MapContainsAny[T any](fieldName string, kvPairs map[interface{}]interface{}
 		}
 
 		return false
-	})
+	}))
 }
 
 // MapSizeEquals checks if a map has exactly the specified number of entries
 func MapSizeEquals[T any](fieldName string, size int) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpMapSizeEq, Value: size}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Map {
 			return false
 		}
 
 		return fieldValue.Len() == size
-	})
+	}))
 }
 
 // MapSizeGreaterThan checks if a map has more than the specified number of entries
 func MapSizeGreaterThan[T any](fieldName string, size int) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpMapSizeGt, Value: size}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Map {
 			return false
 		}
 
 		return fieldValue.Len() > size
-	})
+	}))
 }
 
 // MapSizeLessThan checks if a map has fewer than the specified number of entries
 func MapSizeLessThan[T any](fieldName string, size int) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpMapSizeLt, Value: size}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		if fieldValue.Kind() != reflect.Map {
 			return false
 		}
 
 		return fieldValue.Len() < size
-	})
+	}))
 }
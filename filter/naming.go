@@ -0,0 +1,131 @@
+package filter
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// jsonNamed holds the types registered with UseJSONNames
+var jsonNamed sync.Map // reflect.Type -> bool
+
+// jsonNaming counts the calls to UseJSONNames. While it is 0, every type
+// skips the lookup, and filters on a concrete type resolve their path again
+// only when it changes.
+var jsonNaming atomic.Int64
+
+// namedPaths caches field paths resolved by json names
+var namedPaths = newPathCache[namedPathKey](maxCachedPaths)
+
+type namedPathKey struct {
+	t    reflect.Type
+	path string
+}
+
+// UseJSONNames makes field paths on items of type T resolve struct fields by
+// the names in their json tags, in T and in every struct reached from it, so
+// that filters, sorts and indexes can use the names APIs and saved filters
+// speak. A field tagged `json:"created_at"` is then found as "created_at",
+// and "author.created_at" reads CreatedAt from the Author field.
+//
+// The registration is process-wide: it applies to every filter, sort and
+// index on T, including filters built before it and in other packages. It
+// only adds names, so a path that named a field before keeps naming it: Go
+// field names win, and a json name that equals the Go name of another field
+// cannot be used. It is meant to be called from an init function.
+//
+// Example:
+//
+//	func init() { filter.UseJSONNames[Post]() }
+//
+//	recent := filter.Apply(posts, filter.Gt[Post]("created_at", lastWeek))
+func UseJSONNames[T any]() {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	jsonNamed.Store(t, true)
+	jsonNaming.Add(1)
+}
+
+// named returns the path with its struct fields named the way items of type
+// t name them: p itself, or a copy with json names replaced by Go field names
+// when t uses json names. Below an interface type, fields are left as written.
+func (p *fieldPath) named(t reflect.Type, path string) *fieldPath {
+	if jsonNaming.Load() == 0 || t == nil {
+		return p
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, ok := jsonNamed.Load(t); !ok {
+		return p
+	}
+
+	key := namedPathKey{t: t, path: path}
+	if named, ok := namedPaths.load(key); ok {
+		return named
+	}
+
+	named := *p
+	named.steps = slices.Clone(p.steps)
+	for i, step := range named.steps {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Struct {
+			if step.kind != stepField && step.kind != stepKey {
+				break
+			}
+			if _, ok := exportedField(t, step.name); !ok {
+				if name, ok := jsonFieldName(t, step.name); ok {
+					named.steps[i].name = name
+				}
+			}
+			field, ok := t.FieldByName(named.steps[i].name)
+			if !ok {
+				break
+			}
+			t = field.Type
+			continue
+		}
+
+		switch t.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array:
+			t = t.Elem()
+			continue
+		}
+		break
+	}
+
+	namedPaths.store(key, &named)
+	return &named
+}
+
+// exportedField returns the exported field of struct type t with the Go
+// name name, possibly promoted from an embedded struct
+func exportedField(t reflect.Type, name string) (reflect.StructField, bool) {
+	field, ok := t.FieldByName(name)
+	if !ok || !field.IsExported() {
+		return reflect.StructField{}, false
+	}
+	return field, true
+}
+
+// jsonFieldName returns the Go name of the exported field of struct type t,
+// possibly promoted from an embedded struct, whose json tag names it name
+func jsonFieldName(t reflect.Type, name string) (string, bool) {
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+		tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tagName == name && tagName != "-" {
+			return field.Name, true
+		}
+	}
+	return "", false
+}
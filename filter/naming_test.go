@@ -0,0 +1,102 @@
+package filter
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+type jsonAuthor struct {
+	Name     string `json:"name"`
+	Verified bool   `json:"is_verified"`
+}
+
+type jsonAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type jsonPost struct {
+	jsonAudit
+	Title  string            `json:"title"`
+	Status string            `json:"Title"`
+	Views  int               `json:"views,omitempty"`
+	Author *jsonAuthor       `json:"author"`
+	Tags   []string          `json:"tags"`
+	Meta   map[string]string `json:"meta"`
+	Draft  bool
+}
+
+// jsonPost is the only type registered with UseJSONNames in the tests
+func init() { UseJSONNames[jsonPost]() }
+
+func TestUseJSONNames(t *testing.T) {
+	now := time.Now()
+	posts := []jsonPost{
+		{jsonAudit: jsonAudit{now}, Title: "Go", Status: "live", Views: 10, Author: &jsonAuthor{"Ana", true}, Tags: []string{"go"}},
+		{jsonAudit: jsonAudit{now.Add(-time.Hour)}, Title: "Rust", Status: "draft", Views: 3, Author: &jsonAuthor{"Bia", false}},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter[jsonPost]
+		want   string
+	}{
+		{"json name", Gt[jsonPost]("views", 5), "Go"},
+		{"Go name", Gt[jsonPost]("Views", 5), "Go"},
+		{"no json tag", Eq[jsonPost]("Draft", false), "Go"},
+		{"nested", Eq[jsonPost]("author.is_verified", true), "Go"},
+		{"promoted", DateAfter[jsonPost]("created_at", now.Add(-time.Minute)), "Go"},
+		{"Go name wins over json name", Eq[jsonPost]("Title", "Rust"), "Rust"},
+		{"shadowed json name", Eq[jsonPost]("Status", "draft"), "Rust"},
+		{"path grammar", Eq[jsonPost]("tags[*]", "go"), "Go"},
+	}
+
+	for _, tt := range tests {
+		result := Apply(posts, tt.filter)
+		if len(result) == 0 || result[0].Title != tt.want {
+			t.Errorf("%s: expected %s first, got %v", tt.name, tt.want, result)
+		}
+	}
+
+	sorted := SortBy(posts, Asc[jsonPost]("views"))
+	if sorted[0].Title != "Rust" {
+		t.Errorf("expected posts sorted by views, got %v", sorted)
+	}
+
+	if err := Validate(And(Eq[jsonPost]("author.name", "Ana"), Gt[jsonPost]("views", 1))); err != nil {
+		t.Errorf("expected json names to validate, got %v", err)
+	}
+
+	// Types that did not opt in keep Go names only
+	people := []Person{{Name: "Alice"}}
+	if len(Apply(people, Eq[Person]("name", "Alice"))) != 0 {
+		t.Error("expected json names to be ignored for Person")
+	}
+}
+
+func TestNamedPathsBounded(t *testing.T) {
+	posts := []jsonPost{{Title: "Go"}}
+	for i := 0; i < maxCachedPaths+100; i++ {
+		Apply(posts, Eq[jsonPost]("meta.key"+strconv.Itoa(i), "x"))
+	}
+	if size := cachedPaths(namedPaths); size > maxCachedPaths {
+		t.Errorf("expected at most %d cached named paths, got %d", maxCachedPaths, size)
+	}
+}
+
+func TestUseJSONNamesAfterBuild(t *testing.T) {
+	type lateNamed struct {
+		Score int `json:"score"`
+	}
+	items := []lateNamed{{1}, {5}}
+
+	f := Gt[lateNamed]("score", 2)
+	if result := Apply(items, f); len(result) != 0 {
+		t.Errorf("expected no matches before UseJSONNames, got %v", result)
+	}
+
+	UseJSONNames[lateNamed]()
+	if result := Apply(items, f); len(result) != 1 || result[0].Score != 5 {
+		t.Errorf("expected the filter to resolve the json name once registered, got %v", result)
+	}
+}
@@ -1,56 +1,43 @@
 package filter
 
 import (
+	"cmp"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 )
 
 // getFieldValue gets the value of a field from a struct by name
-// Supports nested fields with dot notation (e.g., "Address.City")
+// Supports nested fields with dot notation (e.g., "Address.City"), indexes
+// and quoted keys (e.g., "Items[0].Price", `Attrs["color"]`) and len()
+// (e.g., "len(Tags)"). Maps are navigated by key and slices and arrays by
+// index, so paths also work on decoded JSON documents (e.g., "tags.0" on a
+// map[string]interface{}). Interface values are unwrapped, except for a nil
+// interface at the end of the path, which is returned as is. Paths with a
+// wildcard read several values and return an error; see matchField.
 func getFieldValue(item interface{}, fieldPath string) (reflect.Value, error) {
-	value := reflect.ValueOf(item)
-
-	// Handle pointers
-	if value.Kind() == reflect.Ptr {
-		if value.IsNil() {
-			return reflect.Value{}, fmt.Errorf("nil pointer")
-		}
-		value = value.Elem()
+	path, err := lookupPath(reflect.TypeOf(item), fieldPath)
+	if err != nil {
+		return reflect.Value{}, err
 	}
+	return path.value(item)
+}
 
-	if value.Kind() != reflect.Struct {
-		return reflect.Value{}, fmt.Errorf("item is not a struct")
-	}
-
-	// Handle nested fields with dot notation
-	fields := strings.Split(fieldPath, ".")
-	for i, field := range fields {
-		value = value.FieldByName(field)
-
-		if !value.IsValid() {
-			return reflect.Value{}, fmt.Errorf("field %s not found", field)
-		}
-
-		// Handle pointer to struct for nested fields
-		if value.Kind() == reflect.Ptr {
-			if value.IsNil() {
-				return reflect.Value{}, fmt.Errorf("nil pointer for field %s", field)
-			}
-			value = value.Elem()
-		}
-
-		// If not the last field, ensure it's a struct
-		if i < len(fields)-1 && value.Kind() != reflect.Struct {
-			return reflect.Value{}, fmt.Errorf("%s is not a struct", field)
-		}
+// unwrap returns the value held by a non-nil interface value, such as an
+// element of a []interface{}, and any other value unchanged
+func unwrap(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return v.Elem()
 	}
-
-	return value, nil
+	return v
 }
 
 // compareValues compares two values and returns true if they are equal
 func compareValues(a, b reflect.Value) (bool, error) {
+	a, b = unwrap(a), unwrap(b)
+
 	// Handle different types
 	if a.Type() != b.Type() {
 		// Try to convert b to a's type
@@ -61,6 +48,10 @@ func compareValues(a, b reflect.Value) (bool, error) {
 		}
 	}
 
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time)), nil
+	}
+
 	// Compare based on kind
 	switch a.Kind() {
 	case reflect.String:
@@ -81,6 +72,8 @@ func compareValues(a, b reflect.Value) (bool, error) {
 
 // compareValuesLess compares two values and returns true if a < b
 func compareValuesLess(a, b reflect.Value) (bool, error) {
+	a, b = unwrap(a), unwrap(b)
+
 	// Convert if needed
 	if a.Type() != b.Type() {
 		if b.Type().ConvertibleTo(a.Type()) {
@@ -90,6 +83,10 @@ func compareValuesLess(a, b reflect.Value) (bool, error) {
 		}
 	}
 
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Before(b.Interface().(time.Time)), nil
+	}
+
 	// Compare based on kind
 	switch a.Kind() {
 	case reflect.String:
@@ -105,6 +102,164 @@ func compareValuesLess(a, b reflect.Value) (bool, error) {
 	}
 }
 
+// convertExact converts v to t when the conversion keeps its value: numbers
+// must not change sign, overflow or lose a fraction. Other values convert
+// as ConvertibleTo allows.
+func convertExact(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if !v.Type().ConvertibleTo(t) {
+		return v, false
+	}
+	converted := v.Convert(t)
+	if !isNumber(v.Kind()) || !isNumber(t.Kind()) {
+		return converted, true
+	}
+
+	switch {
+	case isInt(v.Kind()) && isUint(t.Kind()) && v.Int() < 0:
+		return v, false
+	case isUint(v.Kind()) && isInt(t.Kind()) && converted.Int() < 0:
+		return v, false
+	}
+	if !converted.Convert(v.Type()).Equal(v) {
+		return v, false
+	}
+	return converted, true
+}
+
+// compareNumbers compares two numbers of any numeric types and returns -1,
+// 0 or +1, or false when either is not a number
+func compareNumbers(a, b reflect.Value) (int, bool) {
+	a, b = unwrap(a), unwrap(b)
+	if !isNumber(a.Kind()) || !isNumber(b.Kind()) {
+		return 0, false
+	}
+
+	switch {
+	case isInt(a.Kind()) && isUint(b.Kind()):
+		if a.Int() < 0 {
+			return -1, true
+		}
+		return cmp.Compare(uint64(a.Int()), b.Uint()), true
+	case isUint(a.Kind()) && isInt(b.Kind()):
+		if b.Int() < 0 {
+			return 1, true
+		}
+		return cmp.Compare(a.Uint(), uint64(b.Int())), true
+	default:
+		return cmp.Compare(floatOf(a), floatOf(b)), true
+	}
+}
+
+// floatOf returns a number as a float64
+func floatOf(v reflect.Value) float64 {
+	switch {
+	case isInt(v.Kind()):
+		return float64(v.Int())
+	case isUint(v.Kind()):
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+// boundHolds reports whether a Gt, Gte, Lt or Lte bound holds for a field
+// that compares c to the bound
+func boundHolds(op Op, c int) bool {
+	switch op {
+	case OpGt:
+		return c > 0
+	case OpGte:
+		return c >= 0
+	case OpLt:
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// compareBound reports whether a field value satisfies a Gt, Gte, Lt or Lte
+// bound on targetValue
+func compareBound(op Op, fieldValue, targetValue reflect.Value) bool {
+	var less, equal bool
+	var err error
+
+	// Convert the bound to the field type up front, so that Gt does not
+	// convert the field to the bound type instead. Bounds the field type
+	// cannot hold, such as -1 for a uint field, are compared as numbers.
+	if targetValue.IsValid() && targetValue.Type() != fieldValue.Type() {
+		if converted, ok := convertExact(targetValue, fieldValue.Type()); ok {
+			targetValue = converted
+		} else if c, ok := compareNumbers(fieldValue, targetValue); ok {
+			return boundHolds(op, c)
+		}
+	}
+
+	switch op {
+	case OpGt:
+		less, err = compareValuesLess(targetValue, fieldValue)
+		return err == nil && less
+	case OpLt:
+		less, err = compareValuesLess(fieldValue, targetValue)
+		return err == nil && less
+	}
+
+	if less, err = compareValuesLess(fieldValue, targetValue); err != nil {
+		return false
+	}
+	if equal, err = compareValues(fieldValue, targetValue); err != nil {
+		return false
+	}
+
+	if op == OpGte {
+		return !less || equal
+	}
+	return less || equal
+}
+
+// valueSet is a hashed set of values for In. Like compareValues, each value is
+// converted to the type of the field it is compared with; the converted keys
+// are built once per field type.
+type valueSet struct {
+	values []interface{}
+	byType sync.Map // reflect.Type -> map[interface{}]bool
+}
+
+// contains reports whether a field value equals any value of the set
+func (s *valueSet) contains(fieldValue reflect.Value) bool {
+	key, ok := valueKey(fieldValue)
+	if !ok {
+		return false
+	}
+	return s.keys(fieldValue.Type())[key]
+}
+
+// keys returns the keys of the set converted to type t
+func (s *valueSet) keys(t reflect.Type) map[interface{}]bool {
+	if keys, ok := s.byType.Load(t); ok {
+		return keys.(map[interface{}]bool)
+	}
+
+	keys := make(map[interface{}]bool, len(s.values))
+	for _, value := range s.values {
+		v := reflect.ValueOf(value)
+		if !v.IsValid() {
+			continue
+		}
+		if v.Type() != t {
+			if !v.Type().ConvertibleTo(t) {
+				continue
+			}
+			v = v.Convert(t)
+		}
+		if key, ok := valueKey(v); ok {
+			keys[key] = true
+		}
+	}
+
+	actual, _ := s.byType.LoadOrStore(t, keys)
+	return actual.(map[interface{}]bool)
+}
+
 // Eq returns a filter that checks if a field equals a value.
 // Supports nested fields using dot notation (e.g., "Address.City").
 //
@@ -112,12 +267,13 @@ func compareValuesLess(a, b reflect.Value) (bool, error) {
 //
 //	filter.Eq[User]("City", "SP")  // users where City == "SP"
 func Eq[T any](fieldName string, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
+	if accessor, converted, ok := compareAccessor[T](fieldName, value); ok {
+		return newNode(Expr{Field: fieldName, Op: OpEq, Value: value}, func(item T) bool {
+			return accessor.Equal(item, converted)
+		})
+	}
 
+	return newNode(Expr{Field: fieldName, Op: OpEq, Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		targetValue := reflect.ValueOf(value)
 		equal, err := compareValues(fieldValue, targetValue)
 		if err != nil {
@@ -125,7 +281,7 @@ func Eq[T any](fieldName string, value interface{}) Filter[T] {
 		}
 
 		return equal
-	})
+	}))
 }
 
 // Ne returns a filter that checks if a field does not equal a value.
@@ -134,12 +290,13 @@ func Eq[T any](fieldName string, value interface{}) Filter[T] {
 //
 //	filter.Ne[User]("Status", "inactive")  // users where Status != "inactive"
 func Ne[T any](fieldName string, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
+	if accessor, converted, ok := compareAccessor[T](fieldName, value); ok {
+		return newNode(Expr{Field: fieldName, Op: OpNe, Value: value}, func(item T) bool {
+			return !accessor.Equal(item, converted)
+		})
+	}
 
+	return newNode(Expr{Field: fieldName, Op: OpNe, Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		targetValue := reflect.ValueOf(value)
 		equal, err := compareValues(fieldValue, targetValue)
 		if err != nil {
@@ -147,7 +304,7 @@ func Ne[T any](fieldName string, value interface{}) Filter[T] {
 		}
 
 		return !equal
-	})
+	}))
 }
 
 // Gt returns a filter that checks if a field is greater than a value.
@@ -157,20 +314,13 @@ func Ne[T any](fieldName string, value interface{}) Filter[T] {
 //
 //	filter.Gt[User]("Age", 18)  // users where Age > 18
 func Gt[T any](fieldName string, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
-		targetValue := reflect.ValueOf(value)
-		less, err := compareValuesLess(targetValue, fieldValue)
-		if err != nil {
-			return false
-		}
+	if match := boundAccessor[T](OpGt, fieldName, value); match != nil {
+		return newNode(Expr{Field: fieldName, Op: OpGt, Value: value}, match)
+	}
 
-		return less
-	})
+	return newNode(Expr{Field: fieldName, Op: OpGt, Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		return compareBound(OpGt, fieldValue, reflect.ValueOf(value))
+	}))
 }
 
 // Lt returns a filter that checks if a field is less than a value.
@@ -180,20 +330,13 @@ func Gt[T any](fieldName string, value interface{}) Filter[T] {
 //
 //	filter.Lt[User]("Age", 65)  // users where Age < 65
 func Lt[T any](fieldName string, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
-		targetValue := reflect.ValueOf(value)
-		less, err := compareValuesLess(fieldValue, targetValue)
-		if err != nil {
-			return false
-		}
+	if match := boundAccessor[T](OpLt, fieldName, value); match != nil {
+		return newNode(Expr{Field: fieldName, Op: OpLt, Value: value}, match)
+	}
 
-		return less
-	})
+	return newNode(Expr{Field: fieldName, Op: OpLt, Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		return compareBound(OpLt, fieldValue, reflect.ValueOf(value))
+	}))
 }
 
 // Gte returns a filter that checks if a field is greater than or equal to a value.
@@ -202,26 +345,13 @@ func Lt[T any](fieldName string, value interface{}) Filter[T] {
 //
 //	filter.Gte[User]("Age", 18)  // users where Age >= 18
 func Gte[T any](fieldName string, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
-		targetValue := reflect.ValueOf(value)
-
-		less, err := compareValuesLess(fieldValue, targetValue)
-		if err != nil {
-			return false
-		}
-
-		equal, err := compareValues(fieldValue, targetValue)
-		if err != nil {
-			return false
-		}
+	if match := boundAccessor[T](OpGte, fieldName, value); match != nil {
+		return newNode(Expr{Field: fieldName, Op: OpGte, Value: value}, match)
+	}
 
-		return !less || equal
-	})
+	return newNode(Expr{Field: fieldName, Op: OpGte, Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		return compareBound(OpGte, fieldValue, reflect.ValueOf(value))
+	}))
 }
 
 // Lte returns a filter that checks if a field is less than or equal to a value.
@@ -230,26 +360,13 @@ func Gte[T any](fieldName string, value interface{}) Filter[T] {
 //
 //	filter.Lte[User]("Age", 65)  // users where Age <= 65
 func Lte[T any](fieldName string, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
-		targetValue := reflect.ValueOf(value)
-
-		less, err := compareValuesLess(fieldValue, targetValue)
-		if err != nil {
-			return false
-		}
-
-		equal, err := compareValues(fieldValue, targetValue)
-		if err != nil {
-			return false
-		}
+	if match := boundAccessor[T](OpLte, fieldName, value); match != nil {
+		return newNode(Expr{Field: fieldName, Op: OpLte, Value: value}, match)
+	}
 
-		return less || equal
-	})
+	return newNode(Expr{Field: fieldName, Op: OpLte, Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		return compareBound(OpLte, fieldValue, reflect.ValueOf(value))
+	}))
 }
 
 // Contains returns a filter that checks if a field contains a value.
@@ -261,12 +378,7 @@ func Lte[T any](fieldName string, value interface{}) Filter[T] {
 //	filter.Contains[User]("Name", "ana")      // users with "ana" in Name
 //	filter.Contains[User]("Tags", "premium")  // users with "premium" in Tags slice
 func Contains[T any](fieldName string, value interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
-
+	return newNode(Expr{Field: fieldName, Op: OpContains, Value: value}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
 		// For strings
 		if fieldValue.Kind() == reflect.String {
 			if valueStr, ok := value.(string); ok {
@@ -289,30 +401,32 @@ func Contains[T any](fieldName string, value interface{}) Filter[T] {
 		}
 
 		return false
-	})
+	}))
 }
 
 // In returns a filter that checks if a field's value is in a list of allowed values.
-// Useful for filtering by multiple possible values.
+// Useful for filtering by multiple possible values. Values are looked up in a
+// hash set, so long lists cost about the same as short ones.
 //
 // Example:
 //
 //	filter.In[User]("City", []interface{}{"SP", "RJ", "MG"})  // users in SP, RJ, or MG
 func In[T any](fieldName string, values []interface{}) Filter[T] {
-	return FilterFunc[T](func(item T) bool {
-		fieldValue, err := getFieldValue(item, fieldName)
-		if err != nil {
-			return false
-		}
+	set := &valueSet{values: values}
 
+	if accessor, ok := accessorFor[T](fieldName); ok && accessor.Type.Comparable() {
+		keys := make(map[interface{}]bool, len(values))
 		for _, value := range values {
-			targetValue := reflect.ValueOf(value)
-			equal, err := compareValues(fieldValue, targetValue)
-			if err == nil && equal {
-				return true
+			if _, converted, ok := compareAccessor[T](fieldName, value); ok {
+				keys[converted] = true
 			}
 		}
+		return newNode(Expr{Field: fieldName, Op: OpIn, Value: values}, func(item T) bool {
+			return keys[accessor.Get(item)]
+		})
+	}
 
-		return false
-	})
+	return newNode(Expr{Field: fieldName, Op: OpIn, Value: values}, matchField[T](fieldName, func(fieldValue reflect.Value) bool {
+		return set.contains(fieldValue)
+	}))
 }
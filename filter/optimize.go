@@ -0,0 +1,345 @@
+package filter
+
+import (
+	"reflect"
+	"slices"
+)
+
+// opCosts estimates the relative cost of evaluating each operator on one item.
+// Cheap comparisons are 1; operators that parse, scan or compute distances
+// cost more.
+var opCosts = map[Op]int{
+	OpEq:     1,
+	OpNe:     1,
+	OpGt:     1,
+	OpGte:    1,
+	OpLt:     1,
+	OpLte:    1,
+	OpIsNil:  1,
+	OpIsZero: 1,
+
+	OpIn:        2,
+	OpBetween:   2,
+	OpExact:     2,
+	OpPrefix:    2,
+	OpSuffix:    2,
+	OpHasKey:    2,
+	OpMapSizeEq: 2,
+	OpMapSizeGt: 2,
+	OpMapSizeLt: 2,
+
+	OpIExact:        3,
+	OpIPrefix:       3,
+	OpISuffix:       3,
+	OpSubstring:     3,
+	OpContains:      3,
+	OpISubstring:    4,
+	OpHasValue:      4,
+	OpKeyValue:      4,
+	OpArrayContains: 4,
+
+	OpWithinBox:        5,
+	OpIArrayContains:   6,
+	OpArrayContainsAny: 6,
+	OpArrayContainsAll: 6,
+	OpMapContainsAll:   6,
+	OpMapContainsAny:   6,
+	OpDateBefore:       8,
+	OpDateAfter:        8,
+	OpDateBetween:      8,
+	OpDateRange:        8,
+	OpDatePart:         8,
+	OpWithinRadius:     10,
+	OpWithinPolygon:    12,
+
+	OpRegex:   20,
+	OpCustom:  30,
+	OpSearch:  40,
+	OpFuzzy:   50,
+	OpSimilar: 50,
+}
+
+// Cost estimates the relative cost of evaluating an expression on one item.
+// Logical operators cost the sum of their operands. The estimates only make
+// sense relative to each other; Optimize uses them to order operands.
+//
+// Example:
+//
+//	filter.Cost(filter.Describe(filter.Eq[User]("City", "SP")))  // 1
+func Cost(e Expr) int {
+	switch e.Op {
+	case OpAnd, OpOr, OpNot:
+		total := 0
+		for _, child := range e.Children {
+			total += Cost(child)
+		}
+		return total
+	}
+
+	if cost, ok := opCosts[e.Op]; ok {
+		return cost
+	}
+	return opCosts[OpCustom]
+}
+
+// Optimize returns a filter equivalent to f that is cheaper to evaluate.
+// It rewrites And, Or and Not trees as follows:
+//
+//   - nested And and Or are flattened, and Not(Not(x)) becomes x
+//   - duplicate operands are removed, as are operands that are always true in
+//     an And (an empty And) or always false in an Or (an empty Or)
+//   - Gt, Gte, Lt and Lte on the same field under an And are merged into a
+//     range that reads the field once
+//   - Eq and In on the same field under an Or are merged into a single In,
+//     which looks values up in a hash set
+//   - operands are reordered so cheap predicates such as equality run before
+//     expensive ones such as regex, geo and fuzzy matching (see Cost)
+//
+// The result describes the same expression up to these rewrites, so it can
+// still be marshaled and served by indexes. Reordering assumes filters have
+// no side effects. Custom filters run after comparisons, string, date, geo
+// and regex matching, but before Search, Fuzzy and Similar.
+//
+// Example:
+//
+//	f := filter.Optimize(filter.And(
+//	    filter.RegexMatch[User]("Email", `@gmail\.com$`),
+//	    filter.Gt[User]("Age", 18),
+//	    filter.Lt[User]("Age", 65),
+//	))
+//	fmt.Println(f)  // ((Age > 18 AND Age < 65) AND Email REGEX "@gmail\\.com$")
+func Optimize[T any](f Filter[T]) Filter[T] {
+	n, ok := f.(*node[T])
+	if !ok {
+		return f
+	}
+
+	switch n.expr.Op {
+	case OpAnd, OpOr:
+		return optimizeLogical(n.expr.Op, n.children)
+	case OpNot:
+		child := Optimize(n.children[0])
+		if inner, ok := child.(*node[T]); ok {
+			switch {
+			case inner.expr.Op == OpNot:
+				return inner.children[0]
+			case isConstant(inner, OpAnd):
+				return Or[T]()
+			case isConstant(inner, OpOr):
+				return And[T]()
+			}
+		}
+		return Not(child)
+	default:
+		return f
+	}
+}
+
+// isConstant reports whether n is an And or Or without operands, which are
+// always true and always false respectively
+func isConstant[T any](n *node[T], op Op) bool {
+	return n.expr.Op == op && len(n.children) == 0
+}
+
+// optimizeLogical optimizes the operands of an And or Or and combines them again
+func optimizeLogical[T any](op Op, children []Filter[T]) Filter[T] {
+	// An empty And is always true and absorbs an Or; an empty Or is always
+	// false and absorbs an And
+	absorbing := OpOr
+	if op == OpOr {
+		absorbing = OpAnd
+	}
+
+	flat := make([]Filter[T], 0, len(children))
+	for _, child := range children {
+		child = Optimize(child)
+
+		if n, ok := child.(*node[T]); ok {
+			if isConstant(n, absorbing) {
+				return child
+			}
+			if n.expr.Op == op {
+				flat = append(flat, n.children...)
+				continue
+			}
+		}
+		flat = append(flat, child)
+	}
+
+	flat = removeDuplicates(flat)
+	if op == OpAnd {
+		flat = mergeRanges(flat)
+	} else {
+		flat = mergeEquals(flat)
+	}
+
+	type costed struct {
+		filter Filter[T]
+		cost   int
+	}
+	ordered := make([]costed, len(flat))
+	for i, f := range flat {
+		ordered[i] = costed{filter: f, cost: Cost(Describe(f))}
+	}
+	slices.SortStableFunc(ordered, func(a, b costed) int {
+		return a.cost - b.cost
+	})
+	for i, c := range ordered {
+		flat[i] = c.filter
+	}
+
+	if len(flat) == 1 {
+		return flat[0]
+	}
+	if op == OpAnd {
+		return And(flat...)
+	}
+	return Or(flat...)
+}
+
+// removeDuplicates drops operands that describe the same expression as an
+// earlier one. Custom filters cannot be compared and are always kept.
+func removeDuplicates[T any](filters []Filter[T]) []Filter[T] {
+	result := make([]Filter[T], 0, len(filters))
+	kept := make([]Expr, 0, len(filters))
+
+	for _, f := range filters {
+		e := Describe(f)
+		duplicate := false
+		if e.Op != OpCustom {
+			for _, k := range kept {
+				if reflect.DeepEqual(e, k) {
+					duplicate = true
+					break
+				}
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		result = append(result, f)
+		kept = append(kept, e)
+	}
+
+	return result
+}
+
+// boundLeaf returns f as a Gt, Gte, Lt or Lte leaf, or nil. Bounds on a
+// wildcard path may hold for different elements, so they are not merged.
+func boundLeaf[T any](f Filter[T]) *node[T] {
+	n, ok := f.(*node[T])
+	if !ok || hasWildcard(n.expr.Field) {
+		return nil
+	}
+	switch n.expr.Op {
+	case OpGt, OpGte, OpLt, OpLte:
+		return n
+	}
+	return nil
+}
+
+// mergeRanges replaces bounds on the same field with a single range filter,
+// placed where the first bound was
+func mergeRanges[T any](filters []Filter[T]) []Filter[T] {
+	bounds := make(map[string][]*node[T])
+	for _, f := range filters {
+		if n := boundLeaf(f); n != nil {
+			bounds[n.expr.Field] = append(bounds[n.expr.Field], n)
+		}
+	}
+
+	result := make([]Filter[T], 0, len(filters))
+	for _, f := range filters {
+		n := boundLeaf(f)
+		if n == nil || len(bounds[n.expr.Field]) < 2 {
+			result = append(result, f)
+			continue
+		}
+
+		group := bounds[n.expr.Field]
+		if group[0] == n {
+			result = append(result, newRange(n.expr.Field, group))
+		}
+	}
+
+	return result
+}
+
+// newRange creates a filter checking several bounds on one field, reading the
+// field once. It is described as an And of the bounds.
+func newRange[T any](fieldName string, bounds []*node[T]) Filter[T] {
+	children := make([]Filter[T], len(bounds))
+	ops := make([]Op, len(bounds))
+	targets := make([]reflect.Value, len(bounds))
+	for i, bound := range bounds {
+		children[i] = bound
+		ops[i] = bound.expr.Op
+		targets[i] = reflect.ValueOf(bound.expr.Value)
+	}
+
+	return newLogicalNode(OpAnd, children, func(item T) bool {
+		fieldValue, err := getFieldValue(item, fieldName)
+		if err != nil {
+			return false
+		}
+
+		for i, op := range ops {
+			if !compareBound(op, fieldValue, targets[i]) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// equalityLeaf returns f as an Eq or In leaf, or nil. With [all], values
+// may differ between elements, so wildcard paths are not merged.
+func equalityLeaf[T any](f Filter[T]) *node[T] {
+	n, ok := f.(*node[T])
+	if !ok || hasWildcard(n.expr.Field) {
+		return nil
+	}
+	switch n.expr.Op {
+	case OpEq, OpIn:
+		return n
+	}
+	return nil
+}
+
+// mergeEquals replaces Eq and In filters on the same field with a single In,
+// placed where the first of them was
+func mergeEquals[T any](filters []Filter[T]) []Filter[T] {
+	groups := make(map[string][]*node[T])
+	for _, f := range filters {
+		if n := equalityLeaf(f); n != nil {
+			groups[n.expr.Field] = append(groups[n.expr.Field], n)
+		}
+	}
+
+	result := make([]Filter[T], 0, len(filters))
+	for _, f := range filters {
+		n := equalityLeaf(f)
+		if n == nil || len(groups[n.expr.Field]) < 2 {
+			result = append(result, f)
+			continue
+		}
+
+		group := groups[n.expr.Field]
+		if group[0] != n {
+			continue
+		}
+
+		var values []interface{}
+		for _, leaf := range group {
+			if leaf.expr.Op == OpIn {
+				values = append(values, leaf.expr.Value.([]interface{})...)
+			} else {
+				values = append(values, leaf.expr.Value)
+			}
+		}
+		result = append(result, In[T](n.expr.Field, values))
+	}
+
+	return result
+}
@@ -0,0 +1,136 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter[Person]
+		want   string
+	}{
+		{
+			"flatten nested",
+			And(Eq[Person]("Name", "Ana"), And(Gt[Person]("Age", 1), And(IsNil[Person]("Address")))),
+			`(Name = "Ana" AND Age > 1 AND Address IS NIL)`,
+		},
+		{
+			"remove duplicates",
+			Or(Eq[Person]("Age", 1), Contains[Person]("Name", "a"), Contains[Person]("Name", "a")),
+			`(Age = 1 OR Name CONTAINS "a")`,
+		},
+		{"double negation", Not(Not(Eq[Person]("Age", 1))), `Age = 1`},
+		{"drop true operands", And(Eq[Person]("Age", 1), And[Person]()), `Age = 1`},
+		{"drop false operands", Or(Eq[Person]("Age", 1), Or[Person]()), `Age = 1`},
+		{"false absorbs and", And(Eq[Person]("Age", 1), Not(And[Person]())), `FALSE`},
+		{"true absorbs or", Or(Eq[Person]("Age", 1), And[Person]()), `TRUE`},
+		{
+			"merge range",
+			And(Gt[Person]("Age", 18), Contains[Person]("Name", "a"), Lte[Person]("Age", 65)),
+			`((Age > 18 AND Age <= 65) AND Name CONTAINS "a")`,
+		},
+		{
+			"merge equalities into in",
+			Or(Eq[Person]("Name", "Ana"), Eq[Person]("Age", 3), In[Person]("Name", []interface{}{"Bia", "Caio"}), Eq[Person]("Name", "Davi")),
+			`(Age = 3 OR Name IN ["Ana","Bia","Caio","Davi"])`,
+		},
+		{
+			"cheap predicates first",
+			And(Fuzzy[Person]("Name", "ana", 1), RegexMatch[Person]("Name", "^A"), Eq[Person]("Age", 20)),
+			`(Age = 20 AND Name REGEX "^A" AND Name FUZZY ["ana",1])`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Describe(Optimize(tt.filter)).String(); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestOptimizeEquivalent(t *testing.T) {
+	people := []Person{
+		{Name: "Ana", Age: 17, Hobbies: []string{"chess"}},
+		{Name: "Bia", Age: 25, Address: &Address{City: "SP"}},
+		{Name: "Caio", Age: 40, Hobbies: []string{"golf"}, Address: &Address{City: "RJ"}},
+		{Name: "Davi", Age: 70},
+	}
+
+	filters := []Filter[Person]{
+		And(Gt[Person]("Age", 18), Lt[Person]("Age", 65), Gte[Person]("Age", 25)),
+		Or(Eq[Person]("Name", "Ana"), Eq[Person]("Name", "Davi"), Eq[Person]("Age", 40)),
+		And(Or(Eq[Person]("Address.City", "SP"), Eq[Person]("Address.City", "RJ")), Not(Not(Gt[Person]("Age", 30)))),
+		Not(And(Custom(func(p Person) bool { return p.Age > 20 }), RegexMatch[Person]("Name", "^[BC]"))),
+		Or(And(Gte[Person]("Age", 17), Lte[Person]("Age", 17)), Contains[Person]("Hobbies", "golf")),
+	}
+
+	for _, f := range filters {
+		want, got := Apply(people, f), Apply(people, Optimize(f))
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%v: optimized to %v, expected %v, got %v", f, Optimize(f), want, got)
+		}
+	}
+}
+
+func TestOptimizeKeepsOpaqueFilters(t *testing.T) {
+	custom := Custom(func(p Person) bool { return true })
+	if got := Optimize(custom); Describe(got).Op != OpCustom {
+		t.Errorf("Expected custom filters to be returned as is, got %v", got)
+	}
+
+	f := And(custom, custom, Eq[Person]("Age", 1))
+	if got := Describe(Optimize(f)); len(got.Children) != 3 || got.Children[0].Op != OpEq {
+		t.Errorf("Expected custom filters to be kept after Eq, got %v", got)
+	}
+}
+
+func TestOptimizeCustomOrder(t *testing.T) {
+	f := And(
+		Similar[Person]("Name", "ana", 0.5),
+		Fuzzy[Person]("Name", "ana", 1),
+		Search[Person]("ana", "Name"),
+		Custom(func(p Person) bool { return true }),
+		RegexMatch[Person]("Name", "^A"),
+		Eq[Person]("Age", 1),
+	)
+
+	want := []Op{OpEq, OpRegex, OpCustom, OpSearch, OpSimilar, OpFuzzy}
+	got := Describe(Optimize(f)).Children
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d operands, got %v", len(want), got)
+	}
+	for i, op := range want {
+		if got[i].Op != op {
+			t.Errorf("Expected operand %d to be %s, got %s", i, op, got[i].Op)
+		}
+	}
+}
+
+func TestInHashSet(t *testing.T) {
+	people := []Person{{Name: "Ana", Age: 17}, {Name: "Bia", Age: 25}}
+
+	// Values are converted to the field type, as with Eq
+	result := Apply(people, In[Person]("Age", []interface{}{int64(25), 17.0, "x", nil}))
+	if len(result) != 2 {
+		t.Errorf("Expected 2 matches, got %d", len(result))
+	}
+
+	if len(Apply(people, In[Person]("Name", []interface{}{}))) != 0 {
+		t.Error("Expected an empty In to match nothing")
+	}
+}
+
+func TestCost(t *testing.T) {
+	cheap := Cost(Describe(Eq[Person]("Age", 1)))
+	expensive := Cost(Describe(Fuzzy[Person]("Name", "ana", 1)))
+	if cheap >= expensive {
+		t.Errorf("Expected Eq (%d) to cost less than Fuzzy (%d)", cheap, expensive)
+	}
+
+	composite := Cost(Describe(And(Eq[Person]("Age", 1), Fuzzy[Person]("Name", "ana", 1))))
+	if composite != cheap+expensive {
+		t.Errorf("Expected And to cost the sum of its operands, got %d", composite)
+	}
+}
@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"runtime"
+	"sync"
+)
+
+// DefaultParallelThreshold is the smallest slice ApplyParallel splits across
+// goroutines unless Threshold says otherwise. Below it, starting goroutines
+// costs more than it saves for typical filters.
+const DefaultParallelThreshold = 10_000
+
+// ParallelOption configures ApplyParallel.
+type ParallelOption func(*parallelOptions)
+
+type parallelOptions struct {
+	workers   int
+	threshold int
+}
+
+// Workers sets the number of goroutines ApplyParallel uses. The default is
+// runtime.GOMAXPROCS(0); values below 2 filter sequentially.
+//
+// Example:
+//
+//	filter.ApplyParallel(items, f, filter.Workers(4))
+func Workers(n int) ParallelOption {
+	return func(o *parallelOptions) {
+		o.workers = n
+	}
+}
+
+// Threshold sets the smallest slice ApplyParallel splits across goroutines.
+// Smaller slices are filtered sequentially. The default is
+// DefaultParallelThreshold.
+//
+// Example:
+//
+//	filter.ApplyParallel(items, f, filter.Threshold(50_000))
+func Threshold(n int) ParallelOption {
+	return func(o *parallelOptions) {
+		o.threshold = n
+	}
+}
+
+// ApplyParallel filters a slice like Apply, splitting it into contiguous
+// chunks filtered by separate goroutines. The result keeps the original order.
+// Slices smaller than the threshold, or a single worker, fall back to Apply.
+//
+// The filter is called concurrently, so it must be safe for concurrent use.
+// All built-in filters are; Custom filters must not share unsynchronized state.
+// A panic in the filter is re-raised on the calling goroutine.
+//
+// Example:
+//
+//	adults := filter.ApplyParallel(users, filter.Gte[User]("Age", 18), filter.Workers(8))
+func ApplyParallel[T any](items []T, filter Filter[T], opts ...ParallelOption) []T {
+	o := parallelOptions{
+		workers:   runtime.GOMAXPROCS(0),
+		threshold: DefaultParallelThreshold,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return applyChunks(items, o, func(chunk []T) []T {
+		return Apply(chunk, filter)
+	})
+}
+
+// applyChunks runs fn over contiguous chunks of items, one goroutine per
+// worker, and concatenates the results in order. It calls fn once with all
+// the items when the options call for sequential filtering.
+func applyChunks[T any](items []T, o parallelOptions, fn func(chunk []T) []T) []T {
+	if o.workers < 2 || len(items) < o.threshold || len(items) < 2 {
+		return fn(items)
+	}
+	if o.workers > len(items) {
+		o.workers = len(items)
+	}
+
+	chunkSize := (len(items) + o.workers - 1) / o.workers
+	chunks := make([][]T, o.workers)
+	panics := make([]interface{}, o.workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < o.workers; w++ {
+		start := w * chunkSize
+		if start >= len(items) {
+			break
+		}
+		end := min(start+chunkSize, len(items))
+
+		wg.Add(1)
+		go func(w int, chunk []T) {
+			defer wg.Done()
+			defer func() {
+				panics[w] = recover()
+			}()
+			chunks[w] = fn(chunk)
+		}(w, items[start:end])
+	}
+	wg.Wait()
+
+	total := 0
+	for w, chunk := range chunks {
+		if panics[w] != nil {
+			panic(panics[w])
+		}
+		total += len(chunk)
+	}
+
+	result := make([]T, 0, total)
+	for _, chunk := range chunks {
+		result = append(result, chunk...)
+	}
+	return result
+}
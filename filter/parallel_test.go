@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func parallelItems(n int) []Person {
+	names := []string{"Ana", "Bia", "Caio", "Davi", "Eva"}
+	items := make([]Person, n)
+	for i := range items {
+		items[i] = Person{Name: names[i%len(names)], Age: i % 90}
+	}
+	return items
+}
+
+func TestApplyParallel(t *testing.T) {
+	items := parallelItems(10_007)
+	f := And(Gte[Person]("Age", 30), In[Person]("Name", []interface{}{"Ana", "Eva"}))
+	want := Apply(items, f)
+
+	for _, workers := range []int{0, 1, 2, 3, 8, 20_000} {
+		got := ApplyParallel(items, f, Workers(workers), Threshold(1))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Workers(%d): expected %d items in order, got %d", workers, len(want), len(got))
+		}
+	}
+
+	if got := ApplyParallel(items, f); !reflect.DeepEqual(got, want) {
+		t.Errorf("default options: expected %d items in order, got %d", len(want), len(got))
+	}
+	if got := ApplyParallel([]Person{}, f, Workers(4), Threshold(0)); len(got) != 0 {
+		t.Errorf("Expected no items, got %v", got)
+	}
+}
+
+func TestApplyParallelSequentialBelowThreshold(t *testing.T) {
+	items := parallelItems(100)
+
+	// A filter with unsynchronized state is only safe when run sequentially
+	var calls int
+	counting := Custom[Person](func(Person) bool {
+		calls++
+		return true
+	})
+
+	ApplyParallel(items, counting, Workers(4), Threshold(101))
+	if calls != len(items) {
+		t.Errorf("Expected %d calls, got %d", len(items), calls)
+	}
+}
+
+func TestApplyParallelPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected the filter panic to reach the caller, got %v", r)
+		}
+	}()
+
+	items := parallelItems(1000)
+	ApplyParallel(items, Custom[Person](func(p Person) bool {
+		if p.Age == 89 {
+			panic("boom")
+		}
+		return true
+	}), Workers(4), Threshold(1))
+}
+
+func benchmarkApply(b *testing.B, n int, parallel bool) {
+	items := parallelItems(n)
+	f := And(Gte[Person]("Age", 30), Eq[Person]("Name", "Ana"))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if parallel {
+			ApplyParallel(items, f)
+		} else {
+			Apply(items, f)
+		}
+	}
+}
+
+func BenchmarkApply_100K(b *testing.B)         { benchmarkApply(b, 100_000, false) }
+func BenchmarkApplyParallel_100K(b *testing.B) { benchmarkApply(b, 100_000, true) }
+func BenchmarkApply_1M(b *testing.B)           { benchmarkApply(b, 1_000_000, false) }
+func BenchmarkApplyParallel_1M(b *testing.B)   { benchmarkApply(b, 1_000_000, true) }
@@ -0,0 +1,534 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// stepKind is the kind of one step of a field path
+type stepKind int
+
+const (
+	// stepField reads a struct field or a map key by name, or a slice
+	// element when the name is a number
+	stepField stepKind = iota
+	// stepKey reads a quoted map key or struct field, such as ["color"]
+	stepKey
+	// stepIndex reads a slice or array element, such as [0]
+	stepIndex
+	// stepAny reads every element, matching when any of them matches ([*])
+	stepAny
+	// stepAll reads every element, matching when all of them match ([all])
+	stepAll
+)
+
+// pathStep is one step of a field path
+type pathStep struct {
+	kind  stepKind
+	name  string
+	index int
+}
+
+// fieldPath is a parsed field path:
+//
+//	Address.City        struct fields and map keys
+//	Items[0].Price      slice and array indexes
+//	Attrs["color"]      quoted map keys
+//	Orders[*].Status    any element (also [any])
+//	Orders[all].Status  every element
+//	len(Tags)           length of a slice, array, map or string
+type fieldPath struct {
+	steps    []pathStep
+	length   bool
+	wildcard bool
+}
+
+// maxCachedPaths bounds the field paths a path cache remembers
+const maxCachedPaths = 10_000
+
+// paths caches parsed field paths
+var paths = newPathCache[string](maxCachedPaths)
+
+// pathCache remembers field paths by key. Paths come from callers, such as
+// the field names of a query string or a JSON filter, so the cache holds at
+// most limit paths: storing one more starts it over. Lookups take no lock.
+type pathCache[K comparable] struct {
+	limit   int64
+	entries atomic.Pointer[sync.Map] // K -> *fieldPath
+	size    atomic.Int64
+}
+
+// newPathCache returns an empty cache holding at most limit paths
+func newPathCache[K comparable](limit int64) *pathCache[K] {
+	c := &pathCache[K]{limit: limit}
+	c.entries.Store(&sync.Map{})
+	return c
+}
+
+// load returns the path stored for key
+func (c *pathCache[K]) load(key K) (*fieldPath, bool) {
+	p, ok := c.entries.Load().Load(key)
+	if !ok {
+		return nil, false
+	}
+	return p.(*fieldPath), true
+}
+
+// store remembers the path for key, first emptying the cache when it is full
+func (c *pathCache[K]) store(key K, p *fieldPath) {
+	if c.size.Add(1) > c.limit {
+		c.entries.Store(&sync.Map{})
+		c.size.Store(1)
+	}
+	c.entries.Load().Store(key, p)
+}
+
+// lookupPath returns the parsed form of a field path on items of type t, with
+// its struct fields named the way t names them (see UseJSONNames)
+func lookupPath(t reflect.Type, path string) (*fieldPath, error) {
+	p, err := parsedPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.named(t, path), nil
+}
+
+// parsedPath returns the parsed form of a field path, parsing it once
+func parsedPath(path string) (*fieldPath, error) {
+	if p, ok := paths.load(path); ok {
+		return p, nil
+	}
+
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	paths.store(path, p)
+	return p, nil
+}
+
+// parsePath parses a field path
+func parsePath(path string) (*fieldPath, error) {
+	p := &fieldPath{}
+
+	s := path
+	if strings.HasPrefix(s, "len(") {
+		if !strings.HasSuffix(s, ")") {
+			return nil, &ErrInvalidPath{Path: path, Reason: "len( is not closed"}
+		}
+		p.length = true
+		s = s[len("len(") : len(s)-1]
+	}
+	if s == "" {
+		return nil, &ErrInvalidPath{Path: path, Reason: "empty path"}
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '[':
+			step, n, err := parseBracket(s[i:])
+			if err != nil {
+				return nil, &ErrInvalidPath{Path: path, Reason: err.Error()}
+			}
+			if step.kind == stepAny || step.kind == stepAll {
+				p.wildcard = true
+			}
+			p.steps = append(p.steps, step)
+			i += n
+
+		case s[i] == '.' && i > 0 || i == 0:
+			if i > 0 {
+				i++
+			}
+			end := i
+			for end < len(s) && s[end] != '.' && s[end] != '[' {
+				end++
+			}
+			if end == i {
+				return nil, &ErrInvalidPath{Path: path, Reason: fmt.Sprintf("empty field name at offset %d", i)}
+			}
+			p.steps = append(p.steps, pathStep{kind: stepField, name: s[i:end]})
+			i = end
+
+		default:
+			return nil, &ErrInvalidPath{Path: path, Reason: fmt.Sprintf("unexpected %q at offset %d", s[i], i)}
+		}
+	}
+
+	return p, nil
+}
+
+// parseBracket parses the bracket step at the start of s and returns it with
+// its length
+func parseBracket(s string) (pathStep, int, error) {
+	if len(s) > 1 && (s[1] == '"' || s[1] == '\'') {
+		quote := s[1]
+		end := 2
+		for end < len(s) && s[end] != quote {
+			if s[end] == '\\' && quote == '"' {
+				end++
+			}
+			end++
+		}
+		if end+1 >= len(s) || s[end+1] != ']' {
+			return pathStep{}, 0, fmt.Errorf("unterminated key %s", s)
+		}
+
+		key := s[2:end]
+		if quote == '"' {
+			unquoted, err := strconv.Unquote(s[1 : end+1])
+			if err != nil {
+				return pathStep{}, 0, fmt.Errorf("invalid key %s", s[1:end+1])
+			}
+			key = unquoted
+		}
+		return pathStep{kind: stepKey, name: key}, end + 2, nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return pathStep{}, 0, fmt.Errorf("unterminated bracket %s", s)
+	}
+
+	switch inner := s[1:end]; inner {
+	case "*", "any":
+		return pathStep{kind: stepAny}, end + 1, nil
+	case "all":
+		return pathStep{kind: stepAll}, end + 1, nil
+	default:
+		index, err := strconv.Atoi(inner)
+		if err != nil || index < 0 {
+			return pathStep{}, 0, fmt.Errorf("invalid index [%s]", inner)
+		}
+		return pathStep{kind: stepIndex, index: index}, end + 1, nil
+	}
+}
+
+// root returns the value a path is read from, which must be a struct or a map
+func root(item interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(item)
+
+	// Handle pointers
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, fmt.Errorf("nil pointer")
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct, reflect.Map:
+		return value, nil
+	}
+	return reflect.Value{}, fmt.Errorf("item is not a struct or map")
+}
+
+// value reads the path from an item. Paths with a wildcard read several
+// values, so they return an error; use match instead.
+func (p *fieldPath) value(item interface{}) (reflect.Value, error) {
+	if p.wildcard {
+		return reflect.Value{}, fmt.Errorf("path has a wildcard")
+	}
+
+	value, err := root(item)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	for _, step := range p.steps {
+		if value, err = step.follow(value); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return p.result(value)
+}
+
+// match reports whether match accepts the value of the path in an item. For
+// each wildcard, match is called with every element it reaches.
+func (p *fieldPath) match(item interface{}, match func(fieldValue reflect.Value) bool) bool {
+	value, err := root(item)
+	if err != nil {
+		return false
+	}
+	return p.matchFrom(value, 0, match)
+}
+
+// matchFrom matches the steps of the path from the i-th one on
+func (p *fieldPath) matchFrom(value reflect.Value, i int, match func(fieldValue reflect.Value) bool) bool {
+	for ; i < len(p.steps); i++ {
+		step := p.steps[i]
+		if step.kind != stepAny && step.kind != stepAll {
+			var err error
+			if value, err = step.follow(value); err != nil {
+				return false
+			}
+			continue
+		}
+
+		elements := elementsOf(value)
+		if len(elements) == 0 {
+			return false
+		}
+		for _, element := range elements {
+			element, err := settle(element, "")
+			matched := err == nil && p.matchFrom(element, i+1, match)
+			if step.kind == stepAny && matched {
+				return true
+			}
+			if step.kind == stepAll && !matched {
+				return false
+			}
+		}
+		return step.kind == stepAll
+	}
+
+	value, err := p.result(value)
+	if err != nil {
+		return false
+	}
+	return match(value)
+}
+
+// result returns the value read at the end of the path, or its length for
+// len() paths
+func (p *fieldPath) result(value reflect.Value) (reflect.Value, error) {
+	if !p.length {
+		return value, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String, reflect.Chan:
+		return reflect.ValueOf(value.Len()), nil
+	}
+	return reflect.Value{}, fmt.Errorf("len of %s", value.Kind())
+}
+
+// follow reads one step of a path from value
+func (s pathStep) follow(value reflect.Value) (reflect.Value, error) {
+	switch value.Kind() {
+	case reflect.Struct:
+		if s.kind == stepIndex {
+			return reflect.Value{}, fmt.Errorf("cannot index %s", value.Type())
+		}
+		value = value.FieldByName(s.name)
+
+	case reflect.Map:
+		key, err := s.mapKey(value.Type().Key())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value = value.MapIndex(key)
+
+	case reflect.Slice, reflect.Array:
+		index := s.index
+		if s.kind != stepIndex {
+			var err error
+			if index, err = strconv.Atoi(s.name); err != nil || index < 0 {
+				return reflect.Value{}, fmt.Errorf("%s is not an index", s.name)
+			}
+		}
+		if index >= value.Len() {
+			return reflect.Value{}, fmt.Errorf("index %d out of range", index)
+		}
+		value = value.Index(index)
+
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot read %s from %s", s, value.Kind())
+	}
+
+	if !value.IsValid() {
+		return reflect.Value{}, fmt.Errorf("field %s not found", s)
+	}
+	return settle(value, s.String())
+}
+
+// mapKey returns the key of a map with keys of type t read by the step
+func (s pathStep) mapKey(t reflect.Type) (reflect.Value, error) {
+	switch {
+	case s.kind == stepIndex && isInt(t.Kind()):
+		return reflect.ValueOf(s.index).Convert(t), nil
+	case s.kind != stepIndex && t.Kind() == reflect.String:
+		return reflect.ValueOf(s.name).Convert(t), nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot look up %s in a map with %s keys", s, t)
+}
+
+// String returns the step as written in a path
+func (s pathStep) String() string {
+	switch s.kind {
+	case stepKey:
+		return strconv.Quote(s.name)
+	case stepIndex:
+		return strconv.Itoa(s.index)
+	case stepAny:
+		return "*"
+	case stepAll:
+		return "all"
+	}
+	return s.name
+}
+
+// settle unwraps the interface and pointer holding a value, which is how
+// decoded JSON stores every value and how optional structs are referenced.
+// A nil interface is returned as is so that IsNil can match JSON nulls.
+func settle(value reflect.Value, field string) (reflect.Value, error) {
+	if value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	// Handle pointer to struct for nested fields
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, fmt.Errorf("nil pointer for field %s", field)
+		}
+		value = value.Elem()
+	}
+	return value, nil
+}
+
+// elementsOf returns the elements of a slice or array, or the values of a map
+func elementsOf(value reflect.Value) []reflect.Value {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		elements := make([]reflect.Value, value.Len())
+		for i := range elements {
+			elements[i] = value.Index(i)
+		}
+		return elements
+	case reflect.Map:
+		elements := make([]reflect.Value, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			elements = append(elements, iter.Value())
+		}
+		return elements
+	}
+	return nil
+}
+
+// matchField returns a match function that reads a field of each item and
+// calls match with its value. Items whose field cannot be read do not match.
+// When the field path has a wildcard, match is called with each element it
+// reaches, and the item matches when any element matches ([*]) or when every
+// element does ([all]).
+func matchField[T any](fieldName string, match func(fieldValue reflect.Value) bool) func(item T) bool {
+	parsed, err := parsedPath(fieldName)
+	if err != nil {
+		return func(T) bool { return false }
+	}
+
+	// Field names are resolved again whenever UseJSONNames is called, so that
+	// it also applies to filters built before it. With an interface type, the
+	// names depend on the dynamic type of each item.
+	itemType := reflect.TypeFor[T]()
+	var resolved atomic.Pointer[namedPath]
+	pathFor := func(item T) *fieldPath {
+		generation := jsonNaming.Load()
+		if generation == 0 {
+			return parsed
+		}
+		if itemType.Kind() == reflect.Interface {
+			return parsed.named(reflect.TypeOf(item), fieldName)
+		}
+		if r := resolved.Load(); r != nil && r.generation == generation {
+			return r.path
+		}
+		path := parsed.named(itemType, fieldName)
+		resolved.Store(&namedPath{generation: generation, path: path})
+		return path
+	}
+
+	return func(item T) bool {
+		path := pathFor(item)
+
+		if path.wildcard {
+			return path.match(item, match)
+		}
+		fieldValue, err := path.value(item)
+		if err != nil {
+			return false
+		}
+		return match(fieldValue)
+	}
+}
+
+// namedPath is a field path resolved for a concrete item type while
+// jsonNaming was generation
+type namedPath struct {
+	generation int64
+	path       *fieldPath
+}
+
+// hasWildcard reports whether a field path reads several values
+func hasWildcard(fieldName string) bool {
+	path, err := parsedPath(fieldName)
+	return err == nil && path.wildcard
+}
+
+// fieldType returns the type of the values a field path reads from items of
+// type t, following the steps the way they are followed on values. The rest
+// of a path below an interface type cannot be known before evaluation, so it
+// has the interface type.
+func fieldType(t reflect.Type, fieldPath string) (reflect.Type, error) {
+	path, err := lookupPath(t, fieldPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+		return nil, fmt.Errorf("type %s is not a struct or map", t)
+	}
+
+	for _, step := range path.steps {
+		if t.Kind() == reflect.Interface {
+			break
+		}
+
+		switch {
+		case t.Kind() == reflect.Struct && step.kind != stepIndex && step.kind != stepAny && step.kind != stepAll:
+			structField, ok := t.FieldByName(step.name)
+			if !ok {
+				return nil, fmt.Errorf("field %s not found", step.name)
+			}
+			t = structField.Type
+
+		case t.Kind() == reflect.Map:
+			if step.kind != stepAny && step.kind != stepAll {
+				if _, err := step.mapKey(t.Key()); err != nil {
+					return nil, err
+				}
+			}
+			t = t.Elem()
+
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+			if step.kind == stepField || step.kind == stepKey {
+				if index, err := strconv.Atoi(step.name); err != nil || index < 0 {
+					return nil, fmt.Errorf("%s is not an index", step.name)
+				}
+			}
+			t = t.Elem()
+
+		default:
+			return nil, fmt.Errorf("cannot read %s from %s", step, t)
+		}
+
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+
+	if path.length {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.String, reflect.Chan, reflect.Interface:
+			return reflect.TypeOf(0), nil
+		}
+		return nil, fmt.Errorf("len of %s", t)
+	}
+	return t, nil
+}
@@ -0,0 +1,195 @@
+package filter
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type OrderLine struct {
+	SKU   string
+	Price float64
+}
+
+type Order struct {
+	Status string
+	Lines  []OrderLine
+}
+
+type Customer struct {
+	Name   string
+	Tags   []string
+	Attrs  map[string]string
+	Orders []*Order
+}
+
+func testCustomers() []Customer {
+	return []Customer{
+		{
+			Name:  "Alice",
+			Tags:  []string{"vip", "early"},
+			Attrs: map[string]string{"color": "red", "shoe size": "38"},
+			Orders: []*Order{
+				{Status: "shipped", Lines: []OrderLine{{SKU: "A1", Price: 10}, {SKU: "B2", Price: 25}}},
+				{Status: "shipped", Lines: []OrderLine{{SKU: "C3", Price: 5}}},
+			},
+		},
+		{
+			Name:  "Bob",
+			Tags:  []string{"new"},
+			Attrs: map[string]string{"color": "blue"},
+			Orders: []*Order{
+				{Status: "pending", Lines: []OrderLine{{SKU: "A1", Price: 12}}},
+				{Status: "shipped", Lines: []OrderLine{{SKU: "D4", Price: 40}}},
+			},
+		},
+		{
+			Name:  "Carol",
+			Attrs: map[string]string{},
+		},
+	}
+}
+
+func customerNames(customers []Customer) []string {
+	names := make([]string, len(customers))
+	for i, c := range customers {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func TestFieldPaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter[Customer]
+		want   []string
+	}{
+		{"index", Eq[Customer]("Tags[0]", "vip"), []string{"Alice"}},
+		{"index out of range", Eq[Customer]("Tags[1]", "early"), []string{"Alice"}},
+		{"nested index", Gt[Customer]("Orders[0].Lines[1].Price", 20), []string{"Alice"}},
+		{"map key", Eq[Customer](`Attrs["color"]`, "blue"), []string{"Bob"}},
+		{"quoted key with space", Eq[Customer](`Attrs['shoe size']`, "38"), []string{"Alice"}},
+		{"any", Eq[Customer]("Orders[*].Status", "pending"), []string{"Bob"}},
+		{"any keyword", Eq[Customer]("Orders[any].Status", "pending"), []string{"Bob"}},
+		{"all", Eq[Customer]("Orders[all].Status", "shipped"), []string{"Alice"}},
+		{"nested wildcards", Eq[Customer]("Orders[*].Lines[*].SKU", "D4"), []string{"Bob"}},
+		{"all then any", Lt[Customer]("Orders[all].Lines[*].Price", 11), []string{"Alice"}},
+		{"wildcard over map values", Eq[Customer]("Attrs[*]", "red"), []string{"Alice"}},
+		{"wildcard with string operator", StringMatch[Customer]("Tags[*]", "ea", StringMatchOptions{Mode: PrefixMatch}), []string{"Alice"}},
+		{"wildcard between", Between[Customer]("Orders[*].Lines[*].Price", 11, 20), []string{"Bob"}},
+		{"not any", Not(Eq[Customer]("Orders[*].Status", "pending")), []string{"Alice", "Carol"}},
+		{"len of slice", Gte[Customer]("len(Orders)", 2), []string{"Alice", "Bob"}},
+		{"len of map", Eq[Customer]("len(Attrs)", 0), []string{"Carol"}},
+		{"len under wildcard", Eq[Customer]("len(Orders[*].Lines)", 2), []string{"Alice"}},
+		{"invalid path", Eq[Customer]("Tags[0", "vip"), []string{}},
+	}
+
+	for _, tt := range tests {
+		got := customerNames(Apply(testCustomers(), tt.filter))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestFieldPathSort(t *testing.T) {
+	sorted := customerNames(SortBy(testCustomers(), Desc[Customer]("len(Tags)"), Asc[Customer]("Name")))
+	if sorted[0] != "Alice" || sorted[1] != "Bob" || sorted[2] != "Carol" {
+		t.Errorf("expected customers by number of tags, got %v", sorted)
+	}
+
+	sorted = customerNames(Sort(testCustomers()[:2], "Orders[0].Lines[0].Price", false))
+	if sorted[0] != "Bob" {
+		t.Errorf("expected Bob's first line to be the most expensive, got %v", sorted)
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	for _, path := range []string{"", "Tags[0", "Tags[x]", "Tags[-1]", `Attrs["color]`, "Orders..Status", ".Name", "len(Tags", "Tags[0]Name"} {
+		if _, err := parsePath(path); err == nil {
+			t.Errorf("expected %q to be invalid", path)
+		}
+	}
+
+	err := Validate(Eq[Customer]("Tags[x]", "vip"))
+	var invalid *ErrInvalidPath
+	if !errors.As(err, &invalid) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
+
+func TestPathCacheBounded(t *testing.T) {
+	cache := newPathCache[string](3)
+	for i := 0; i < 10; i++ {
+		cache.store(strconv.Itoa(i), &fieldPath{})
+		if size := cachedPaths(cache); size > 3 {
+			t.Fatalf("expected at most 3 cached paths, got %d", size)
+		}
+	}
+	if _, ok := cache.load("9"); !ok {
+		t.Error("expected the last stored path to be cached")
+	}
+
+	// Unknown field names from a caller, such as query string keys, are
+	// parsed and matched without growing the global cache past its bound
+	people := []Person{{Name: "Ana"}}
+	for i := 0; i < maxCachedPaths+100; i++ {
+		Apply(people, Eq[Person]("field"+strconv.Itoa(i), 1))
+	}
+	if size := cachedPaths(paths); size > maxCachedPaths {
+		t.Errorf("expected at most %d cached paths, got %d", maxCachedPaths, size)
+	}
+}
+
+// cachedPaths counts the paths in a cache
+func cachedPaths[K comparable](c *pathCache[K]) int {
+	size := 0
+	c.entries.Load().Range(func(_, _ interface{}) bool {
+		size++
+		return true
+	})
+	return size
+}
+
+func TestValidateFieldPaths(t *testing.T) {
+	valid := And(
+		Eq[Customer]("Orders[*].Lines[0].SKU", "A1"),
+		Eq[Customer](`Attrs["color"]`, "red"),
+		Gt[Customer]("len(Tags)", 1),
+		Contains[Customer]("Orders[all].Status", "ship"),
+	)
+	if err := Validate(valid); err != nil {
+		t.Errorf("expected paths to validate, got %v", err)
+	}
+
+	for _, f := range []Filter[Customer]{
+		Eq[Customer]("Orders[*].Total", 1),
+		Eq[Customer]("Orders[*].Status", 1),
+		Gt[Customer]("len(Name)", "x"),
+		Eq[Customer]("Name[0]", "A"),
+	} {
+		if err := Validate(f); err == nil {
+			t.Errorf("expected %s to be invalid", Describe(f))
+		}
+	}
+}
+
+func TestOptimizeKeepsWildcardBounds(t *testing.T) {
+	f := Optimize(And(
+		Gt[Customer]("Orders[*].Lines[*].Price", 20),
+		Lt[Customer]("Orders[*].Lines[*].Price", 11),
+	))
+
+	// Alice has a line over 20 and another under 11
+	got := customerNames(Apply(testCustomers(), f))
+	if len(got) != 1 || got[0] != "Alice" {
+		t.Errorf("expected Alice, got %v", got)
+	}
+}
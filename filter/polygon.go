@@ -0,0 +1,309 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Polygon is a geographic area bounded by an outer ring of points, with
+// optional holes cut out of it. Rings follow GeoJSON (RFC 7946): edges are
+// straight lines in latitude and longitude, the last point may repeat the
+// first, and an outer ring winds counterclockwise while holes wind clockwise.
+type Polygon struct {
+	Outer []Point   // Boundary of the area
+	Holes [][]Point // Areas inside Outer that are not part of the polygon
+}
+
+// MultiPolygon is an area made of several polygons, such as a delivery zone
+// split by a river. A point is inside it when it is inside any polygon.
+type MultiPolygon []Polygon
+
+// WithinPolygon returns a filter that checks if a location is inside a polygon
+// or on its boundary. Locations inside a hole are outside the polygon.
+//
+// Edges take the shorter way around the globe, so polygons may cross the
+// antimeridian. A ring that circles a pole encloses the pole on its left,
+// as the GeoJSON winding order implies: an outer ring drawn eastward
+// encloses the North Pole, and one drawn westward the South Pole.
+//
+// Example:
+//
+//	zone := filter.Polygon{Outer: []filter.Point{
+//	    {Lat: -23.50, Lng: -46.70}, {Lat: -23.60, Lng: -46.70},
+//	    {Lat: -23.60, Lng: -46.60}, {Lat: -23.50, Lng: -46.60},
+//	}}
+//	filter.WithinPolygon[Place]("Lat", "Lng", zone)
+func WithinPolygon[T any](latField, lngField string, polygon Polygon) Filter[T] {
+	return WithinMultiPolygon[T](latField, lngField, MultiPolygon{polygon})
+}
+
+// WithinMultiPolygon returns a filter that checks if a location is inside any
+// of several polygons, as WithinPolygon does for one. Load polygons from
+// GeoJSON with ParseGeoJSON.
+//
+// Example:
+//
+//	zones, err := filter.ParseGeoJSON(data)
+//	filter.WithinMultiPolygon[Order]("Lat", "Lng", zones[0].Area)
+func WithinMultiPolygon[T any](latField, lngField string, polygons MultiPolygon) Filter[T] {
+	area := newArea(polygons)
+	return newNode(Expr{Field: latField + "," + lngField, Op: OpWithinPolygon, Value: polygons}, func(item T) bool {
+		point, ok := pointOf(item, latField, lngField)
+		return ok && area.contains(point)
+	})
+}
+
+// pointOf reads the location of an item from its latitude and longitude fields
+func pointOf(item interface{}, latField, lngField string) (Point, bool) {
+	lat, ok := coordinateOf(item, latField)
+	if !ok {
+		return Point{}, false
+	}
+	lng, ok := coordinateOf(item, lngField)
+	if !ok {
+		return Point{}, false
+	}
+	return Point{Lat: lat, Lng: lng}, true
+}
+
+// coordinateOf reads a numeric field as a coordinate in degrees
+func coordinateOf(item interface{}, field string) (float64, bool) {
+	v, err := getFieldValue(item, field)
+	if err != nil {
+		return 0, false
+	}
+	v = unwrap(v)
+	switch {
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float(), true
+	case isInt(v.Kind()):
+		return float64(v.Int()), true
+	case isUint(v.Kind()):
+		return float64(v.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+// checkRing reports why points cannot bound an area, or nil
+func checkRing(points []Point) error {
+	distinct := 0
+	for i, p := range points {
+		if math.IsNaN(p.Lat) || math.IsNaN(p.Lng) || math.IsInf(p.Lat, 0) || math.IsInf(p.Lng, 0) {
+			return fmt.Errorf("point %d is not a number", i)
+		}
+		if p.Lat < -90 || p.Lat > 90 {
+			return fmt.Errorf("latitude %v of point %d is out of range", p.Lat, i)
+		}
+		if i == 0 || p != points[i-1] {
+			distinct++
+		}
+	}
+	if len(points) > 1 && points[0] == points[len(points)-1] {
+		distinct--
+	}
+	if distinct < 3 {
+		return fmt.Errorf("a ring needs at least 3 distinct points, got %d", distinct)
+	}
+	return nil
+}
+
+// checkPolygons reports why polygons cannot bound an area, or nil
+func checkPolygons(polygons MultiPolygon) error {
+	if len(polygons) == 0 {
+		return fmt.Errorf("expected at least one polygon")
+	}
+	for i, polygon := range polygons {
+		if err := checkRing(polygon.Outer); err != nil {
+			return fmt.Errorf("polygon %d: outer ring: %v", i, err)
+		}
+		for j, hole := range polygon.Holes {
+			if err := checkRing(hole); err != nil {
+				return fmt.Errorf("polygon %d: hole %d: %v", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// area is a MultiPolygon prepared for point-in-polygon tests
+type area []preparedPolygon
+
+// preparedPolygon is a Polygon prepared for point-in-polygon tests
+type preparedPolygon struct {
+	outer ring
+	holes []ring
+}
+
+// newArea prepares the rings of polygons
+func newArea(polygons MultiPolygon) area {
+	a := make(area, len(polygons))
+	for i, polygon := range polygons {
+		a[i].outer = newRing(polygon.Outer, false)
+		a[i].holes = make([]ring, len(polygon.Holes))
+		for j, hole := range polygon.Holes {
+			a[i].holes[j] = newRing(hole, true)
+		}
+	}
+	return a
+}
+
+// contains reports whether p is inside or on the boundary of any polygon
+func (a area) contains(p Point) bool {
+	p.Lng = math.Remainder(p.Lng, 360)
+	for _, polygon := range a {
+		if polygon.contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether p is inside the outer ring and not inside a hole.
+// The boundary of a hole is part of the polygon.
+func (polygon preparedPolygon) contains(p Point) bool {
+	if polygon.outer.locate(p) == ringOutside {
+		return false
+	}
+	for _, hole := range polygon.holes {
+		if hole.locate(p) == ringInside {
+			return false
+		}
+	}
+	return true
+}
+
+// Positions of a point relative to a ring
+const (
+	ringOutside = iota
+	ringBoundary
+	ringInside
+)
+
+// ring is a closed ring of points whose longitudes are unwrapped, so each
+// edge spans less than 180 degrees of longitude and the ring can be tested
+// in plain latitude and longitude. A ring around a pole is closed through
+// the pole along two seams, which are not part of its boundary.
+type ring struct {
+	points []Point
+	// seams is the index of the first edge that closes the ring through a
+	// pole, or len(points) when the ring does not circle a pole
+	seams int
+	// pole is the latitude of the pole the ring encloses, or 0
+	pole float64
+
+	minLat, maxLat, minLng, maxLng float64
+}
+
+// newRing unwraps and closes points. hole selects which side of a ring
+// around a pole is enclosed: the left for outer rings, the right for holes.
+func newRing(points []Point, hole bool) ring {
+	if checkRing(points) != nil {
+		return ring{}
+	}
+
+	first := Point{Lat: points[0].Lat, Lng: math.Remainder(points[0].Lng, 360)}
+	r := ring{points: make([]Point, 0, len(points)+4)}
+	r.points = append(r.points, first)
+	prev := first
+	for _, p := range points[1:] {
+		prev = Point{Lat: p.Lat, Lng: nearestLng(p.Lng, prev.Lng)}
+		r.points = append(r.points, prev)
+	}
+
+	// Closing the ring either returns to the first longitude or ends a whole
+	// turn away from it, when the ring circles a pole
+	end := nearestLng(first.Lng, prev.Lng)
+	r.seams = len(r.points)
+	if turn := end - first.Lng; math.Abs(turn) > 180 {
+		r.pole = 90
+		if (turn < 0) != hole {
+			r.pole = -90
+		}
+		r.points = append(r.points, Point{Lat: first.Lat, Lng: end})
+		r.seams = len(r.points) - 1
+		r.points = append(r.points, Point{Lat: r.pole, Lng: end}, Point{Lat: r.pole, Lng: first.Lng})
+	}
+	r.points = append(r.points, first)
+
+	r.minLat, r.maxLat = math.Inf(1), math.Inf(-1)
+	r.minLng, r.maxLng = math.Inf(1), math.Inf(-1)
+	for _, p := range r.points {
+		r.minLat, r.maxLat = math.Min(r.minLat, p.Lat), math.Max(r.maxLat, p.Lat)
+		r.minLng, r.maxLng = math.Min(r.minLng, p.Lng), math.Max(r.maxLng, p.Lng)
+	}
+	return r
+}
+
+// nearestLng returns lng shifted by whole turns to within 180 degrees of ref
+func nearestLng(lng, ref float64) float64 {
+	return ref + math.Remainder(lng-ref, 360)
+}
+
+// locate returns the position of p, whose longitude is in [-180, 180],
+// relative to the ring. The ring may lie up to a turn away, so p is also
+// tried a turn to either side.
+func (r ring) locate(p Point) int {
+	if len(r.points) == 0 {
+		return ringOutside
+	}
+	if r.pole != 0 && p.Lat == r.pole {
+		return ringInside
+	}
+
+	result := ringOutside
+	for _, shift := range [...]float64{0, 360, -360} {
+		q := Point{Lat: p.Lat, Lng: p.Lng + shift}
+		if q.Lat < r.minLat || q.Lat > r.maxLat || q.Lng < r.minLng || q.Lng > r.maxLng {
+			continue
+		}
+		switch r.locateUnwrapped(q) {
+		case ringBoundary:
+			return ringBoundary
+		case ringInside:
+			result = ringInside
+		}
+	}
+	return result
+}
+
+// locateUnwrapped casts a ray from p towards growing longitudes and counts
+// the edges it crosses: an odd count means p is inside
+func (r ring) locateUnwrapped(p Point) int {
+	inside := false
+	for i := 0; i+1 < len(r.points); i++ {
+		a, b := r.points[i], r.points[i+1]
+		if i < r.seams && onSegment(p, a, b) {
+			return ringBoundary
+		}
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) {
+			lng := a.Lng + (p.Lat-a.Lat)*(b.Lng-a.Lng)/(b.Lat-a.Lat)
+			if p.Lng < lng {
+				inside = !inside
+			}
+		}
+	}
+	if inside {
+		return ringInside
+	}
+	return ringOutside
+}
+
+// boundaryTolerance is how far from an edge, in degrees, a point still lies
+// on it: about 0.1 mm
+const boundaryTolerance = 1e-9
+
+// onSegment reports whether p lies on the edge from a to b
+func onSegment(p, a, b Point) bool {
+	if p.Lat < math.Min(a.Lat, b.Lat)-boundaryTolerance || p.Lat > math.Max(a.Lat, b.Lat)+boundaryTolerance ||
+		p.Lng < math.Min(a.Lng, b.Lng)-boundaryTolerance || p.Lng > math.Max(a.Lng, b.Lng)+boundaryTolerance {
+		return false
+	}
+	length := math.Hypot(b.Lng-a.Lng, b.Lat-a.Lat)
+	if length == 0 {
+		return true
+	}
+	cross := (b.Lng-a.Lng)*(p.Lat-a.Lat) - (b.Lat-a.Lat)*(p.Lng-a.Lng)
+	return math.Abs(cross)/length <= boundaryTolerance
+}
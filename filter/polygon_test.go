@@ -0,0 +1,149 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithinPolygon(t *testing.T) {
+	// A 10x10 degree square with a 2x2 hole in the middle
+	square := Polygon{
+		Outer: []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 10}, {Lat: 10, Lng: 10}, {Lat: 10, Lng: 0}},
+		Holes: [][]Point{{{Lat: 4, Lng: 4}, {Lat: 6, Lng: 4}, {Lat: 6, Lng: 6}, {Lat: 4, Lng: 6}, {Lat: 4, Lng: 4}}},
+	}
+	// An L shape, concave at (5, 5)
+	ell := Polygon{Outer: []Point{
+		{Lat: 0, Lng: 0}, {Lat: 0, Lng: 10}, {Lat: 5, Lng: 10}, {Lat: 5, Lng: 5}, {Lat: 10, Lng: 5}, {Lat: 10, Lng: 0}, {Lat: 0, Lng: 0},
+	}}
+
+	tests := []struct {
+		name    string
+		polygon Polygon
+		point   Point
+		want    bool
+	}{
+		{"inside", square, Point{Lat: 2, Lng: 2}, true},
+		{"outside", square, Point{Lat: 12, Lng: 2}, false},
+		{"on an edge", square, Point{Lat: 0, Lng: 5}, true},
+		{"on a vertex", square, Point{Lat: 10, Lng: 10}, true},
+		{"in the hole", square, Point{Lat: 5, Lng: 5}, false},
+		{"on the hole's edge", square, Point{Lat: 4, Lng: 5}, true},
+		{"level with a vertex", square, Point{Lat: 10, Lng: -5}, false},
+		{"in the concave part", ell, Point{Lat: 7, Lng: 7}, false},
+		{"in the other arm", ell, Point{Lat: 7, Lng: 2}, true},
+	}
+
+	for _, tt := range tests {
+		items := []Location{{Name: tt.name, Latitude: tt.point.Lat, Longitude: tt.point.Lng}}
+		got := len(Apply(items, WithinPolygon[Location]("Latitude", "Longitude", tt.polygon))) == 1
+		if got != tt.want {
+			t.Errorf("%s: expected %v for %+v, got %v", tt.name, tt.want, tt.point, got)
+		}
+	}
+}
+
+func TestWithinPolygonOnTheSphere(t *testing.T) {
+	// Fiji straddles the antimeridian
+	fiji := Polygon{Outer: []Point{{Lat: -15, Lng: 177}, {Lat: -20, Lng: 177}, {Lat: -20, Lng: -178}, {Lat: -15, Lng: -178}}}
+	// Everything north of 80°N, drawn eastward
+	arctic := Polygon{Outer: []Point{{Lat: 80, Lng: 0}, {Lat: 80, Lng: 90}, {Lat: 80, Lng: 180}, {Lat: 80, Lng: -90}}}
+	// The same ring drawn westward encloses the South Pole instead
+	rest := Polygon{Outer: []Point{{Lat: 80, Lng: -90}, {Lat: 80, Lng: 180}, {Lat: 80, Lng: 90}, {Lat: 80, Lng: 0}}}
+	// A band around the globe, from the Equator to 60°N: the northern
+	// hemisphere, drawn eastward, without the cap north of 60°N, drawn westward
+	band := Polygon{
+		Outer: []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 120}, {Lat: 0, Lng: -120}, {Lat: 0, Lng: 0}},
+		Holes: [][]Point{{{Lat: 60, Lng: 0}, {Lat: 60, Lng: -120}, {Lat: 60, Lng: 120}, {Lat: 60, Lng: 0}}},
+	}
+
+	tests := []struct {
+		name    string
+		polygon Polygon
+		point   Point
+		want    bool
+	}{
+		{"west of the antimeridian", fiji, Point{Lat: -17, Lng: 179}, true},
+		{"east of the antimeridian", fiji, Point{Lat: -17, Lng: -179}, true},
+		{"on the antimeridian", fiji, Point{Lat: -17, Lng: 180}, true},
+		{"longitude past 180", fiji, Point{Lat: -17, Lng: 181}, true},
+		{"far from Fiji", fiji, Point{Lat: -17, Lng: 0}, false},
+		{"just west of Fiji", fiji, Point{Lat: -17, Lng: 176}, false},
+		{"in the Arctic", arctic, Point{Lat: 85, Lng: 45}, true},
+		{"in the Arctic on the seam", arctic, Point{Lat: 85, Lng: 0}, true},
+		{"at the North Pole", arctic, Point{Lat: 90, Lng: 0}, true},
+		{"south of the Arctic", arctic, Point{Lat: 75, Lng: -135}, false},
+		{"Arctic drawn westward", rest, Point{Lat: 85, Lng: 45}, false},
+		{"Equator drawn westward", rest, Point{Lat: 0, Lng: 45}, true},
+		{"in the band", band, Point{Lat: 30, Lng: -170}, true},
+		{"in the band on the seam", band, Point{Lat: 30, Lng: 0}, true},
+		{"south of the band", band, Point{Lat: -30, Lng: 10}, false},
+		{"north of the band", band, Point{Lat: 70, Lng: 10}, false},
+		{"north of the band on the seam", band, Point{Lat: 70, Lng: 0}, false},
+		{"on the band's northern edge", band, Point{Lat: 60, Lng: 45}, true},
+	}
+
+	for _, tt := range tests {
+		items := []Location{{Name: tt.name, Latitude: tt.point.Lat, Longitude: tt.point.Lng}}
+		got := len(Apply(items, WithinPolygon[Location]("Latitude", "Longitude", tt.polygon))) == 1
+		if got != tt.want {
+			t.Errorf("%s: expected %v for %+v, got %v", tt.name, tt.want, tt.point, got)
+		}
+	}
+}
+
+func TestWithinMultiPolygon(t *testing.T) {
+	type Order struct {
+		ID  int
+		Lat int
+		Lng int
+	}
+	orders := []Order{{1, 1, 1}, {2, 21, 21}, {3, 10, 10}}
+	zones := MultiPolygon{
+		{Outer: []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 5}, {Lat: 5, Lng: 5}, {Lat: 5, Lng: 0}}},
+		{Outer: []Point{{Lat: 20, Lng: 20}, {Lat: 20, Lng: 25}, {Lat: 25, Lng: 25}, {Lat: 25, Lng: 20}}},
+	}
+
+	result := Apply(orders, WithinMultiPolygon[Order]("Lat", "Lng", zones))
+	if len(result) != 2 || result[0].ID != 1 || result[1].ID != 2 {
+		t.Errorf("expected orders 1 and 2, got %v", result)
+	}
+
+	docs := []map[string]interface{}{
+		{"lat": 1.5, "lng": 2.5},
+		{"lat": 1.5},
+		{"lat": "1.5", "lng": 2.5},
+	}
+	found := Apply(docs, WithinMultiPolygon[map[string]interface{}]("lat", "lng", zones))
+	if len(found) != 1 {
+		t.Errorf("expected 1 document with coordinates in a zone, got %v", found)
+	}
+
+	degenerate := Polygon{Outer: []Point{{Lat: 0, Lng: 0}, {Lat: 5, Lng: 5}, {Lat: 0, Lng: 0}}}
+	if result := Apply(orders, WithinPolygon[Order]("Lat", "Lng", degenerate)); len(result) != 0 {
+		t.Errorf("expected a degenerate polygon to match nothing, got %v", result)
+	}
+}
+
+func TestValidatePolygon(t *testing.T) {
+	valid := Polygon{Outer: []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 5}, {Lat: 5, Lng: 5}}}
+	if err := Validate(WithinPolygon[Location]("Latitude", "Longitude", valid)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	var invalid *ErrInvalidOperand
+	for _, polygon := range []Polygon{
+		{Outer: []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 5}}},
+		{Outer: []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 5}, {Lat: 95, Lng: 5}}},
+		{Outer: valid.Outer, Holes: [][]Point{{{Lat: 1, Lng: 1}}}},
+	} {
+		err := Validate(WithinPolygon[Location]("Latitude", "Longitude", polygon))
+		if !errors.As(err, &invalid) {
+			t.Errorf("%+v: expected ErrInvalidOperand, got %v", polygon, err)
+		}
+	}
+
+	var unknown *ErrUnknownField
+	if err := Validate(WithinPolygon[Location]("Lat", "Longitude", valid)); !errors.As(err, &unknown) {
+		t.Errorf("expected ErrUnknownField, got %v", err)
+	}
+}
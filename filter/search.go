@@ -0,0 +1,240 @@
+package filter
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// StopWords is the set of terms ignored by Tokenize, Search and Rank.
+// It holds common English and Portuguese words and may be replaced or
+// extended during program initialization.
+var StopWords = map[string]bool{
+	// English
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+	"it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "with": true,
+	// Portuguese
+	"com": true, "da": true, "das": true, "de": true, "do": true,
+	"dos": true, "e": true, "em": true, "na": true, "nas": true, "no": true,
+	"nos": true, "o": true, "os": true, "ou": true, "para": true, "por": true,
+	"um": true, "uma": true,
+}
+
+// foldTable maps precomposed Latin letters to their unaccented form
+var foldTable = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ł': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ŕ': "r", 'ř': "r",
+	'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss",
+	'ť': "t", 'ţ': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	'æ': "ae", 'œ': "oe", 'þ': "th",
+}
+
+// Fold lowercases a string and removes accents, so that "São Paulo" and
+// "sao paulo" fold to the same text. Combining marks are dropped as well,
+// which covers decomposed input.
+//
+// Example:
+//
+//	filter.Fold("Ação")  // "acao"
+func Fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		r = unicode.ToLower(r)
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if folded, ok := foldTable[r]; ok {
+			b.WriteString(folded)
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Tokenize splits text into folded search terms.
+// Text is folded with Fold, split on anything that is not a letter or digit,
+// and terms found in StopWords are dropped.
+//
+// Example:
+//
+//	filter.Tokenize("Café com Leite")  // []string{"cafe", "leite"}
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if !StopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+
+	return terms
+}
+
+// fieldTerms tokenizes the searchable content of the given fields.
+// String fields and slices or arrays of strings are searched; fields of any
+// other type are ignored.
+func fieldTerms(item interface{}, fields []string) []string {
+	var terms []string
+
+	for _, field := range fields {
+		fieldValue, err := getFieldValue(item, field)
+		if err != nil {
+			continue
+		}
+
+		switch fieldValue.Kind() {
+		case reflect.String:
+			terms = append(terms, Tokenize(fieldValue.String())...)
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < fieldValue.Len(); i++ {
+				if elem := unwrap(fieldValue.Index(i)); elem.Kind() == reflect.String {
+					terms = append(terms, Tokenize(elem.String())...)
+				}
+			}
+		}
+	}
+
+	return terms
+}
+
+// Search returns a filter that performs a full-text search across one or more
+// fields. Both the query and the field contents are tokenized with Tokenize,
+// and an item passes when every query term appears in at least one field.
+// A query made only of stop words matches every item.
+//
+// Example:
+//
+//	filter.Search[User]("sao paulo", "City", "Bio")  // matches City "São Paulo"
+func Search[T any](text string, fields ...string) Filter[T] {
+	queryTerms := Tokenize(text)
+
+	return newNode(Expr{Field: strings.Join(fields, ","), Op: OpSearch, Value: text}, func(item T) bool {
+		if len(queryTerms) == 0 {
+			return true
+		}
+
+		present := make(map[string]bool)
+		for _, term := range fieldTerms(item, fields) {
+			present[term] = true
+		}
+
+		for _, term := range queryTerms {
+			if !present[term] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Scored pairs an item with its search relevance score.
+type Scored[T any] struct {
+	// Item is the scored item
+	Item T
+	// Score is the relevance of the item; higher is more relevant
+	Score float64
+}
+
+// BM25 ranking parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Rank scores items against a full-text query using BM25 and returns them
+// ordered from most to least relevant. The given fields are treated as a
+// single document per item, and term statistics are computed over items.
+// Rank does not remove anything: items sharing no term with the query score 0.
+// Combine it with Search to keep only matching items.
+//
+// Example:
+//
+//	matches := filter.Apply(products, filter.Search[Product]("red shoes", "Name", "Tags"))
+//	ranked := filter.Rank(matches, "red shoes", "Name", "Tags")
+//	best := ranked[0].Item
+func Rank[T any](items []T, text string, fields ...string) []Scored[T] {
+	queryTerms := Tokenize(text)
+
+	docs := make([]map[string]int, len(items))
+	lengths := make([]int, len(items))
+	docFreq := make(map[string]int)
+	totalLength := 0
+
+	for i, item := range items {
+		terms := fieldTerms(item, fields)
+		freq := make(map[string]int, len(terms))
+		for _, term := range terms {
+			freq[term]++
+		}
+		for term := range freq {
+			docFreq[term]++
+		}
+
+		docs[i] = freq
+		lengths[i] = len(terms)
+		totalLength += len(terms)
+	}
+
+	avgLength := 0.0
+	if len(items) > 0 {
+		avgLength = float64(totalLength) / float64(len(items))
+	}
+
+	n := float64(len(items))
+	result := make([]Scored[T], len(items))
+
+	for i, item := range items {
+		score := 0.0
+		for _, term := range queryTerms {
+			tf := float64(docs[i][term])
+			if tf == 0 {
+				continue
+			}
+
+			df := float64(docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+			norm := 1.0
+			if avgLength > 0 {
+				norm = 1 - bm25B + bm25B*float64(lengths[i])/avgLength
+			}
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+
+		result[i] = Scored[T]{Item: item, Score: score}
+	}
+
+	slices.SortStableFunc(result, func(a, b Scored[T]) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return result
+}
@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Article struct {
+	Title string
+	Body  string
+	Tags  []string
+	Views int
+}
+
+func testArticles() []Article {
+	return []Article{
+		{Title: "Restaurantes em São Paulo", Body: "Os melhores cafés da cidade", Tags: []string{"food"}},
+		{Title: "Go generics", Body: "Type parameters in Go", Tags: []string{"go", "programming"}},
+		{Title: "Paulo's garden", Body: "Growing tomatoes", Tags: []string{"garden"}},
+		{Title: "Sao Paulo travel guide", Body: "Where to eat in Sao Paulo, and where to sleep in Sao Paulo", Tags: []string{"travel", "food"}},
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"São Paulo", "sao paulo"},
+		{"Ação", "acao"},
+		{"Crème Brûlée", "creme brulee"},
+		{"Straße", "strasse"},
+		{"São", "sao"}, // decomposed tilde
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.input); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Café com Leite, the BEST in São-Paulo!")
+	want := []string{"cafe", "leite", "best", "sao", "paulo"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	result := Apply(testArticles(), Search[Article]("sao paulo", "Title", "Body"))
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 articles about São Paulo, got %d", len(result))
+	}
+
+	// Slice fields are searched element by element
+	result = Apply(testArticles(), Search[Article]("FOOD", "Tags"))
+	if len(result) != 2 {
+		t.Errorf("Expected 2 articles tagged food, got %d", len(result))
+	}
+
+	// Every term must match
+	result = Apply(testArticles(), Search[Article]("paulo tomatoes", "Title", "Body"))
+	if len(result) != 1 || result[0].Title != "Paulo's garden" {
+		t.Errorf("Expected only Paulo's garden, got %v", result)
+	}
+
+	// Stop words alone match everything
+	result = Apply(testArticles(), Search[Article]("the of", "Title"))
+	if len(result) != 4 {
+		t.Errorf("Expected stop-word query to match all 4 articles, got %d", len(result))
+	}
+}
+
+func TestRank(t *testing.T) {
+	ranked := Rank(testArticles(), "sao paulo", "Title", "Body")
+	if len(ranked) != 4 {
+		t.Fatalf("Expected all 4 articles to be ranked, got %d", len(ranked))
+	}
+
+	if ranked[0].Item.Title != "Sao Paulo travel guide" {
+		t.Errorf("Expected the travel guide to rank first, got %q", ranked[0].Item.Title)
+	}
+	if ranked[1].Item.Title != "Restaurantes em São Paulo" {
+		t.Errorf("Expected the restaurant article to rank second, got %q", ranked[1].Item.Title)
+	}
+	if ranked[3].Score != 0 {
+		t.Errorf("Expected the unrelated article to score 0, got %f", ranked[3].Score)
+	}
+
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i].Score > ranked[i-1].Score {
+			t.Errorf("Expected scores in descending order, got %f after %f", ranked[i].Score, ranked[i-1].Score)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package filter
+
+import "iter"
+
+// Seq returns an iterator over the items of a slice that pass the filter.
+// Items are filtered as the iterator is consumed, so no slice is allocated
+// and a loop that breaks early skips the rest of the slice.
+//
+// Example:
+//
+//	for u := range filter.Seq(users, filter.Gte[User]("Age", 18)) {
+//	    if send(u) != nil {
+//	        break
+//	    }
+//	}
+func Seq[T any](items []T, filter Filter[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range items {
+			if filter.Apply(item) && !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq returns an iterator over the items of seq that pass the filter.
+// Use it to filter generators, database cursors or file readers lazily;
+// seq is only read as far as the returned iterator is consumed.
+//
+// Example:
+//
+//	active := filter.FilterSeq(readUsers(file), filter.Eq[User]("Active", true))
+//	for u := range active {
+//	    fmt.Println(u.Name)
+//	}
+func FilterSeq[T any](seq iter.Seq[T], filter Filter[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for item := range seq {
+			if filter.Apply(item) && !yield(item) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+//go:build go1.23
+
+package filter
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	items := parallelItems(100)
+	f := Eq[Person]("Name", "Ana")
+
+	if got := slices.Collect(Seq(items, f)); !reflect.DeepEqual(got, Apply(items, f)) {
+		t.Errorf("Expected Seq to yield the items of Apply, got %v", got)
+	}
+
+	// Breaking out of the loop stops filtering
+	calls := 0
+	counting := Custom[Person](func(p Person) bool {
+		calls++
+		return p.Name == "Ana"
+	})
+	for range Seq(items, counting) {
+		break
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call before breaking, got %d", calls)
+	}
+}
+
+func TestFilterSeq(t *testing.T) {
+	read := 0
+	source := func(yield func(Person) bool) {
+		for _, p := range parallelItems(1000) {
+			read++
+			if !yield(p) {
+				return
+			}
+		}
+	}
+
+	var names []string
+	for p := range FilterSeq(source, Gte[Person]("Age", 3)) {
+		names = append(names, p.Name)
+		if len(names) == 2 {
+			break
+		}
+	}
+
+	if !reflect.DeepEqual(names, []string{"Davi", "Eva"}) {
+		t.Errorf("Unexpected items %v", names)
+	}
+	if read != 5 {
+		t.Errorf("Expected the source to be read up to the second match, read %d items", read)
+	}
+}
@@ -0,0 +1,204 @@
+package filter
+
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
+// SortKey describes one level of a multi-field sort.
+// Build keys with Asc and Desc and pass them to SortBy or DistinctOn.
+type SortKey[T any] struct {
+	// Field is the field path the key sorts by
+	Field string
+	// Ascending is true for A-Z/0-9 order
+	Ascending bool
+
+	compare func(a, b T) int
+}
+
+// Asc returns a sort key that orders items by a field in ascending order.
+//
+// Example:
+//
+//	filter.SortBy(users, filter.Asc[User]("City"), filter.Desc[User]("Age"))
+func Asc[T any](fieldName string) SortKey[T] {
+	return SortKey[T]{Field: fieldName, Ascending: true, compare: accessorCompare[T](fieldName)}
+}
+
+// Desc returns a sort key that orders items by a field in descending order.
+//
+// Example:
+//
+//	filter.SortBy(users, filter.Desc[User]("Score"))
+func Desc[T any](fieldName string) SortKey[T] {
+	return SortKey[T]{Field: fieldName, Ascending: false, compare: accessorCompare[T](fieldName)}
+}
+
+// ByDate returns a copy of the key that orders items by the time their field
+// holds, reading string fields with the layouts and time zone of opts as date
+// filters do (see DateOptions). Items whose field holds no time sort after
+// the others, in either direction.
+//
+// Example:
+//
+//	european := filter.DateOptions{Layouts: []string{"02/01/2006"}}
+//	filter.SortBy(invoices, filter.Desc[Invoice]("IssuedOn").ByDate(european))
+func (k SortKey[T]) ByDate(opts ...DateOptions) SortKey[T] {
+	parser := dateOptions(opts).parser()
+	timeAt := func(item T) (time.Time, bool) {
+		v, err := getFieldValue(item, k.Field)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return parser.timeOf(unwrap(v))
+	}
+
+	ascending := k.Ascending
+	k.compare = func(a, b T) int {
+		ta, okA := timeAt(a)
+		tb, okB := timeAt(b)
+		switch {
+		case !okA || !okB:
+			// Compare undoes the direction, so missing times stay last
+			c := boolCompare(okB, okA)
+			if !ascending {
+				c = -c
+			}
+			return c
+		default:
+			return ta.Compare(tb)
+		}
+	}
+	return k
+}
+
+// boolCompare orders false before true
+func boolCompare(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// accessorCompare returns the registered comparison of a field of T, or nil
+// when the field is compared through reflection
+func accessorCompare[T any](fieldName string) func(a, b T) int {
+	if accessor, ok := accessorFor[T](fieldName); ok && accessor.CompareItems != nil {
+		return accessor.CompareItems
+	}
+	return nil
+}
+
+// Compare compares two items by this key and returns -1, 0 or +1.
+// Items whose field is missing or null sort last in either direction;
+// items whose fields cannot be compared are considered equal.
+func (k SortKey[T]) Compare(a, b T) int {
+	var c int
+	if k.compare != nil {
+		c = k.compare(a, b)
+	} else {
+		c = compareFields(a, b, k.Field, k.Ascending)
+	}
+
+	if k.Ascending {
+		return c
+	}
+	return -c
+}
+
+// SortBy returns a sorted copy of the slice ordered by one or more sort keys.
+// Later keys break ties left by earlier ones, and the sort is stable, so items
+// that compare equal on every key keep their original relative order.
+// The original slice is not modified.
+//
+// Example:
+//
+//	sorted := filter.SortBy(users, filter.Asc[User]("City"), filter.Desc[User]("Score"))
+func SortBy[T any](items []T, keys ...SortKey[T]) []T {
+	result := make([]T, len(items))
+	copy(result, items)
+
+	if len(keys) == 0 {
+		return result
+	}
+
+	slices.SortStableFunc(result, func(a, b T) int {
+		return compareByKeys(a, b, keys)
+	})
+
+	return result
+}
+
+// compareByKeys compares two items by each key in turn
+func compareByKeys[T any](a, b T, keys []SortKey[T]) int {
+	for _, key := range keys {
+		if c := key.Compare(a, b); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareFields compares the same field of two items and returns -1, 0 or +1.
+// Items whose field is missing or null sort after the others in the
+// direction of the key, so documents without a value stay last.
+func compareFields(a, b interface{}, fieldName string, ascending bool) int {
+	fieldValueA, okA := sortValue(a, fieldName)
+	fieldValueB, okB := sortValue(b, fieldName)
+	if !okA || !okB {
+		// Compare undoes the direction, so missing values stay last
+		c := boolCompare(okB, okA)
+		if !ascending {
+			c = -c
+		}
+		return c
+	}
+
+	c, err := compareOrder(fieldValueA, fieldValueB)
+	if err != nil {
+		return 0
+	}
+	return c
+}
+
+// sortValue returns the value of a field to sort by, or false when the field
+// is missing or null, as in a JSON document without the key or with null
+func sortValue(item interface{}, fieldName string) (reflect.Value, bool) {
+	v, err := getFieldValue(item, fieldName)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	v = unwrap(v)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return v, false
+	case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+		return v, !v.IsNil()
+	}
+	return v, true
+}
+
+// compareOrder compares two values and returns -1 if a < b, +1 if a > b and 0 otherwise
+func compareOrder(a, b reflect.Value) (int, error) {
+	less, err := compareValuesLess(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if less {
+		return -1, nil
+	}
+
+	greater, err := compareValuesLess(b, a)
+	if err != nil {
+		return 0, err
+	}
+	if greater {
+		return 1, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,106 @@
+package filter
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+)
+
+// TopK returns the first k items of the slice in the order SortBy would
+// return them, without sorting the rest. It keeps the best k items seen so
+// far in a heap, so it costs O(n log k) instead of O(n log n), which makes
+// the first page of a large sorted result much cheaper.
+// The original slice is not modified.
+//
+// Example:
+//
+//	top10 := filter.TopK(users, 10, filter.Desc[User]("Score"))
+func TopK[T any](items []T, k int, keys ...SortKey[T]) []T {
+	result, _ := topK(context.Background(), items, k, keys)
+	return result
+}
+
+// TopKContext is like TopK, but checks ctx every 1024 items and returns the
+// context's cause once it is canceled or its deadline passes.
+//
+// Example:
+//
+//	top10, err := filter.TopKContext(ctx, users, 10, filter.Desc[User]("Score"))
+func TopKContext[T any](ctx context.Context, items []T, k int, keys ...SortKey[T]) ([]T, error) {
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return topK(ctx, items, k, keys)
+}
+
+// topK selects the first k items by keys, breaking ties by position so the
+// result matches a stable sort
+func topK[T any](ctx context.Context, items []T, k int, keys []SortKey[T]) ([]T, error) {
+	if k <= 0 {
+		return []T{}, nil
+	}
+	if k >= len(items) {
+		return SortByContext(ctx, items, keys...)
+	}
+
+	h := &positionHeap[T]{items: items, keys: keys, positions: make([]int, k)}
+	for i := range h.positions {
+		h.positions[i] = i
+	}
+	heap.Init(h)
+
+	for i := k; i < len(items); i++ {
+		if i%contextCheckInterval == 0 && ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		// Positions only grow, so an item equal to the worst kept one loses
+		if compareByKeys(items[i], items[h.positions[0]], keys) < 0 {
+			h.positions[0] = i
+			heap.Fix(h, 0)
+		}
+	}
+
+	slices.SortFunc(h.positions, h.compare)
+
+	result := make([]T, k)
+	for i, pos := range h.positions {
+		result[i] = items[pos]
+	}
+	return result, nil
+}
+
+// positionHeap is a max-heap of item positions, ordered by sort keys and then
+// by position, so the worst item kept so far is at the root
+type positionHeap[T any] struct {
+	items     []T
+	keys      []SortKey[T]
+	positions []int
+}
+
+// compare orders two positions by their items, then by position
+func (h *positionHeap[T]) compare(a, b int) int {
+	if c := compareByKeys(h.items[a], h.items[b], h.keys); c != 0 {
+		return c
+	}
+	return a - b
+}
+
+func (h *positionHeap[T]) Len() int { return len(h.positions) }
+
+func (h *positionHeap[T]) Less(i, j int) bool {
+	return h.compare(h.positions[i], h.positions[j]) > 0
+}
+
+func (h *positionHeap[T]) Swap(i, j int) {
+	h.positions[i], h.positions[j] = h.positions[j], h.positions[i]
+}
+
+func (h *positionHeap[T]) Push(x interface{}) {
+	h.positions = append(h.positions, x.(int))
+}
+
+func (h *positionHeap[T]) Pop() interface{} {
+	last := h.positions[len(h.positions)-1]
+	h.positions = h.positions[:len(h.positions)-1]
+	return last
+}
@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	items := parallelItems(1000)
+	keys := []SortKey[Person]{Desc[Person]("Age"), Asc[Person]("Name")}
+	sorted := SortBy(items, keys...)
+
+	for _, k := range []int{1, 7, 90, 999, 1000} {
+		if got := TopK(items, k, keys...); !reflect.DeepEqual(got, sorted[:k]) {
+			t.Errorf("TopK(%d): expected the first %d items of SortBy", k, k)
+		}
+	}
+
+	// Ties keep their original order, like SortBy
+	byAge := SortBy(items, Asc[Person]("Age"))
+	if got := TopK(items, 25, Asc[Person]("Age")); !reflect.DeepEqual(got, byAge[:25]) {
+		t.Errorf("Expected ties in original order, got %v", got)
+	}
+
+	if got := TopK(items, 2000, keys...); !reflect.DeepEqual(got, sorted) {
+		t.Errorf("Expected every item when k exceeds the length")
+	}
+	if got := TopK(items, 0, keys...); len(got) != 0 {
+		t.Errorf("Expected no items for k = 0, got %d", len(got))
+	}
+	if got := TopK(items, 3); !reflect.DeepEqual(got, items[:3]) {
+		t.Errorf("Expected the first items without keys, got %v", got)
+	}
+}
+
+func TestTopKContext(t *testing.T) {
+	items := parallelItems(5000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := TopKContext(ctx, items, 10, Asc[Person]("Age")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	got, err := TopKContext(context.Background(), items, 10, Asc[Person]("Age"))
+	if err != nil || !reflect.DeepEqual(got, TopK(items, 10, Asc[Person]("Age"))) {
+		t.Errorf("Expected the same items as TopK (%v)", err)
+	}
+}
+
+func BenchmarkSortBy_100K(b *testing.B) {
+	items := parallelItems(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SortBy(items, Desc[Person]("Age"))
+	}
+}
+
+func BenchmarkTopK_100K(b *testing.B) {
+	items := parallelItems(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TopK(items, 10, Desc[Person]("Age"))
+	}
+}
@@ -0,0 +1,137 @@
+package filter
+
+import "cmp"
+
+// TypedField reads an ordered field through a getter function instead of a
+// field name, so field references are checked by the compiler and filters
+// built from it evaluate without reflection.
+// Create one with Field.
+type TypedField[T any, V cmp.Ordered] struct {
+	name string
+	get  func(T) V
+}
+
+// Field returns a typed accessor for a field of T. Its filters and sort keys
+// call get directly, so renaming the field is a compile error rather than a
+// filter that silently matches nothing.
+//
+// Example:
+//
+//	age := filter.Field(func(u User) int { return u.Age })
+//	adults := filter.Apply(users, age.Gte(18))
+//	sorted := filter.SortBy(users, age.Desc())
+func Field[T any, V cmp.Ordered](get func(T) V) TypedField[T, V] {
+	return TypedField[T, V]{get: get}
+}
+
+// Named returns a copy of the accessor that describes its filters with the
+// given field path, which must be the field the getter reads. Unnamed
+// accessors build filters described as custom; named ones can be marshaled
+// to JSON, merged by Optimize and answered by indexes like their string-based
+// counterparts.
+//
+// Example:
+//
+//	age := filter.Field(func(u User) int { return u.Age }).Named("Age")
+//	fmt.Println(age.Gt(18))  // Age > 18
+func (f TypedField[T, V]) Named(name string) TypedField[T, V] {
+	f.name = name
+	return f
+}
+
+// Name returns the field path given to Named, or "" for an unnamed accessor.
+func (f TypedField[T, V]) Name() string {
+	return f.name
+}
+
+// filter builds a filter described by op and value when the accessor is named
+func (f TypedField[T, V]) filter(op Op, value interface{}, match func(item T) bool) Filter[T] {
+	if f.name == "" {
+		return FilterFunc[T](match)
+	}
+	return newNode(Expr{Field: f.name, Op: op, Value: value}, match)
+}
+
+// Eq returns a filter that checks if the field equals value.
+func (f TypedField[T, V]) Eq(value V) Filter[T] {
+	return f.filter(OpEq, value, func(item T) bool {
+		return f.get(item) == value
+	})
+}
+
+// Ne returns a filter that checks if the field does not equal value.
+func (f TypedField[T, V]) Ne(value V) Filter[T] {
+	return f.filter(OpNe, value, func(item T) bool {
+		return f.get(item) != value
+	})
+}
+
+// Gt returns a filter that checks if the field is greater than value.
+func (f TypedField[T, V]) Gt(value V) Filter[T] {
+	return f.filter(OpGt, value, func(item T) bool {
+		return f.get(item) > value
+	})
+}
+
+// Gte returns a filter that checks if the field is greater than or equal to value.
+func (f TypedField[T, V]) Gte(value V) Filter[T] {
+	return f.filter(OpGte, value, func(item T) bool {
+		return f.get(item) >= value
+	})
+}
+
+// Lt returns a filter that checks if the field is less than value.
+func (f TypedField[T, V]) Lt(value V) Filter[T] {
+	return f.filter(OpLt, value, func(item T) bool {
+		return f.get(item) < value
+	})
+}
+
+// Lte returns a filter that checks if the field is less than or equal to value.
+func (f TypedField[T, V]) Lte(value V) Filter[T] {
+	return f.filter(OpLte, value, func(item T) bool {
+		return f.get(item) <= value
+	})
+}
+
+// In returns a filter that checks if the field equals any of the values.
+//
+// Example:
+//
+//	city := filter.Field(func(u User) string { return u.City })
+//	city.In("SP", "RJ")
+func (f TypedField[T, V]) In(values ...V) Filter[T] {
+	set := make(map[V]bool, len(values))
+	described := make([]interface{}, len(values))
+	for i, value := range values {
+		set[value] = true
+		described[i] = value
+	}
+
+	return f.filter(OpIn, described, func(item T) bool {
+		return set[f.get(item)]
+	})
+}
+
+// Between returns a filter that checks if the field is between min and max, inclusive.
+func (f TypedField[T, V]) Between(min, max V) Filter[T] {
+	return f.filter(OpBetween, []interface{}{min, max}, func(item T) bool {
+		value := f.get(item)
+		return value >= min && value <= max
+	})
+}
+
+// Asc returns a sort key that orders items by the field in ascending order.
+func (f TypedField[T, V]) Asc() SortKey[T] {
+	return SortKey[T]{Field: f.name, Ascending: true, compare: f.compare}
+}
+
+// Desc returns a sort key that orders items by the field in descending order.
+func (f TypedField[T, V]) Desc() SortKey[T] {
+	return SortKey[T]{Field: f.name, Ascending: false, compare: f.compare}
+}
+
+// compare compares the field of two items
+func (f TypedField[T, V]) compare(a, b T) int {
+	return cmp.Compare(f.get(a), f.get(b))
+}
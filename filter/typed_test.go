@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	personAge  = Field(func(p Person) int { return p.Age })
+	personName = Field(func(p Person) string { return p.Name })
+)
+
+func typedPeople() []Person {
+	return []Person{
+		{Name: "Ana", Age: 17},
+		{Name: "Bia", Age: 25},
+		{Name: "Caio", Age: 40},
+		{Name: "Davi", Age: 25},
+	}
+}
+
+func TestTypedField(t *testing.T) {
+	people := typedPeople()
+
+	tests := []struct {
+		typed  Filter[Person]
+		byName Filter[Person]
+	}{
+		{personAge.Eq(25), Eq[Person]("Age", 25)},
+		{personAge.Ne(25), Ne[Person]("Age", 25)},
+		{personAge.Gt(25), Gt[Person]("Age", 25)},
+		{personAge.Gte(25), Gte[Person]("Age", 25)},
+		{personAge.Lt(25), Lt[Person]("Age", 25)},
+		{personAge.Lte(25), Lte[Person]("Age", 25)},
+		{personName.In("Ana", "Davi"), In[Person]("Name", []interface{}{"Ana", "Davi"})},
+		{personAge.Between(18, 30), Between[Person]("Age", 18, 30)},
+		{And(personAge.Gt(18), personName.Ne("Bia")), And(Gt[Person]("Age", 18), Ne[Person]("Name", "Bia"))},
+	}
+
+	for i, tt := range tests {
+		want, got := Apply(people, tt.byName), Apply(people, tt.typed)
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("case %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestTypedFieldSort(t *testing.T) {
+	sorted := SortBy(typedPeople(), personAge.Desc(), personName.Asc())
+
+	var names []string
+	for _, p := range sorted {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Caio", "Bia", "Davi", "Ana"}) {
+		t.Errorf("Unexpected order %v", names)
+	}
+}
+
+func TestTypedFieldNamed(t *testing.T) {
+	if Describe(personAge.Gt(18)).Op != OpCustom {
+		t.Error("Expected unnamed typed filters to be described as custom")
+	}
+
+	age := personAge.Named("Age")
+	if age.Name() != "Age" {
+		t.Errorf("Expected name Age, got %q", age.Name())
+	}
+	if got := Describe(And(age.Gt(18), personName.Named("Name").In("Ana", "Bia"))).String(); got != `(Age > 18 AND Name IN ["Ana","Bia"])` {
+		t.Errorf("Unexpected description %s", got)
+	}
+
+	data, err := MarshalJSON(age.Between(18, 30))
+	if err != nil || string(data) != `{"field":"Age","op":"between","value":[18,30]}` {
+		t.Errorf("Unexpected encoding %s (%v)", data, err)
+	}
+	if age.Asc().Field != "Age" {
+		t.Error("Expected sort keys to carry the field name")
+	}
+}
+
+func BenchmarkEqReflection(b *testing.B) {
+	people := typedPeople()
+	f := Eq[Person]("Age", 25)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Apply(people, f)
+	}
+}
+
+func BenchmarkEqTyped(b *testing.B) {
+	people := typedPeople()
+	f := personAge.Eq(25)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Apply(people, f)
+	}
+}
@@ -0,0 +1,142 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Validate type-checks every field path and value of a filter against T.
+// It reports the mistakes operators otherwise treat as a non-match, such as a
+// misspelled field, a string compared with an int field, a float that would be
+// truncated to compare with an int field or an invalid regular expression.
+// All problems are returned together, joined with errors.Join; each one is an
+// ErrUnknownField, ErrInvalidPath or ErrInvalidOperand. Custom filters are
+// not checked.
+//
+// When T is an interface type, fields can only be checked on concrete items;
+// see Collect.
+//
+// Example:
+//
+//	if err := filter.Validate(filter.Eq[User]("Aeg", 18)); err != nil {
+//	    log.Fatal(err)  // unknown field "Aeg"
+//	}
+func Validate[T any](f Filter[T]) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	return validate[T](Describe(f), t)
+}
+
+// Must returns f unchanged, and panics if Validate reports an error.
+// Use it for filters built at program start or in tests.
+//
+// Example:
+//
+//	var adults = filter.Must(filter.Gte[User]("Age", 18))
+func Must[T any](f Filter[T]) Filter[T] {
+	if err := Validate(f); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// validate checks every leaf of an expression against itemType
+func validate[T any](e Expr, itemType reflect.Type) error {
+	var errs []error
+
+	Inspect(e, func(e Expr) bool {
+		switch e.Op {
+		case OpAnd, OpOr, OpNot:
+			return true
+		case OpCustom:
+			return false
+		}
+
+		if _, err := compile[T](e, itemType); err != nil {
+			errs = append(errs, err)
+		}
+		return false
+	})
+
+	return errors.Join(errs...)
+}
+
+// ErrorCollector gathers the errors found by filters wrapped with Collect.
+// It is safe for concurrent use.
+type ErrorCollector struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// Errors returns the errors collected so far.
+func (c *ErrorCollector) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	errs := make([]error, len(c.errs))
+	copy(errs, c.errs)
+	return errs
+}
+
+// Err returns the errors collected so far joined with errors.Join,
+// or nil if there are none.
+func (c *ErrorCollector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return errors.Join(c.errs...)
+}
+
+// add records an error
+func (c *ErrorCollector) add(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errs = append(c.errs, err)
+}
+
+// collected is a filter that validates itself against the items it evaluates
+type collected[T any] struct {
+	filter    Filter[T]
+	expr      Expr
+	collector *ErrorCollector
+	checked   sync.Map // reflect.Type -> bool
+}
+
+// Collect returns a filter that behaves like f and reports to c the problems
+// Validate would find, checked against the concrete type of each item it
+// evaluates. Each concrete type is checked once. It is meant for filters over
+// interface types, whose fields Validate cannot check, and for catching bugs
+// in tests or staging without failing requests.
+//
+// Example:
+//
+//	var c filter.ErrorCollector
+//	shapes := filter.Apply(items, filter.Collect(filter.Gt[Shape]("Radius", 2), &c))
+//	if err := c.Err(); err != nil {
+//	    log.Println(err)  // main.Square: unknown field "Radius"
+//	}
+func Collect[T any](f Filter[T], c *ErrorCollector) Filter[T] {
+	return &collected[T]{filter: f, expr: Describe(f), collector: c}
+}
+
+// Apply implements the Filter interface for collected.
+func (f *collected[T]) Apply(item T) bool {
+	if t := reflect.TypeOf(item); t != nil {
+		if _, seen := f.checked.LoadOrStore(t, true); !seen {
+			if err := validate[T](f.expr, t); err != nil {
+				f.collector.add(fmt.Errorf("%s: %w", t, err))
+			}
+		}
+	}
+	return f.filter.Apply(item)
+}
+
+// Expr implements the Describer interface for collected.
+func (f *collected[T]) Expr() Expr {
+	return f.expr
+}
@@ -0,0 +1,122 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []Filter[Person]{
+		Eq[Person]("Age", 18),
+		Gt[Person]("Age", int64(18)),
+		In[Person]("Address.City", []interface{}{"SP", "RJ"}),
+		And(StringMatch[Person]("Name", "a", StringMatchOptions{Mode: PrefixMatch}), Not(IsNil[Person]("Address"))),
+		Contains[Person]("Hobbies", "chess"),
+		Or(Custom(func(Person) bool { return true }), Lte[Person]("Age", 30.0)),
+	}
+	for _, f := range valid {
+		if err := Validate(f); err != nil {
+			t.Errorf("%v: unexpected error %v", f, err)
+		}
+	}
+
+	var unknownField *ErrUnknownField
+	var invalid *ErrInvalidOperand
+	tests := []struct {
+		filter Filter[Person]
+		target interface{}
+	}{
+		{Eq[Person]("Aeg", 18), &unknownField},
+		{Eq[Person]("Address.Street", "x"), &unknownField},
+		{Eq[Person]("Age", "18"), &invalid},
+		{Eq[Person]("Age", 18.5), &invalid},
+		{Gt[Person]("Address", 1), &invalid},
+		{RegexMatch[Person]("Name", "("), &invalid},
+		{DateAfter[Person]("Age", time.Now()), &invalid},
+		{Not(In[Person]("Name", []interface{}{"Ana", 3})), &invalid},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.filter)
+		if err == nil || !errors.As(err, tt.target) {
+			t.Errorf("%v: expected %T, got %v", tt.filter, tt.target, err)
+		}
+	}
+
+	// Every problem is reported
+	err := Validate(And(Eq[Person]("Aeg", 18), Eq[Person]("Nmae", "Ana")))
+	if err == nil || !strings.Contains(err.Error(), "Aeg") || !strings.Contains(err.Error(), "Nmae") {
+		t.Errorf("Expected both misspelled fields to be reported, got %v", err)
+	}
+}
+
+func TestMust(t *testing.T) {
+	f := Gt[Person]("Age", 18)
+	if Must(f) != f {
+		t.Error("Expected Must to return the filter unchanged")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Must to panic for an unknown field")
+		}
+	}()
+	Must(Eq[Person]("Aeg", 18))
+}
+
+type shape interface{ Area() float64 }
+
+type circle struct{ Radius float64 }
+
+func (c circle) Area() float64 { return 3.14 * c.Radius * c.Radius }
+
+type square struct{ Side float64 }
+
+func (s square) Area() float64 { return s.Side * s.Side }
+
+func TestCollect(t *testing.T) {
+	shapes := []shape{circle{Radius: 1}, square{Side: 2}, circle{Radius: 3}, square{Side: 1}}
+
+	// Validate cannot check fields of an interface type
+	f := Gt[shape]("Radius", 2)
+	if err := Validate(f); err != nil {
+		t.Errorf("Expected no static errors for an interface type, got %v", err)
+	}
+
+	var c ErrorCollector
+	result := Apply(shapes, Collect(f, &c))
+	if len(result) != 1 {
+		t.Errorf("Expected 1 circle with radius > 2, got %d", len(result))
+	}
+
+	errs := c.Errors()
+	var unknownField *ErrUnknownField
+	if len(errs) != 1 || !errors.As(errs[0], &unknownField) || !strings.Contains(errs[0].Error(), "square") {
+		t.Errorf("Expected one unknown field error for square, got %v", errs)
+	}
+
+	if Describe(Collect(f, &c)).Op != OpGt {
+		t.Error("Expected a collected filter to describe the wrapped filter")
+	}
+}
+
+func TestCollectConcurrent(t *testing.T) {
+	var c ErrorCollector
+	f := Collect(Eq[Person]("Aeg", 1), &c)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.Apply(Person{Age: 1})
+		}()
+	}
+	wg.Wait()
+
+	if len(c.Errors()) != 1 || c.Err() == nil {
+		t.Errorf("Expected the error to be reported once, got %v", c.Errors())
+	}
+}
@@ -0,0 +1,53 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+func benchUsers(n int) []User {
+	cities := []string{"SP", "RJ", "MG", "BA", "RS"}
+	users := make([]User, n)
+	for i := range users {
+		users[i] = User{
+			Name: "User" + string(rune('A'+i%26)),
+			Age:  18 + i%50,
+			City: cities[i%len(cities)],
+		}
+	}
+	return users
+}
+
+func benchSet(b *testing.B, users []User) *Set[User] {
+	byCity, err := NewHashIndex(users, "City")
+	if err != nil {
+		b.Fatal(err)
+	}
+	byAge, err := NewRangeIndex(users, "Age")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return NewSet(users, byCity, byAge)
+}
+
+var selective = filter.And(
+	filter.Eq[User]("City", "SP"),
+	filter.Between[User]("Age", 30, 32),
+)
+
+func BenchmarkScan_100K_Selective(b *testing.B) {
+	users := benchUsers(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filter.Apply(users, selective)
+	}
+}
+
+func BenchmarkIndex_100K_Selective(b *testing.B) {
+	set := benchSet(b, benchUsers(100_000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Apply(selective)
+	}
+}
@@ -0,0 +1,110 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// HashIndex indexes a field by value for equality lookups.
+// It answers Eq and In filters.
+type HashIndex[T any] struct {
+	field     string
+	fieldType reflect.Type
+	buckets   map[interface{}][]int
+}
+
+// NewHashIndex builds a hash index on a field of items.
+// The field must be a string, integer, float or bool, and may be a
+// dot-separated nested path.
+//
+// Example:
+//
+//	byCity, err := index.NewHashIndex(users, "City")
+func NewHashIndex[T any](items []T, field string) (*HashIndex[T], error) {
+	t, err := resolveFieldType[T](field)
+	if err != nil {
+		return nil, err
+	}
+	if classOf(t.Kind()) == classNone {
+		return nil, fmt.Errorf("cannot hash index %q: unsupported type %s", field, t)
+	}
+
+	idx := &HashIndex[T]{
+		field:     field,
+		fieldType: t,
+		buckets:   make(map[interface{}][]int),
+	}
+	for pos, item := range items {
+		idx.Add(pos, item)
+	}
+	return idx, nil
+}
+
+// Field implements the Index interface for HashIndex.
+func (idx *HashIndex[T]) Field() string {
+	return idx.field
+}
+
+// Add implements the Index interface for HashIndex.
+func (idx *HashIndex[T]) Add(pos int, item T) {
+	if key, ok := itemKey(item, idx.field); ok {
+		idx.buckets[key] = append(idx.buckets[key], pos)
+	}
+}
+
+// Remove implements the Index interface for HashIndex.
+func (idx *HashIndex[T]) Remove(pos int, item T) {
+	key, ok := itemKey(item, idx.field)
+	if !ok {
+		return
+	}
+
+	positions := removePosition(idx.buckets[key], pos)
+	if len(positions) == 0 {
+		delete(idx.buckets, key)
+	} else {
+		idx.buckets[key] = positions
+	}
+}
+
+// Lookup implements the Index interface for HashIndex.
+func (idx *HashIndex[T]) Lookup(e filter.Expr) ([]int, bool) {
+	if e.Field != idx.field {
+		return nil, false
+	}
+
+	switch e.Op {
+	case filter.OpEq:
+		key, ok := lookupKey(e.Value, idx.fieldType)
+		if !ok {
+			return nil, false
+		}
+		return append([]int(nil), idx.buckets[key]...), true
+
+	case filter.OpIn:
+		values, ok := e.Value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		seen := make(map[interface{}]bool, len(values))
+		var positions []int
+		for _, value := range values {
+			key, ok := lookupKey(value, idx.fieldType)
+			if !ok {
+				return nil, false
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			positions = append(positions, idx.buckets[key]...)
+		}
+		return positions, true
+
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,315 @@
+// Package index provides in-memory secondary indexes that answer filters
+// without scanning every item.
+//
+// An index is built on a single field of a slice. A Set groups the indexes
+// of a slice and executes filters against them: Eq, In, Between, Gt, Gte,
+// Lt, Lte and prefix StringMatch filters on indexed fields are answered
+// from the indexes, and whatever remains is applied as a residual filter
+// to the candidates only.
+//
+// Example:
+//
+//	byCity, _ := index.NewHashIndex(users, "City")
+//	byAge, _ := index.NewRangeIndex(users, "Age")
+//	set := index.NewSet(users, byCity, byAge)
+//
+//	result := set.Apply(filter.And(
+//	    filter.Eq[User]("City", "SP"),      // answered by byCity
+//	    filter.Gte[User]("Age", 18),        // answered by byAge
+//	    filter.Contains[User]("Name", "a"), // applied to the candidates
+//	))
+package index
+
+import (
+	"slices"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// Index answers filter expressions on a single field.
+// Items are identified by their position in the indexed slice.
+type Index[T any] interface {
+	// Field returns the field path the index was built on
+	Field() string
+	// Add indexes the item stored at position pos
+	Add(pos int, item T)
+	// Remove removes the item stored at position pos from the index
+	Remove(pos int, item T)
+	// Lookup returns the positions of the items matching e, in no particular
+	// order. It returns false if the index cannot answer e.
+	Lookup(e filter.Expr) ([]int, bool)
+}
+
+// Set executes filters over a slice using the indexes built on it.
+// Items can be inserted, updated and deleted after creation, and the
+// indexes are kept in sync. A Set is not safe for concurrent writes.
+type Set[T any] struct {
+	items   []T
+	deleted []bool
+	free    []int
+	indexes []Index[T]
+	byField map[string][]Index[T]
+}
+
+// NewSet creates a set over items using the given indexes.
+// The indexes must have been built from the same slice.
+//
+// Example:
+//
+//	set := index.NewSet(users, byCity, byAge)
+func NewSet[T any](items []T, indexes ...Index[T]) *Set[T] {
+	s := &Set[T]{
+		items:   items,
+		deleted: make([]bool, len(items)),
+		indexes: indexes,
+		byField: make(map[string][]Index[T]),
+	}
+	for _, idx := range indexes {
+		s.byField[idx.Field()] = append(s.byField[idx.Field()], idx)
+	}
+	return s
+}
+
+// Items returns the items of the set in position order.
+// Until an item is deleted, this is the slice the set was built on.
+func (s *Set[T]) Items() []T {
+	if len(s.free) == 0 {
+		return s.items
+	}
+
+	result := make([]T, 0, len(s.items)-len(s.free))
+	for pos, item := range s.items {
+		if !s.deleted[pos] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Len returns the number of items in the set.
+func (s *Set[T]) Len() int {
+	return len(s.items) - len(s.free)
+}
+
+// At returns the item at position pos, or false if there is none.
+func (s *Set[T]) At(pos int) (T, bool) {
+	if pos < 0 || pos >= len(s.items) || s.deleted[pos] {
+		var zero T
+		return zero, false
+	}
+	return s.items[pos], true
+}
+
+// Insert adds an item to the set and its indexes and returns its position.
+// Positions freed by Delete are reused.
+func (s *Set[T]) Insert(item T) int {
+	var pos int
+	if n := len(s.free); n > 0 {
+		pos = s.free[n-1]
+		s.free = s.free[:n-1]
+		s.items[pos] = item
+		s.deleted[pos] = false
+	} else {
+		pos = len(s.items)
+		s.items = append(s.items, item)
+		s.deleted = append(s.deleted, false)
+	}
+
+	for _, idx := range s.indexes {
+		idx.Add(pos, item)
+	}
+	return pos
+}
+
+// Update replaces the item at position pos and reindexes it.
+// It does nothing if there is no item at pos.
+func (s *Set[T]) Update(pos int, item T) {
+	old, ok := s.At(pos)
+	if !ok {
+		return
+	}
+
+	for _, idx := range s.indexes {
+		idx.Remove(pos, old)
+		idx.Add(pos, item)
+	}
+	s.items[pos] = item
+}
+
+// Delete removes the item at position pos from the set and its indexes.
+// It does nothing if there is no item at pos.
+func (s *Set[T]) Delete(pos int) {
+	old, ok := s.At(pos)
+	if !ok {
+		return
+	}
+
+	for _, idx := range s.indexes {
+		idx.Remove(pos, old)
+	}
+
+	var zero T
+	s.items[pos] = zero
+	s.deleted[pos] = true
+	s.free = append(s.free, pos)
+}
+
+// Apply returns the items passing the filter, in their original order.
+// The filter is answered from the indexes where possible; if no part of it
+// can use an index, Apply falls back to filter.Apply.
+//
+// Results always come from the set's own items, so after the source slice
+// changes, update the set with Insert, Update and Delete or build a new one.
+//
+// Example:
+//
+//	adults := set.Apply(filter.Gte[User]("Age", 18))
+func (s *Set[T]) Apply(f filter.Filter[T]) []T {
+	positions, residual, ok := s.resolve(f)
+	if !ok {
+		return filter.Apply(s.Items(), f)
+	}
+
+	slices.Sort(positions)
+
+	result := make([]T, 0, len(positions))
+	for _, pos := range positions {
+		item := s.items[pos]
+		if residual == nil || residual.Apply(item) {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// Plan describes how a Set answers a filter.
+type Plan struct {
+	// Indexed is true when at least part of the filter is answered by an index
+	Indexed bool
+	// Exact is true when the indexes answer the filter on their own, without
+	// checking the candidates against a residual filter
+	Exact bool
+	// Candidates is the number of items the indexes narrow the filter down to
+	Candidates int
+}
+
+// Plan reports how Apply would answer a filter, without applying it.
+//
+// Example:
+//
+//	plan := set.Plan(filter.Eq[User]("City", "SP"))
+//	fmt.Println(plan.Indexed, plan.Candidates)  // true 2
+func (s *Set[T]) Plan(f filter.Filter[T]) Plan {
+	positions, residual, ok := s.resolve(f)
+	if !ok {
+		return Plan{}
+	}
+	return Plan{Indexed: true, Exact: residual == nil, Candidates: len(positions)}
+}
+
+// lookup answers a leaf expression from the first index able to
+func (s *Set[T]) lookup(e filter.Expr) ([]int, bool) {
+	for _, idx := range s.byField[e.Field] {
+		if positions, ok := idx.Lookup(e); ok {
+			return positions, true
+		}
+	}
+	return nil, false
+}
+
+// resolve narrows a filter down to candidate positions using the indexes.
+// Candidates must still pass the returned residual filter, which is nil when
+// the indexes answered the filter exactly.
+func (s *Set[T]) resolve(f filter.Filter[T]) ([]int, filter.Filter[T], bool) {
+	e := filter.Describe(f)
+
+	switch e.Op {
+	case filter.OpAnd:
+		var candidates []int
+		var residual []filter.Filter[T]
+		resolved := false
+
+		for _, operand := range filter.Operands(f) {
+			positions, rest, ok := s.resolve(operand)
+			if !ok {
+				residual = append(residual, operand)
+				continue
+			}
+			if rest != nil {
+				residual = append(residual, rest)
+			}
+			if resolved {
+				candidates = intersect(candidates, positions)
+			} else {
+				candidates = positions
+				resolved = true
+			}
+		}
+
+		if !resolved {
+			return nil, nil, false
+		}
+		switch len(residual) {
+		case 0:
+			return candidates, nil, true
+		case 1:
+			return candidates, residual[0], true
+		default:
+			return candidates, filter.And(residual...), true
+		}
+
+	case filter.OpOr:
+		seen := make(map[int]bool)
+		var candidates []int
+
+		for _, operand := range filter.Operands(f) {
+			positions, rest, ok := s.resolve(operand)
+			if !ok {
+				return nil, nil, false
+			}
+			for _, pos := range positions {
+				if !seen[pos] && (rest == nil || rest.Apply(s.items[pos])) {
+					seen[pos] = true
+					candidates = append(candidates, pos)
+				}
+			}
+		}
+		return candidates, nil, true
+
+	default:
+		positions, ok := s.lookup(e)
+		return positions, nil, ok
+	}
+}
+
+// intersect returns the positions present in both a and b
+func intersect(a, b []int) []int {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	in := make(map[int]bool, len(a))
+	for _, pos := range a {
+		in[pos] = true
+	}
+
+	result := make([]int, 0, len(a))
+	for _, pos := range b {
+		if in[pos] {
+			result = append(result, pos)
+			delete(in, pos)
+		}
+	}
+	return result
+}
+
+// removePosition removes one occurrence of pos from positions
+func removePosition(positions []int, pos int) []int {
+	for i, p := range positions {
+		if p == pos {
+			return append(positions[:i], positions[i+1:]...)
+		}
+	}
+	return positions
+}
@@ -0,0 +1,353 @@
+package index
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+type Address struct {
+	City string
+}
+
+type User struct {
+	Name    string
+	Age     int
+	City    string
+	Score   float64
+	Active  bool
+	Tags    []string
+	Address *Address
+}
+
+func testUsers() []User {
+	return []User{
+		{Name: "Ana", Age: 20, City: "SP", Score: 8.5, Address: &Address{City: "Campinas"}},
+		{Name: "Bruno", Age: 17, City: "RJ", Score: 7.0},
+		{Name: "Carla", Age: 25, City: "SP", Score: 9.1, Address: &Address{City: "Santos"}},
+		{Name: "Daniel", Age: 30, City: "MG", Score: 6.8},
+		{Name: "Elena", Age: 22, City: "RJ", Score: 8.9, Address: &Address{City: "Niteroi"}},
+		{Name: "Andre", Age: 25, City: "BA", Score: 7.5},
+	}
+}
+
+func names(users []User) []string {
+	result := make([]string, len(users))
+	for i, u := range users {
+		result[i] = u.Name
+	}
+	return result
+}
+
+func testSet(t *testing.T, users []User) *Set[User] {
+	t.Helper()
+	byCity, err := NewHashIndex(users, "City")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byAge, err := NewRangeIndex(users, "Age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName, err := NewPrefixIndex(users, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSet(users, byCity, byAge, byName)
+}
+
+func TestNewIndexErrors(t *testing.T) {
+	if _, err := NewHashIndex(testUsers(), "Missing"); err == nil {
+		t.Error("expected error for missing field")
+	}
+	if _, err := NewHashIndex(testUsers(), "Tags"); err == nil {
+		t.Error("expected error for slice field")
+	}
+	if _, err := NewRangeIndex(testUsers(), "Active"); err == nil {
+		t.Error("expected error for bool range index")
+	}
+	if _, err := NewPrefixIndex(testUsers(), "Age"); err == nil {
+		t.Error("expected error for non-string prefix index")
+	}
+	if _, err := NewHashIndex(testUsers(), "Address.City"); err != nil {
+		t.Errorf("unexpected error for nested field: %v", err)
+	}
+	if _, err := NewHashIndex(testUsers(), "Tags[*]"); err == nil {
+		t.Error("expected error for wildcard path")
+	}
+}
+
+// Post names its fields by json tag through filter.UseJSONNames
+type Post struct {
+	Title    string `json:"title"`
+	AuthorID int    `json:"author_id"`
+}
+
+func init() { filter.UseJSONNames[Post]() }
+
+func TestIndexJSONNames(t *testing.T) {
+	posts := []Post{{"Hello", 1}, {"Again", 2}, {"Bye", 1}}
+
+	byAuthor, err := NewHashIndex(posts, "author_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byTitle, err := NewPrefixIndex(posts, "title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := NewSet(posts, byAuthor, byTitle)
+
+	result := set.Apply(filter.Eq[Post]("author_id", 1))
+	if len(result) != 2 || result[0].Title != "Hello" || result[1].Title != "Bye" {
+		t.Errorf("expected the posts of author 1, got %v", result)
+	}
+	if plan := set.Plan(filter.Eq[Post]("author_id", 1)); !plan.Exact || plan.Candidates != 2 {
+		t.Errorf("expected the json-named filter to use the index, got %+v", plan)
+	}
+}
+
+func TestHashIndexLookup(t *testing.T) {
+	idx, _ := NewHashIndex(testUsers(), "City")
+
+	positions, ok := idx.Lookup(filter.Describe(filter.Eq[User]("City", "SP")))
+	if !ok || !reflect.DeepEqual(positions, []int{0, 2}) {
+		t.Errorf("expected positions [0 2], got %v (ok=%v)", positions, ok)
+	}
+
+	positions, ok = idx.Lookup(filter.Describe(filter.In[User]("City", []interface{}{"MG", "BA", "MG"})))
+	if !ok || len(positions) != 2 {
+		t.Errorf("expected 2 positions, got %v (ok=%v)", positions, ok)
+	}
+
+	if _, ok := idx.Lookup(filter.Describe(filter.Ne[User]("City", "SP"))); ok {
+		t.Error("hash index should not answer Ne")
+	}
+}
+
+func TestRangeIndexLookup(t *testing.T) {
+	idx, _ := NewRangeIndex(testUsers(), "Age")
+
+	tests := []struct {
+		f    filter.Filter[User]
+		want int
+	}{
+		{filter.Eq[User]("Age", 25), 2},
+		{filter.Gt[User]("Age", 22), 3},
+		{filter.Gte[User]("Age", 22), 4},
+		{filter.Lt[User]("Age", 20), 1},
+		{filter.Lte[User]("Age", 20), 2},
+		{filter.Between[User]("Age", 20, 25), 4},
+		{filter.In[User]("Age", []interface{}{17, 30}), 2},
+	}
+	for _, tt := range tests {
+		e := filter.Describe(tt.f)
+		positions, ok := idx.Lookup(e)
+		if !ok || len(positions) != tt.want {
+			t.Errorf("%s %v: expected %d positions, got %v (ok=%v)", e.Op, e.Value, tt.want, positions, ok)
+		}
+	}
+
+	// Floats compared with an int field are left to a scan
+	if _, ok := idx.Lookup(filter.Describe(filter.Gt[User]("Age", 20.5))); ok {
+		t.Error("range index should not answer a float operand on an int field")
+	}
+}
+
+func TestPrefixIndexLookup(t *testing.T) {
+	idx, _ := NewPrefixIndex(testUsers(), "Name")
+
+	prefix := filter.StringMatch[User]("Name", "An", filter.StringMatchOptions{Mode: filter.PrefixMatch})
+	positions, ok := idx.Lookup(filter.Describe(prefix))
+	if !ok || len(positions) != 2 {
+		t.Errorf("expected 2 names starting with An, got %v (ok=%v)", positions, ok)
+	}
+
+	positions, ok = idx.Lookup(filter.Describe(filter.Eq[User]("Name", "Ana")))
+	if !ok || !reflect.DeepEqual(positions, []int{0}) {
+		t.Errorf("expected [0] for Ana, got %v", positions)
+	}
+
+	ignoreCase := filter.StringMatch[User]("Name", "an", filter.StringMatchOptions{Mode: filter.PrefixMatch, IgnoreCase: true})
+	if _, ok := idx.Lookup(filter.Describe(ignoreCase)); ok {
+		t.Error("prefix index should not answer case-insensitive prefixes")
+	}
+}
+
+func TestSetApply(t *testing.T) {
+	users := testUsers()
+	set := testSet(t, users)
+
+	tests := []filter.Filter[User]{
+		filter.Eq[User]("City", "SP"),
+		filter.And(filter.Eq[User]("City", "SP"), filter.Gte[User]("Age", 21)),
+		filter.And(filter.In[User]("City", []interface{}{"SP", "RJ"}), filter.Contains[User]("Name", "a")),
+		filter.Or(filter.Eq[User]("City", "MG"), filter.Lt[User]("Age", 18)),
+		filter.Or(filter.Eq[User]("City", "MG"), filter.And(filter.Eq[User]("City", "SP"), filter.Gt[User]("Score", 9.0))),
+		filter.StringMatch[User]("Name", "An", filter.StringMatchOptions{Mode: filter.PrefixMatch}),
+		filter.Gt[User]("Score", 8.0),
+		filter.Not(filter.Eq[User]("City", "SP")),
+		filter.Or(filter.Eq[User]("City", "MG"), filter.Gt[User]("Score", 9.0)),
+	}
+
+	for i, f := range tests {
+		got := names(set.Apply(f))
+		want := names(filter.Apply(users, f))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("case %d: index returned %v, scan returned %v", i, got, want)
+		}
+	}
+}
+
+func TestSetResidual(t *testing.T) {
+	set := testSet(t, testUsers())
+
+	f := filter.And(filter.Eq[User]("City", "SP"), filter.Gt[User]("Score", 9.0))
+	positions, residual, ok := set.resolve(f)
+	if !ok {
+		t.Fatal("expected the City filter to be answered by an index")
+	}
+	if len(positions) != 2 {
+		t.Errorf("expected 2 candidates from the City index, got %v", positions)
+	}
+	if residual == nil || filter.Describe(residual).Field != "Score" {
+		t.Errorf("expected the Score filter as residual, got %+v", residual)
+	}
+
+	if _, _, ok := set.resolve(filter.Gt[User]("Score", 9.0)); ok {
+		t.Error("expected unindexed filters not to resolve")
+	}
+}
+
+func TestSetPlan(t *testing.T) {
+	set := testSet(t, testUsers())
+
+	if plan := set.Plan(filter.Eq[User]("City", "SP")); plan != (Plan{Indexed: true, Exact: true, Candidates: 2}) {
+		t.Errorf("unexpected plan for an indexed filter: %+v", plan)
+	}
+	if plan := set.Plan(filter.And(filter.Eq[User]("City", "SP"), filter.Gt[User]("Score", 9.0))); !plan.Indexed || plan.Exact {
+		t.Errorf("expected a partially indexed plan, got %+v", plan)
+	}
+	if plan := set.Plan(filter.Gt[User]("Score", 9.0)); plan.Indexed {
+		t.Errorf("expected a scan plan, got %+v", plan)
+	}
+}
+
+func TestSetMatchesScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	cities := []string{"SP", "RJ", "MG", "BA"}
+	users := make([]User, 500)
+	for i := range users {
+		users[i] = User{
+			Name: string(rune('A'+rng.Intn(26))) + string(rune('a'+rng.Intn(26))),
+			Age:  rng.Intn(60),
+			City: cities[rng.Intn(len(cities))],
+		}
+	}
+	set := testSet(t, users)
+
+	for i := 0; i < 200; i++ {
+		age := rng.Intn(60)
+		city := cities[rng.Intn(len(cities))]
+		fs := []filter.Filter[User]{
+			filter.And(filter.Eq[User]("City", city), filter.Lt[User]("Age", age)),
+			filter.Or(filter.Gte[User]("Age", age), filter.Eq[User]("City", city)),
+			filter.Between[User]("Age", age, age+10),
+		}
+		for _, f := range fs {
+			if got, want := set.Apply(f), filter.Apply(users, f); !reflect.DeepEqual(got, want) {
+				t.Fatalf("index and scan disagree for %+v: %d vs %d items", filter.Describe(f), len(got), len(want))
+			}
+		}
+	}
+}
+
+func TestSetOverflowingBounds(t *testing.T) {
+	type Reading struct {
+		I int8
+		U uint8
+	}
+	readings := []Reading{{I: 100, U: 200}, {I: -5, U: 3}}
+	byI, err := NewRangeIndex(readings, "I")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byU, err := NewHashIndex(readings, "U")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := NewSet(readings, byI, byU)
+
+	for _, f := range []filter.Filter[Reading]{
+		filter.Lt[Reading]("I", 300),
+		filter.Gt[Reading]("I", 300),
+		filter.Gte[Reading]("I", -300),
+		filter.Between[Reading]("I", -1000, 1000),
+		filter.In[Reading]("I", []interface{}{100, 356}),
+		filter.Eq[Reading]("U", 456),
+	} {
+		if got, want := set.Apply(f), filter.Apply(readings, f); !reflect.DeepEqual(got, want) {
+			t.Errorf("index and scan disagree for %v: %v vs %v", filter.Describe(f), got, want)
+		}
+	}
+}
+
+func TestIndexRemove(t *testing.T) {
+	users := testUsers()
+	indexes := []Index[User]{}
+
+	hash, _ := NewHashIndex(users, "City")
+	rng, _ := NewRangeIndex(users, "City")
+	prefix, _ := NewPrefixIndex(users, "City")
+	indexes = append(indexes, hash, rng, prefix)
+
+	for _, idx := range indexes {
+		idx.Remove(0, users[0])
+
+		positions, ok := idx.Lookup(filter.Describe(filter.Eq[User]("City", "SP")))
+		if !ok || !reflect.DeepEqual(positions, []int{2}) {
+			t.Errorf("%T: expected [2] after removing position 0, got %v", idx, positions)
+		}
+
+		idx.Add(0, users[0])
+		positions, _ = idx.Lookup(filter.Describe(filter.Eq[User]("City", "SP")))
+		if len(positions) != 2 {
+			t.Errorf("%T: expected 2 positions after re-adding, got %v", idx, positions)
+		}
+	}
+}
+
+func TestSetMutations(t *testing.T) {
+	users := testUsers()
+	set := testSet(t, append([]User(nil), users...))
+
+	set.Delete(0)
+	set.Update(2, User{Name: "Carla", Age: 26, City: "RJ"})
+	pos := set.Insert(User{Name: "Fabio", Age: 40, City: "SP"})
+
+	if pos != 0 {
+		t.Errorf("expected freed position 0 to be reused, got %d", pos)
+	}
+	if set.Len() != 6 {
+		t.Errorf("expected 6 items, got %d", set.Len())
+	}
+
+	got := names(set.Apply(filter.Eq[User]("City", "SP")))
+	if !reflect.DeepEqual(got, []string{"Fabio"}) {
+		t.Errorf("expected [Fabio] in SP, got %v", got)
+	}
+	got = names(set.Apply(filter.Eq[User]("City", "RJ")))
+	if !reflect.DeepEqual(got, []string{"Bruno", "Carla", "Elena"}) {
+		t.Errorf("expected [Bruno Carla Elena] in RJ, got %v", got)
+	}
+
+	set.Delete(3)
+	if _, ok := set.At(3); ok {
+		t.Error("expected deleted position to be empty")
+	}
+	if got := set.Apply(filter.Gt[User]("Score", 0.0)); len(got) != 3 {
+		t.Errorf("expected scan to skip deleted items, got %d", len(got))
+	}
+}
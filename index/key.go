@@ -0,0 +1,130 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// keyClass groups kinds whose values can be converted into each other
+// without changing what a filter compares.
+type keyClass int
+
+const (
+	classNone keyClass = iota
+	classString
+	classInt
+	classUint
+	classFloat
+	classBool
+)
+
+func classOf(k reflect.Kind) keyClass {
+	switch k {
+	case reflect.String:
+		return classString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return classInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return classUint
+	case reflect.Float32, reflect.Float64:
+		return classFloat
+	case reflect.Bool:
+		return classBool
+	default:
+		return classNone
+	}
+}
+
+// keyOf returns the normalized key of a value, so that values the filter
+// package considers equal share a key.
+func keyOf(v reflect.Value) (interface{}, bool) {
+	switch classOf(v.Kind()) {
+	case classString:
+		return v.String(), true
+	case classInt:
+		return v.Int(), true
+	case classUint:
+		return v.Uint(), true
+	case classFloat:
+		return v.Float(), true
+	case classBool:
+		return v.Bool(), true
+	default:
+		return nil, false
+	}
+}
+
+// compareKeys orders two keys of the same class
+func compareKeys(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int64:
+		return compareOrdered(a, b.(int64))
+	case uint64:
+		return compareOrdered(a, b.(uint64))
+	case float64:
+		return compareOrdered(a, b.(float64))
+	default:
+		return 0
+	}
+}
+
+func compareOrdered[V int64 | uint64 | float64](a, b V) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// lookupKey converts a filter operand to the indexed field type the way the
+// filter package does and returns its key. Operands of another kind class
+// (such as a float compared with an int field), and operands the field type
+// cannot hold exactly (such as 300 for an int8 field), are left to a scan.
+func lookupKey(value interface{}, t reflect.Type) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || classOf(v.Kind()) == classNone || classOf(v.Kind()) != classOf(t.Kind()) {
+		return nil, false
+	}
+	converted := v.Convert(t)
+	if !converted.Convert(v.Type()).Equal(v) {
+		return nil, false
+	}
+	return keyOf(converted)
+}
+
+// itemKey returns the key of an item's field
+func itemKey(item interface{}, field string) (interface{}, bool) {
+	v, err := filter.ExportedGetFieldValue(item, field)
+	if err != nil {
+		return nil, false
+	}
+	return keyOf(v)
+}
+
+// resolveFieldType returns the type of a field path of T, resolved as the
+// filter package resolves it, so json names registered with
+// filter.UseJSONNames work. Paths with wildcards read several values and
+// cannot be indexed.
+func resolveFieldType[T any](field string) (reflect.Type, error) {
+	if strings.Contains(field, "[*]") || strings.Contains(field, "[any]") || strings.Contains(field, "[all]") {
+		return nil, fmt.Errorf("cannot index %q: path has a wildcard", field)
+	}
+
+	t, err := filter.ExportedFieldType(reflect.TypeOf((*T)(nil)).Elem(), field)
+	if err != nil {
+		return nil, fmt.Errorf("cannot index %q: %v", field, err)
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, nil
+}
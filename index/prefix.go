@@ -0,0 +1,147 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// trieNode is a node of a PrefixIndex, holding the items whose value ends here
+type trieNode struct {
+	children  map[rune]*trieNode
+	positions []int
+}
+
+// PrefixIndex stores a string field in a trie for prefix lookups.
+// It answers case-sensitive prefix and exact StringMatch filters and Eq.
+type PrefixIndex[T any] struct {
+	field string
+	root  *trieNode
+}
+
+// NewPrefixIndex builds a trie index on a string field of items.
+// The field may be a dot-separated nested path.
+//
+// Example:
+//
+//	byName, err := index.NewPrefixIndex(users, "Name")
+//	set := index.NewSet(users, byName)
+//	set.Apply(filter.StringMatch[User]("Name", "An", filter.StringMatchOptions{Mode: filter.PrefixMatch}))
+func NewPrefixIndex[T any](items []T, field string) (*PrefixIndex[T], error) {
+	t, err := resolveFieldType[T](field)
+	if err != nil {
+		return nil, err
+	}
+	if t.Kind() != reflect.String {
+		return nil, fmt.Errorf("cannot prefix index %q: %s is not a string", field, t)
+	}
+
+	idx := &PrefixIndex[T]{
+		field: field,
+		root:  &trieNode{},
+	}
+	for pos, item := range items {
+		idx.Add(pos, item)
+	}
+	return idx, nil
+}
+
+// Field implements the Index interface for PrefixIndex.
+func (idx *PrefixIndex[T]) Field() string {
+	return idx.field
+}
+
+// value returns the string value of an item's field
+func (idx *PrefixIndex[T]) value(item T) (string, bool) {
+	v, err := filter.ExportedGetFieldValue(item, idx.field)
+	if err != nil || v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// find returns the node for s, or nil if no value starts with s
+func (idx *PrefixIndex[T]) find(s string) *trieNode {
+	n := idx.root
+	for _, r := range s {
+		n = n.children[r]
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// Add implements the Index interface for PrefixIndex.
+func (idx *PrefixIndex[T]) Add(pos int, item T) {
+	s, ok := idx.value(item)
+	if !ok {
+		return
+	}
+
+	n := idx.root
+	for _, r := range s {
+		child := n.children[r]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			n.children[r] = child
+		}
+		n = child
+	}
+	n.positions = append(n.positions, pos)
+}
+
+// Remove implements the Index interface for PrefixIndex.
+func (idx *PrefixIndex[T]) Remove(pos int, item T) {
+	s, ok := idx.value(item)
+	if !ok {
+		return
+	}
+	if n := idx.find(s); n != nil {
+		n.positions = removePosition(n.positions, pos)
+	}
+}
+
+// Lookup implements the Index interface for PrefixIndex.
+func (idx *PrefixIndex[T]) Lookup(e filter.Expr) ([]int, bool) {
+	if e.Field != idx.field {
+		return nil, false
+	}
+	s, ok := e.Value.(string)
+	if !ok {
+		return nil, false
+	}
+
+	switch e.Op {
+	case filter.OpEq, filter.OpExact:
+		n := idx.find(s)
+		if n == nil {
+			return nil, true
+		}
+		return append([]int(nil), n.positions...), true
+
+	case filter.OpPrefix:
+		n := idx.find(s)
+		if n == nil {
+			return nil, true
+		}
+		var result []int
+		collect(n, &result)
+		return result, true
+
+	default:
+		return nil, false
+	}
+}
+
+// collect appends the positions stored under n
+func collect(n *trieNode, result *[]int) {
+	*result = append(*result, n.positions...)
+	for _, child := range n.children {
+		collect(child, result)
+	}
+}
@@ -0,0 +1,171 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// rangeEntry is one indexed value of a RangeIndex
+type rangeEntry struct {
+	key interface{}
+	pos int
+}
+
+// RangeIndex keeps a field's values sorted for range lookups.
+// It answers Eq, In, Between, Gt, Gte, Lt and Lte filters.
+type RangeIndex[T any] struct {
+	field     string
+	fieldType reflect.Type
+	entries   []rangeEntry
+}
+
+// NewRangeIndex builds a sorted index on a field of items.
+// The field must be a string, integer or float, and may be a
+// dot-separated nested path.
+//
+// Example:
+//
+//	byAge, err := index.NewRangeIndex(users, "Age")
+func NewRangeIndex[T any](items []T, field string) (*RangeIndex[T], error) {
+	t, err := resolveFieldType[T](field)
+	if err != nil {
+		return nil, err
+	}
+	if c := classOf(t.Kind()); c == classNone || c == classBool {
+		return nil, fmt.Errorf("cannot range index %q: unsupported type %s", field, t)
+	}
+
+	idx := &RangeIndex[T]{
+		field:     field,
+		fieldType: t,
+		entries:   make([]rangeEntry, 0, len(items)),
+	}
+	for pos, item := range items {
+		if key, ok := itemKey(item, field); ok {
+			idx.entries = append(idx.entries, rangeEntry{key: key, pos: pos})
+		}
+	}
+	sort.SliceStable(idx.entries, func(i, j int) bool {
+		return compareKeys(idx.entries[i].key, idx.entries[j].key) < 0
+	})
+	return idx, nil
+}
+
+// Field implements the Index interface for RangeIndex.
+func (idx *RangeIndex[T]) Field() string {
+	return idx.field
+}
+
+// Add implements the Index interface for RangeIndex.
+func (idx *RangeIndex[T]) Add(pos int, item T) {
+	key, ok := itemKey(item, idx.field)
+	if !ok {
+		return
+	}
+
+	i := idx.upper(key)
+	idx.entries = append(idx.entries, rangeEntry{})
+	copy(idx.entries[i+1:], idx.entries[i:])
+	idx.entries[i] = rangeEntry{key: key, pos: pos}
+}
+
+// Remove implements the Index interface for RangeIndex.
+func (idx *RangeIndex[T]) Remove(pos int, item T) {
+	key, ok := itemKey(item, idx.field)
+	if !ok {
+		return
+	}
+
+	for i := idx.lower(key); i < len(idx.entries) && compareKeys(idx.entries[i].key, key) == 0; i++ {
+		if idx.entries[i].pos == pos {
+			idx.entries = append(idx.entries[:i], idx.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// lower returns the index of the first entry with a key >= key
+func (idx *RangeIndex[T]) lower(key interface{}) int {
+	return sort.Search(len(idx.entries), func(i int) bool {
+		return compareKeys(idx.entries[i].key, key) >= 0
+	})
+}
+
+// upper returns the index of the first entry with a key > key
+func (idx *RangeIndex[T]) upper(key interface{}) int {
+	return sort.Search(len(idx.entries), func(i int) bool {
+		return compareKeys(idx.entries[i].key, key) > 0
+	})
+}
+
+// positions returns the positions of the entries in [from, to)
+func (idx *RangeIndex[T]) positions(from, to int) []int {
+	if from >= to {
+		return nil
+	}
+	result := make([]int, 0, to-from)
+	for _, entry := range idx.entries[from:to] {
+		result = append(result, entry.pos)
+	}
+	return result
+}
+
+// Lookup implements the Index interface for RangeIndex.
+func (idx *RangeIndex[T]) Lookup(e filter.Expr) ([]int, bool) {
+	if e.Field != idx.field {
+		return nil, false
+	}
+
+	if e.Op == filter.OpBetween || e.Op == filter.OpIn {
+		values, ok := e.Value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		keys := make([]interface{}, len(values))
+		for i, value := range values {
+			if keys[i], ok = lookupKey(value, idx.fieldType); !ok {
+				return nil, false
+			}
+		}
+
+		if e.Op == filter.OpBetween {
+			if len(keys) != 2 {
+				return nil, false
+			}
+			return idx.positions(idx.lower(keys[0]), idx.upper(keys[1])), true
+		}
+
+		seen := make(map[interface{}]bool, len(keys))
+		var result []int
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, idx.positions(idx.lower(key), idx.upper(key))...)
+			}
+		}
+		return result, true
+	}
+
+	key, ok := lookupKey(e.Value, idx.fieldType)
+	if !ok {
+		return nil, false
+	}
+
+	switch e.Op {
+	case filter.OpEq:
+		return idx.positions(idx.lower(key), idx.upper(key)), true
+	case filter.OpGt:
+		return idx.positions(idx.upper(key), len(idx.entries)), true
+	case filter.OpGte:
+		return idx.positions(idx.lower(key), len(idx.entries)), true
+	case filter.OpLt:
+		return idx.positions(0, idx.lower(key)), true
+	case filter.OpLte:
+		return idx.positions(0, idx.upper(key)), true
+	default:
+		return nil, false
+	}
+}
@@ -2,6 +2,7 @@ package query
 
 import (
 	"net/url"
+	"runtime"
 	"testing"
 )
 
@@ -63,3 +64,39 @@ func BenchmarkApply_100K_SingleFilter(b *testing.B) {
 		Apply(users, params)
 	}
 }
+
+func BenchmarkApply_100K_Parallel(b *testing.B) {
+	users := generateUsers(100_000)
+	params := url.Values{"city": {"SP"}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Apply(users, params, WithParallelism(runtime.GOMAXPROCS(0)))
+	}
+}
+
+func BenchmarkApply_1M_SingleFilter(b *testing.B) {
+	users := generateUsers(1_000_000)
+	params := url.Values{"city": {"SP"}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Apply(users, params)
+	}
+}
+
+func BenchmarkApply_1M_Parallel(b *testing.B) {
+	users := generateUsers(1_000_000)
+	params := url.Values{"city": {"SP"}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Apply(users, params, WithParallelism(runtime.GOMAXPROCS(0)))
+	}
+}
+
+func BenchmarkApplyPaginated_100K_SortFirstPage(b *testing.B) {
+	users := generateUsers(100_000)
+	params := url.Values{"sort": {"-score"}, "page": {"1"}, "limit": {"10"}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ApplyPaginated(users, params)
+	}
+}
@@ -9,6 +9,22 @@ import (
 
 var timeType = reflect.TypeOf(time.Time{})
 
+// coerceField converts a raw value to the type of a field, using the
+// field's generated coercion when there is one. Dates are read relative to
+// dates (see dateContext.parse), and string fields with layouts hold dates.
+func coerceField(raw string, info fieldInfo, dates dateContext) (interface{}, error) {
+	if len(info.layouts) > 0 {
+		return dates.parseIn(raw, info.layouts)
+	}
+	if info.coerce != nil {
+		return info.coerce(raw)
+	}
+	if info.fieldType == timeType {
+		return dates.parse(raw)
+	}
+	return coerceValue(raw, info.fieldType)
+}
+
 func coerceValue(raw string, targetType reflect.Type) (interface{}, error) {
 	if targetType == timeType {
 		return parseTime(raw)
@@ -100,16 +116,7 @@ func coerceValue(raw string, targetType reflect.Type) (interface{}, error) {
 	}
 }
 
+// parseTime reads a date in UTC, relative to the current time
 func parseTime(raw string) (time.Time, error) {
-	formats := []string{
-		time.RFC3339,
-		"2006-01-02",
-	}
-	for _, f := range formats {
-		t, err := time.Parse(f, raw)
-		if err == nil {
-			return t, nil
-		}
-	}
-	return time.Time{}, fmt.Errorf("cannot parse %q as time", raw)
+	return dateContext{now: time.Now().UTC(), loc: time.UTC}.parse(raw)
 }
@@ -0,0 +1,413 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// dateContext holds what relative dates in query values are resolved
+// against: the time the query started and the time zone of its dates
+type dateContext struct {
+	now time.Time
+	loc *time.Location
+}
+
+// dates returns the date context of a query, reading the tz parameter
+func (o options) dates(params url.Values) (dateContext, error) {
+	loc := o.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if tz := params.Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return dateContext{}, &ErrInvalidValue{Field: "tz", Value: tz, ExpectedType: "IANA time zone"}
+		}
+	}
+
+	now := time.Now
+	if o.clock != nil {
+		now = o.clock
+	}
+	return dateContext{now: now().In(loc), loc: loc}, nil
+}
+
+// absoluteLayouts are the layouts of absolute dates, tried in order. Layouts
+// without a zone are read in the query's time zone.
+var absoluteLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parse reads an absolute or relative date:
+//
+//	2024-01-15, 2024-01-15T10:30:00Z   RFC 3339 dates and times
+//	1705314600                         Unix timestamps in seconds
+//	now, today, yesterday, tomorrow    the current time or day
+//	startOf(month), endOf(week)        bounds of the current hour, day, week, month or year
+//	now-7d, today+1M, now-P1DT12H      offsets in s, m, h, d, w, M, y or ISO 8601 durations
+//
+// Days start at midnight in the query's time zone and weeks on Monday.
+func (dc dateContext) parse(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, raw, dc.loc); err == nil {
+			return t, nil
+		}
+	}
+	if t, ok := parseUnix(raw); ok {
+		return t.In(dc.loc), nil
+	}
+
+	base, rest, err := dc.base(raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for rest != "" {
+		if base, rest, err = applyOffset(base, rest); err != nil {
+			return time.Time{}, fmt.Errorf("cannot parse %q as time: %w", raw, err)
+		}
+	}
+	return base, nil
+}
+
+// parseIn reads a date in one of layouts, in the query's time zone, or else
+// as parse does
+func (dc dateContext) parseIn(raw string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(raw), dc.loc); err == nil {
+			return t, nil
+		}
+	}
+	return dc.parse(raw)
+}
+
+// base reads the keyword a relative date starts with and returns its time
+// and the offsets that follow it
+func (dc dateContext) base(raw string) (time.Time, string, error) {
+	today := startOf(dc.now, "day")
+
+	keywords := []struct {
+		name string
+		time time.Time
+	}{
+		{"now", dc.now},
+		{"today", today},
+		{"yesterday", today.AddDate(0, 0, -1)},
+		{"tomorrow", today.AddDate(0, 0, 1)},
+	}
+	for _, k := range keywords {
+		if strings.HasPrefix(raw, k.name) {
+			return k.time, raw[len(k.name):], nil
+		}
+	}
+
+	for _, bound := range []string{"startOf(", "endOf("} {
+		if !strings.HasPrefix(raw, bound) {
+			continue
+		}
+		end := strings.IndexByte(raw, ')')
+		if end < 0 {
+			return time.Time{}, "", fmt.Errorf("cannot parse %q as time: %s is not closed", raw, bound)
+		}
+
+		unit := raw[len(bound):end]
+		start := startOf(dc.now, unit)
+		if start.IsZero() {
+			return time.Time{}, "", fmt.Errorf("cannot parse %q as time: unknown unit %q", raw, unit)
+		}
+		if bound == "endOf(" {
+			start = addUnit(start, 1, unit).Add(-time.Nanosecond)
+		}
+		return start, raw[end+1:], nil
+	}
+
+	return time.Time{}, "", fmt.Errorf("cannot parse %q as time", raw)
+}
+
+// startOf returns the start of the hour, day, week, month or year containing
+// t, or the zero time for another unit
+func startOf(t time.Time, unit string) time.Time {
+	year, month, day := t.Date()
+	switch unit {
+	case "hour":
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case "day":
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	case "week":
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, t.Location())
+	case "month":
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	case "year":
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// addUnit adds n hours, days, weeks, months or years to t. Days, weeks,
+// months and years are calendar units, so they keep the time of day across
+// daylight saving changes.
+func addUnit(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "hour":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "day":
+		return t.AddDate(0, 0, n)
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(n, 0, 0)
+	}
+}
+
+// offsetUnits maps the units of short offsets such as 7d to addUnit units.
+// Seconds (s) and minutes (m) are added as durations.
+var offsetUnits = map[byte]string{
+	'h': "hour",
+	'd': "day",
+	'w': "week",
+	'M': "month",
+	'y': "year",
+}
+
+// applyOffset applies the offset at the start of s, such as -7d or +P1M, to
+// t and returns the rest of s. A space stands for +, since that is what an
+// unescaped + in a query string decodes to.
+func applyOffset(t time.Time, s string) (time.Time, string, error) {
+	sign := 1
+	switch s[0] {
+	case '+', ' ':
+		s = s[1:]
+	case '-':
+		sign = -1
+		s = s[1:]
+	default:
+		return t, "", fmt.Errorf("expected + or - before %q", s)
+	}
+
+	if strings.HasPrefix(s, "P") {
+		end := strings.IndexAny(s, "+- ")
+		if end < 0 {
+			end = len(s)
+		}
+		t, err := addISODuration(t, s[:end], sign)
+		return t, s[end:], err
+	}
+
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || digits == len(s) {
+		return t, "", fmt.Errorf("invalid offset %q", s)
+	}
+	n, err := strconv.Atoi(s[:digits])
+	if err != nil {
+		return t, "", fmt.Errorf("invalid offset %q", s)
+	}
+	n *= sign
+
+	unit, rest := s[digits], s[digits+1:]
+	switch unit {
+	case 's':
+		return t.Add(time.Duration(n) * time.Second), rest, nil
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute), rest, nil
+	}
+	if name, ok := offsetUnits[unit]; ok {
+		return addUnit(t, n, name), rest, nil
+	}
+	return t, "", fmt.Errorf("unknown unit %q in offset %q", unit, s)
+}
+
+// addISODuration adds an ISO 8601 duration such as P1Y2M10DT2H30M, times
+// sign, to t
+func addISODuration(t time.Time, duration string, sign int) (time.Time, error) {
+	s := duration[1:]
+	if s == "" || s == "T" {
+		return t, fmt.Errorf("empty duration %q", duration)
+	}
+
+	var years, months, days int
+	var clock time.Duration
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			if inTime {
+				return t, fmt.Errorf("invalid duration %q", duration)
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if end <= 0 {
+			return t, fmt.Errorf("invalid duration %q", duration)
+		}
+		value, err := strconv.ParseFloat(s[:end], 64)
+		if err != nil {
+			return t, fmt.Errorf("invalid duration %q", duration)
+		}
+		designator := s[end]
+		s = s[end+1:]
+
+		whole := int(value)
+		if float64(whole) != value && !(inTime && designator == 'S') {
+			return t, fmt.Errorf("only seconds can be fractional in %q", duration)
+		}
+
+		switch {
+		case !inTime && designator == 'Y':
+			years += whole
+		case !inTime && designator == 'M':
+			months += whole
+		case !inTime && designator == 'W':
+			days += 7 * whole
+		case !inTime && designator == 'D':
+			days += whole
+		case inTime && designator == 'H':
+			clock += time.Duration(whole) * time.Hour
+		case inTime && designator == 'M':
+			clock += time.Duration(whole) * time.Minute
+		case inTime && designator == 'S':
+			clock += time.Duration(value * float64(time.Second))
+		default:
+			return t, fmt.Errorf("invalid duration %q", duration)
+		}
+	}
+
+	return t.AddDate(sign*years, sign*months, sign*days).Add(time.Duration(sign) * clock), nil
+}
+
+// parseUnix reads a Unix timestamp in seconds, with an optional fraction
+func parseUnix(raw string) (time.Time, bool) {
+	secs, frac, _ := strings.Cut(raw, ".")
+	if secs == "" || strings.Trim(secs, "0123456789") != "" || strings.Trim(frac, "0123456789") != "" || len(frac) > 9 {
+		return time.Time{}, false
+	}
+
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var nsec int64
+	if frac != "" {
+		nsec, _ = strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+	}
+	return time.Unix(sec, nsec), true
+}
+
+// datePart is a date part operator, such as _weekday, with the values of
+// its part
+type datePart struct {
+	part     filter.TimePart
+	min, max int
+	names    map[string]int
+}
+
+// dateParts are the date part operators of time fields
+var dateParts = map[string]datePart{
+	"year":    {part: filter.Year, min: 1, max: 9999},
+	"month":   {part: filter.Month, min: 1, max: 12, names: monthNames()},
+	"day":     {part: filter.Day, min: 1, max: 31},
+	"weekday": {part: filter.Weekday, min: 0, max: 6, names: weekdayNames()},
+	"hour":    {part: filter.Hour, min: 0, max: 23},
+	"minute":  {part: filter.Minute, min: 0, max: 59},
+}
+
+// weekdayNames maps the English names of weekdays, in full and abbreviated
+// to three letters, to their numbers
+func weekdayNames() map[string]int {
+	names := make(map[string]int, 14)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		names[name], names[name[:3]] = int(d), int(d)
+	}
+	return names
+}
+
+// monthNames maps the English names of months, in full and abbreviated to
+// three letters, to their numbers
+func monthNames() map[string]int {
+	names := make(map[string]int, 24)
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		names[name], names[name[:3]] = int(m), int(m)
+	}
+	return names
+}
+
+// maxDatePartItems bounds the values and ranges of one date part parameter
+const maxDatePartItems = 64
+
+// parse reads a comma-separated list of values and ranges of the part, such
+// as sat,sun or 9-17, and returns the values it covers once each, in
+// ascending order. Ranges of every part but year wrap around, so 22-2 covers
+// 22, 23, 0, 1 and 2. Lists of more than maxDatePartItems items are invalid.
+func (p datePart) parse(raw string) ([]interface{}, error) {
+	items := strings.Split(raw, ",")
+	if len(items) > maxDatePartItems {
+		return nil, fmt.Errorf("too many %s values: %d, at most %d", p.part, len(items), maxDatePartItems)
+	}
+
+	covered := make([]bool, p.max-p.min+1)
+	for _, item := range items {
+		from, to, isRange := strings.Cut(strings.TrimSpace(item), "-")
+		first, err := p.value(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = p.value(to); err != nil {
+				return nil, err
+			}
+		}
+		if last < first && p.part == filter.Year {
+			return nil, fmt.Errorf("invalid year range %q", item)
+		}
+
+		for n := first; ; n++ {
+			if n > p.max {
+				n = p.min
+			}
+			covered[n-p.min] = true
+			if n == last {
+				break
+			}
+		}
+	}
+
+	var values []interface{}
+	for i, ok := range covered {
+		if ok {
+			values = append(values, p.min+i)
+		}
+	}
+	return values, nil
+}
+
+// value reads one value of the part, as a number or a name
+func (p datePart) value(raw string) (int, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if n, ok := p.names[raw]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < p.min || n > p.max {
+		return 0, fmt.Errorf("invalid %s %q", p.part, raw)
+	}
+	return n, nil
+}
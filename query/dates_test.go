@@ -0,0 +1,102 @@
+package query
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateContextParse(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	// Friday, March 15th 2024, 14:30 in São Paulo (UTC-3)
+	now := time.Date(2024, 3, 15, 14, 30, 0, 0, saoPaulo)
+	dc := dateContext{now: now, loc: saoPaulo}
+	at := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, saoPaulo)
+	}
+
+	tests := []struct {
+		raw  string
+		want time.Time
+	}{
+		{"2024-01-15", at(2024, 1, 15, 0, 0)},
+		{"2024-01-15T10:30", at(2024, 1, 15, 10, 30)},
+		{"2024-01-15T10:30:00Z", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"1705314600", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"1705314600.5", time.Date(2024, 1, 15, 10, 30, 0, 500_000_000, time.UTC)},
+		{"now", now},
+		{"today", at(2024, 3, 15, 0, 0)},
+		{"yesterday", at(2024, 3, 14, 0, 0)},
+		{"tomorrow", at(2024, 3, 16, 0, 0)},
+		{"now-7d", at(2024, 3, 8, 14, 30)},
+		{"now+2h-30m", at(2024, 3, 15, 16, 0)},
+		{"now 1w", at(2024, 3, 22, 14, 30)},
+		{"today-1M", at(2024, 2, 15, 0, 0)},
+		{"today+1y", at(2025, 3, 15, 0, 0)},
+		{"now-90s", at(2024, 3, 15, 14, 28).Add(30 * time.Second)},
+		{"startOf(hour)", at(2024, 3, 15, 14, 0)},
+		{"startOf(week)", at(2024, 3, 11, 0, 0)},
+		{"startOf(month)", at(2024, 3, 1, 0, 0)},
+		{"startOf(year)", at(2024, 1, 1, 0, 0)},
+		{"endOf(month)", at(2024, 4, 1, 0, 0).Add(-time.Nanosecond)},
+		{"startOf(month)-1M", at(2024, 2, 1, 0, 0)},
+		{"now-P1M", at(2024, 2, 15, 14, 30)},
+		{"now-P1DT12H", at(2024, 3, 14, 2, 30)},
+		{"now+PT1.5S", now.Add(1500 * time.Millisecond)},
+		{"today-P2W", at(2024, 3, 1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		got, err := dc.parse(tt.raw)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.raw, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.raw, tt.want, got)
+		}
+	}
+}
+
+func TestDateContextParseErrors(t *testing.T) {
+	dc := dateContext{now: time.Now().UTC(), loc: time.UTC}
+
+	for _, raw := range []string{"", "soon", "now-", "now-7", "now-7q", "now*2d", "startOf(decade)", "startOf(month", "now-P", "now-P1.5D", "now-PT1D", "15/01/2024"} {
+		if _, err := dc.parse(raw); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+func TestDatePartParse(t *testing.T) {
+	tests := []struct {
+		op, raw string
+		want    []interface{}
+	}{
+		{"weekday", "sat,sun", []interface{}{0, 6}},
+		{"weekday", "mon-wed,tue-thu,wed", []interface{}{1, 2, 3, 4}},
+		{"hour", "22-2", []interface{}{0, 1, 2, 22, 23}},
+		{"month", "dec-feb,jan", []interface{}{1, 2, 12}},
+	}
+	for _, tt := range tests {
+		got, err := dateParts[tt.op].parse(tt.raw)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("%s %q: expected %v, got %v, %v", tt.op, tt.raw, tt.want, got, err)
+		}
+	}
+
+	// Repeated ranges cover each value once
+	years, err := dateParts["year"].parse(strings.Repeat("1-9999,", maxDatePartItems-1) + "2024")
+	if err != nil || len(years) != 9999 {
+		t.Errorf("expected 9999 distinct years, got %d, %v", len(years), err)
+	}
+
+	if _, err := dateParts["hour"].parse(strings.Repeat("1,", maxDatePartItems) + "2"); err == nil {
+		t.Errorf("expected an error for more than %d hours", maxDatePartItems)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build gofilter_debug
+
+package query
+
+// In debug builds, explain=true attaches an Explanation to PageResult.
+func init() {
+	reservedParams["explain"] = true
+}
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+// DynamicSchema describes the query fields of dynamic documents, such as
+// JSON objects decoded into map[string]interface{}, by column. It plays the
+// role struct tags play for structs.
+//
+// Example:
+//
+//	schema := query.DynamicSchema{
+//	    "name": {Type: reflect.TypeOf(""), Filterable: true, Sortable: true},
+//	    "age":  {Type: reflect.TypeOf(0.0), Filterable: true, Sortable: true},
+//	    "city": {Path: "address.city", Filterable: true},
+//	}
+type DynamicSchema map[string]DynamicField
+
+// DynamicField describes one column of a DynamicSchema.
+type DynamicField struct {
+	// Path is the dot-separated path of the value in the document, such as
+	// "address.city" or "tags.0". It defaults to the column name.
+	Path string
+	// Type is the type query parameter values are converted to. It defaults
+	// to string. JSON numbers decode as float64, but any numeric type
+	// compares with them.
+	Type reflect.Type
+	// Filterable, Sortable and Searchable mirror the gofilter tag options
+	Filterable bool
+	Sortable   bool
+	Searchable bool
+	// Layouts are the time.Parse layouts of a string value holding dates,
+	// like the layout= tag option
+	Layouts []string
+}
+
+// ApplyDynamic is like Apply for dynamic documents, such as a JSON array
+// decoded into []map[string]interface{}. Fields are described by schema
+// instead of struct tags, and values are looked up by their path in each
+// document; documents where a value is missing do not match filters on it.
+//
+// Example:
+//
+//	var docs []map[string]interface{}
+//	json.NewDecoder(resp.Body).Decode(&docs)
+//
+//	// GET /people?age_gte=18&city=SP&sort=-age
+//	result, err := query.ApplyDynamic(docs, r.URL.Query(), schema)
+func ApplyDynamic(items []map[string]interface{}, params url.Values, schema DynamicSchema, opts ...Option) ([]map[string]interface{}, error) {
+	return Apply(items, params, append(opts, withRegistry(schema.registry()))...)
+}
+
+// ApplyPaginatedDynamic is like ApplyPaginated for dynamic documents, with
+// fields described by schema as in ApplyDynamic.
+//
+// Example:
+//
+//	page, err := query.ApplyPaginatedDynamic(docs, r.URL.Query(), schema)
+func ApplyPaginatedDynamic(items []map[string]interface{}, params url.Values, schema DynamicSchema, opts ...Option) (*PageResult[map[string]interface{}], error) {
+	return ApplyPaginated(items, params, append(opts, withRegistry(schema.registry()))...)
+}
+
+// withRegistry makes queries use reg instead of the fields of T
+func withRegistry(reg *fieldRegistry) Option {
+	return func(o *options) {
+		o.registry = reg
+	}
+}
+
+// registry builds the field registry of the schema, in column order
+func (s DynamicSchema) registry() *fieldRegistry {
+	reg := &fieldRegistry{
+		byColumn: make(map[string]fieldInfo),
+	}
+
+	columns := make([]string, 0, len(s))
+	for column := range s {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		f := s[column]
+		info := fieldInfo{
+			structField: f.Path,
+			column:      column,
+			filterable:  f.Filterable,
+			sortable:    f.Sortable,
+			searchable:  f.Searchable,
+			fieldType:   f.Type,
+			layouts:     f.Layouts,
+		}
+		if info.structField == "" {
+			info.structField = column
+		}
+		if info.fieldType == nil {
+			info.fieldType = reflect.TypeOf("")
+		}
+		reg.add(info)
+	}
+
+	return reg
+}
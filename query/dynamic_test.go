@@ -0,0 +1,122 @@
+package query
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func testDocuments(t *testing.T) []map[string]interface{} {
+	var docs []map[string]interface{}
+	err := json.Unmarshal([]byte(`[
+		{"name": "Ana", "age": 20, "address": {"city": "SP"}},
+		{"name": "Bruno", "age": 17, "address": {"city": "RJ"}},
+		{"name": "Carla", "age": 25, "address": {"city": "SP"}},
+		{"name": "Daniel", "age": 30},
+		{"name": "Elena", "age": 22, "address": {"city": "RJ"}}
+	]`), &docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return docs
+}
+
+var documentSchema = DynamicSchema{
+	"name": {Type: reflect.TypeOf(""), Filterable: true, Sortable: true, Searchable: true},
+	"age":  {Type: reflect.TypeOf(0), Filterable: true, Sortable: true},
+	"city": {Path: "address.city", Filterable: true, Sortable: true},
+}
+
+func documentNames(docs []map[string]interface{}) []interface{} {
+	names := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		names[i] = doc["name"]
+	}
+	return names
+}
+
+func TestApplyDynamic(t *testing.T) {
+	tests := []struct {
+		params url.Values
+		want   []interface{}
+	}{
+		{url.Values{"age_gte": {"20"}, "sort": {"-age"}}, []interface{}{"Daniel", "Carla", "Elena", "Ana"}},
+		{url.Values{"city": {"SP"}, "sort": {"name"}}, []interface{}{"Ana", "Carla"}},
+		{url.Values{"city_in": {"RJ,MG"}, "age_between": {"18,30"}}, []interface{}{"Elena"}},
+		{url.Values{"name_contains": {"an"}}, []interface{}{"Daniel"}},
+		{url.Values{"q": {"carla"}}, []interface{}{"Carla"}},
+		{url.Values{"distinct": {"city"}, "sort": {"name"}}, []interface{}{"Ana", "Bruno"}},
+	}
+
+	for _, tt := range tests {
+		result, err := ApplyDynamic(testDocuments(t), tt.params, documentSchema)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", tt.params, err)
+			continue
+		}
+		if got := documentNames(result); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: expected %v, got %v", tt.params, tt.want, got)
+		}
+	}
+}
+
+func TestApplyDynamicSortMissing(t *testing.T) {
+	var docs []map[string]interface{}
+	err := json.Unmarshal([]byte(`[
+		{"name": "Ana", "age": 30},
+		{"name": "Bruno", "age": null},
+		{"name": "Carla", "age": 20},
+		{"name": "Daniel"},
+		{"name": "Elena", "age": 25}
+	]`), &docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sort string
+		want []interface{}
+	}{
+		{"age", []interface{}{"Carla", "Elena", "Ana", "Bruno", "Daniel"}},
+		{"-age", []interface{}{"Ana", "Elena", "Carla", "Bruno", "Daniel"}},
+	}
+
+	for _, tt := range tests {
+		result, err := ApplyDynamic(docs, url.Values{"sort": {tt.sort}}, documentSchema)
+		if err != nil {
+			t.Errorf("sort=%s: unexpected error %v", tt.sort, err)
+			continue
+		}
+		if got := documentNames(result); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sort=%s: expected %v, got %v", tt.sort, tt.want, got)
+		}
+	}
+}
+
+func TestApplyDynamicErrors(t *testing.T) {
+	docs := testDocuments(t)
+
+	_, err := ApplyDynamic(docs, url.Values{"email": {"a@b.c"}}, documentSchema)
+	if _, ok := err.(*ErrFieldNotFilterable); !ok {
+		t.Errorf("expected ErrFieldNotFilterable, got %T: %v", err, err)
+	}
+
+	_, err = ApplyDynamic(docs, url.Values{"age": {"old"}}, documentSchema)
+	if _, ok := err.(*ErrInvalidValue); !ok {
+		t.Errorf("expected ErrInvalidValue, got %T: %v", err, err)
+	}
+}
+
+func TestApplyPaginatedDynamic(t *testing.T) {
+	params := url.Values{"sort": {"age"}, "page": {"2"}, "limit": {"2"}}
+	page, err := ApplyPaginatedDynamic(testDocuments(t), params, documentSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []interface{}{"Elena", "Carla"}
+	if got := documentNames(page.Items); !reflect.DeepEqual(got, want) || page.Total != 5 || !page.HasNext {
+		t.Errorf("expected %v of 5 with a next page, got %v of %d", want, got, page.Total)
+	}
+}
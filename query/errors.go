@@ -33,3 +33,29 @@ type ErrLimitExceeded struct{ Requested, Max int }
 func (e *ErrLimitExceeded) Error() string {
 	return fmt.Sprintf("requested limit %d exceeds maximum %d", e.Requested, e.Max)
 }
+
+// ErrIndexMismatch is returned when WithIndex is given an index set built
+// for a different item type than the query's.
+type ErrIndexMismatch struct{ Index, ItemType string }
+
+func (e *ErrIndexMismatch) Error() string {
+	return fmt.Sprintf("index %s cannot answer queries on %s", e.Index, e.ItemType)
+}
+
+// ErrStaleIndex is returned when WithIndex is given an index set holding a
+// different number of items than the slice being queried, such as a set
+// built before items were added to the slice.
+type ErrStaleIndex struct{ Indexed, Items int }
+
+func (e *ErrStaleIndex) Error() string {
+	return fmt.Sprintf("index holds %d items but the query got %d", e.Indexed, e.Items)
+}
+
+// ErrQueryBudgetExceeded is returned when a query would scan more items than
+// allowed by WithMaxScanned, or runs longer than allowed by WithTimeout.
+// Budget is "scanned items" or "time".
+type ErrQueryBudgetExceeded struct{ Budget, Limit string }
+
+func (e *ErrQueryBudgetExceeded) Error() string {
+	return fmt.Sprintf("query exceeded its %s budget of %s", e.Budget, e.Limit)
+}
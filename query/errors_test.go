@@ -30,6 +30,20 @@ func TestErrInvalidValue(t *testing.T) {
 	}
 }
 
+func TestErrStaleIndex(t *testing.T) {
+	err := &ErrStaleIndex{Indexed: 3, Items: 4}
+	if err.Error() != "index holds 3 items but the query got 4" {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+}
+
+func TestErrIndexMismatch(t *testing.T) {
+	err := &ErrIndexMismatch{Index: "*index.Set[query.Place]", ItemType: "query.User"}
+	if err.Error() != "index *index.Set[query.Place] cannot answer queries on query.User" {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+}
+
 func TestErrLimitExceeded(t *testing.T) {
 	err := &ErrLimitExceeded{Requested: 500, Max: 100}
 	if err.Error() != "requested limit 500 exceeds maximum 100" {
@@ -0,0 +1,178 @@
+package query
+
+import (
+	"context"
+	"net/url"
+	"runtime"
+	"time"
+
+	"github.com/sidneip/gofilter/filter"
+	"github.com/sidneip/gofilter/index"
+)
+
+// Predicate sources reported by PredicateStats
+const (
+	// SourceIndex means the predicate is answered by an index
+	SourceIndex = "index"
+	// SourcePartialIndex means an index narrows the predicate down and the
+	// candidates are then checked one by one
+	SourcePartialIndex = "index+scan"
+	// SourceScan means every item is checked against the predicate
+	SourceScan = "scan"
+)
+
+// Explanation reports how a query was executed, to help tune slow endpoints.
+// Durations and allocations cover the whole execution; allocations are read
+// from runtime.MemStats, so they include any other goroutine allocating at the
+// same time.
+type Explanation struct {
+	// Filter is the optimized filter the query ran, as printed by filter.Expr.String
+	Filter string `json:"filter"`
+	// Predicates reports each top-level predicate of Filter, in evaluation order
+	Predicates []PredicateStats `json:"predicates"`
+	// Input is the number of items the query started from
+	Input int `json:"input"`
+	// Matched is the number of items left after filtering and distinct
+	Matched int `json:"matched"`
+	// Returned is the number of items on the requested page
+	Returned int `json:"returned"`
+	// FilterTime is the time spent filtering, including index lookups
+	FilterTime time.Duration `json:"filter_time_ns"`
+	// SortTime is the time spent sorting, ranking and removing duplicates
+	SortTime time.Duration `json:"sort_time_ns"`
+	// PaginateTime is the time spent selecting the page
+	PaginateTime time.Duration `json:"paginate_time_ns"`
+	// Allocs is the number of heap allocations made by the query
+	Allocs uint64 `json:"allocs"`
+	// AllocBytes is the number of bytes allocated by the query
+	AllocBytes uint64 `json:"alloc_bytes"`
+}
+
+// PredicateStats reports the selectivity of one predicate. Predicates run in
+// sequence, so In is the number of items left by the previous predicates.
+type PredicateStats struct {
+	// Expr is the predicate, as printed by filter.Expr.String
+	Expr string `json:"expr"`
+	// Source is SourceIndex, SourcePartialIndex or SourceScan
+	Source string `json:"source"`
+	// Candidates is the number of items an index narrowed the predicate down to
+	Candidates int `json:"candidates,omitempty"`
+	// In is the number of items checked against the predicate
+	In int `json:"in"`
+	// Out is the number of items that passed it
+	Out int `json:"out"`
+}
+
+// Explain runs a query like ApplyPaginated and reports how it was executed:
+// the optimized filter, whether each predicate was served by an index or a
+// scan, how many items each predicate kept, the time spent in each stage and
+// the allocations made.
+//
+// In builds with the gofilter_debug tag, ApplyPaginated also accepts
+// explain=true and attaches the explanation to PageResult.
+//
+// Example:
+//
+//	e, err := query.Explain(users, r.URL.Query(), query.WithIndex(set))
+//	for _, p := range e.Predicates {
+//	    log.Printf("%s via %s: %d -> %d", p.Expr, p.Source, p.In, p.Out)
+//	}
+func Explain[T any](items []T, params url.Values, opts ...Option) (*Explanation, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	parsed, err := parseParams[T](params, o)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := o.budget(context.Background())
+	defer cancel()
+
+	_, explanation, err := explain(ctx, items, parsed, o)
+	if err != nil {
+		return nil, err
+	}
+	return explanation, nil
+}
+
+// explain runs a parsed query stage by stage, measuring each stage.
+func explain[T any](ctx context.Context, items []T, parsed *parsedQuery, o options) (*PageResult[T], *Explanation, error) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	filtered, err := filterItems(ctx, items, parsed, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	filteredAt := time.Now()
+	ordered, total, err := order(ctx, filtered, parsed, o, parsed.pageEnd(o))
+	if err != nil {
+		return nil, nil, err
+	}
+	orderedAt := time.Now()
+	page := paginate(ordered, total, parsed, o)
+	end := time.Now()
+
+	runtime.ReadMemStats(&after)
+
+	e := &Explanation{
+		Input:        len(items),
+		Matched:      total,
+		Returned:     len(page.Items),
+		FilterTime:   filteredAt.Sub(start),
+		SortTime:     orderedAt.Sub(filteredAt),
+		PaginateTime: end.Sub(orderedAt),
+		Allocs:       after.Mallocs - before.Mallocs,
+		AllocBytes:   after.TotalAlloc - before.TotalAlloc,
+	}
+	e.Filter, e.Predicates = explainPredicates(items, parsed, o)
+
+	return page, e, nil
+}
+
+// explainPredicates runs the top-level predicates of a query one after the
+// other, recording how many items each keeps and whether an index serves it.
+func explainPredicates[T any](items []T, parsed *parsedQuery, o options) (string, []PredicateStats) {
+	filters := compileFilters[T](parsed)
+	if len(filters) == 0 {
+		return "", []PredicateStats{}
+	}
+
+	f := filter.Optimize(filter.And(filters...))
+	predicates := []filter.Filter[T]{f}
+	if filter.Describe(f).Op == filter.OpAnd {
+		predicates = filter.Operands(f)
+	}
+
+	set, _ := o.index.(*index.Set[T])
+	survivors := items
+	stats := make([]PredicateStats, 0, len(predicates))
+
+	for _, p := range predicates {
+		ps := PredicateStats{
+			Expr:   filter.Describe(p).String(),
+			Source: SourceScan,
+			In:     len(survivors),
+		}
+
+		if set != nil {
+			if plan := set.Plan(p); plan.Indexed {
+				ps.Candidates = plan.Candidates
+				ps.Source = SourcePartialIndex
+				if plan.Exact {
+					ps.Source = SourceIndex
+				}
+			}
+		}
+
+		survivors = filter.Apply(survivors, p)
+		ps.Out = len(survivors)
+		stats = append(stats, ps)
+	}
+
+	return filter.Describe(f).String(), stats
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/sidneip/gofilter/index"
+)
+
+func TestExplain(t *testing.T) {
+	users := testUsers()
+	byCity, err := index.NewHashIndex(users, "City")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := index.NewSet(users, byCity)
+
+	params := url.Values{"city": {"SP"}, "age_gt": {"18"}, "limit": {"1"}}
+	e, err := Explain(users, params, WithIndex(set))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Filter != `(Age > 18 AND City = "SP")` {
+		t.Errorf("unexpected filter %s", e.Filter)
+	}
+	if e.Input != 5 || e.Matched != 2 || e.Returned != 1 {
+		t.Errorf("expected 5 in, 2 matched, 1 returned, got %d, %d, %d", e.Input, e.Matched, e.Returned)
+	}
+	if len(e.Predicates) != 2 {
+		t.Fatalf("expected 2 predicates, got %+v", e.Predicates)
+	}
+
+	age, city := e.Predicates[0], e.Predicates[1]
+	if age.Source != SourceScan || age.In != 5 || age.Out != 4 {
+		t.Errorf("unexpected stats for age: %+v", age)
+	}
+	if city.Source != SourceIndex || city.Candidates != 2 || city.In != 4 || city.Out != 2 {
+		t.Errorf("unexpected stats for city: %+v", city)
+	}
+	if e.Allocs == 0 {
+		t.Error("expected allocations to be measured")
+	}
+}
+
+func TestExplainWithoutFilters(t *testing.T) {
+	e, err := Explain(testUsers(), url.Values{"sort": {"age"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Filter != "" || len(e.Predicates) != 0 || e.Matched != 5 {
+		t.Errorf("unexpected explanation without filters: %+v", e)
+	}
+
+	var notFilterable *ErrFieldNotFilterable
+	if _, err := Explain(testUsers(), url.Values{"email": {"x"}}); !errors.As(err, &notFilterable) {
+		t.Errorf("expected ErrFieldNotFilterable, got %v", err)
+	}
+}
+
+func TestExplainParam(t *testing.T) {
+	params := url.Values{"city": {"SP"}, "explain": {"true"}}
+	page, err := ApplyPaginated(testUsers(), params)
+
+	// explain=true is only accepted in builds with the gofilter_debug tag
+	if !reservedParams["explain"] {
+		var notFilterable *ErrFieldNotFilterable
+		if !errors.As(err, &notFilterable) {
+			t.Errorf("expected ErrFieldNotFilterable without the debug tag, got %v", err)
+		}
+		return
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Explain == nil || page.Explain.Matched != 2 || len(page.Items) != 2 {
+		t.Errorf("expected an explanation of 2 matches, got %+v", page.Explain)
+	}
+
+	page, err = ApplyPaginated(testUsers(), url.Values{"city": {"SP"}})
+	if err != nil || page.Explain != nil {
+		t.Errorf("expected no explanation unless requested, got %+v (%v)", page.Explain, err)
+	}
+
+	var invalid *ErrInvalidValue
+	if _, err := ApplyPaginated(testUsers(), url.Values{"explain": {"maybe"}}); !errors.As(err, &invalid) {
+		t.Errorf("expected ErrInvalidValue for explain=maybe, got %v", err)
+	}
+}
@@ -3,30 +3,53 @@ package query
 import (
 	"fmt"
 	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/sidneip/gofilter/filter"
+	"github.com/sidneip/gofilter/index"
 )
 
-var operators = []string{"between", "contains", "gte", "gt", "lte", "lt", "ne", "in"}
+var operators = []string{"between", "contains", "fuzzy", "gte", "gt", "lte", "lt", "ne", "in",
+	"year", "month", "weekday", "day", "hour", "minute"}
 
 var reservedParams = map[string]bool{
-	"sort":  true,
-	"page":  true,
-	"limit": true,
+	"sort":       true,
+	"page":       true,
+	"limit":      true,
+	"distinct":   true,
+	"q":          true,
+	"count_only": true,
+	"tz":         true,
 }
 
+// scoreSort is the sort value that orders results by search relevance
+const scoreSort = "_score"
+
 type parsedFilter struct {
 	field    string
 	operator string
 	value    interface{}
+	// dates is how a string field holding dates is read, when the filter
+	// compares the dates
+	dates *filter.DateOptions
 }
 
 type parsedQuery struct {
-	filters   []parsedFilter
-	sortField string
-	sortAsc   bool
-	page      int
-	limit     int
+	filters       []parsedFilter
+	sortField     string
+	sortAsc       bool
+	sortDates     *filter.DateOptions
+	distinctField string
+	search        string
+	searchFields  []string
+	sortScore     bool
+	page          int
+	limit         int
+	explain       bool
+	countOnly     bool
 }
 
 func splitParamOperator(param string) (column, operator string) {
@@ -40,9 +63,21 @@ func splitParamOperator(param string) (column, operator string) {
 }
 
 func parseParams[T any](params url.Values, opts options) (*parsedQuery, error) {
-	registry, err := parseStructTags[T]()
-	if err != nil {
-		return nil, err
+	registry := opts.registry
+	if registry == nil {
+		var err error
+		if registry, err = parseStructTags[T](opts.jsonColumns); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.index != nil {
+		if _, ok := opts.index.(*index.Set[T]); !ok {
+			return nil, &ErrIndexMismatch{
+				Index:    reflect.TypeOf(opts.index).String(),
+				ItemType: reflect.TypeOf((*T)(nil)).Elem().String(),
+			}
+		}
 	}
 
 	result := &parsedQuery{
@@ -50,6 +85,11 @@ func parseParams[T any](params url.Values, opts options) (*parsedQuery, error) {
 		limit: opts.defaultLimit,
 	}
 
+	dates, err := opts.dates(params)
+	if err != nil {
+		return nil, err
+	}
+
 	for param, values := range params {
 		if len(values) == 0 {
 			continue
@@ -59,6 +99,10 @@ func parseParams[T any](params url.Values, opts options) (*parsedQuery, error) {
 		if reservedParams[param] {
 			switch param {
 			case "sort":
+				if raw == scoreSort {
+					result.sortScore = true
+					continue
+				}
 				sortField, asc, err := parseSortParam(raw, registry)
 				if err != nil {
 					return nil, err
@@ -80,6 +124,30 @@ func parseParams[T any](params url.Values, opts options) (*parsedQuery, error) {
 					return nil, &ErrLimitExceeded{Requested: l, Max: opts.maxLimit}
 				}
 				result.limit = l
+			case "distinct":
+				info, ok := registry.byColumn[raw]
+				if !ok {
+					return nil, &ErrFieldNotFilterable{Field: raw}
+				}
+				result.distinctField = info.structField
+			case "q":
+				if len(registry.searchable) == 0 {
+					return nil, &ErrFieldNotFilterable{Field: param}
+				}
+				result.search = raw
+				result.searchFields = registry.searchable
+			case "explain":
+				explain, err := strconv.ParseBool(raw)
+				if err != nil {
+					return nil, &ErrInvalidValue{Field: param, Value: raw, ExpectedType: "bool"}
+				}
+				result.explain = explain
+			case "count_only":
+				countOnly, err := strconv.ParseBool(raw)
+				if err != nil {
+					return nil, &ErrInvalidValue{Field: param, Value: raw, ExpectedType: "bool"}
+				}
+				result.countOnly = countOnly
 			}
 			continue
 		}
@@ -87,25 +155,85 @@ func parseParams[T any](params url.Values, opts options) (*parsedQuery, error) {
 		col, op := splitParamOperator(param)
 
 		info, ok := registry.byColumn[col]
+		if exact, isColumn := registry.byColumn[param]; isColumn && op != "eq" {
+			// Columns can end like an operator, as start_day does
+			col, op, info, ok = param, "eq", exact, true
+		}
 		if !ok {
 			return nil, &ErrFieldNotFilterable{Field: col}
 		}
 
-		coerced, err := coerceFilterValue(raw, op, info)
+		coerced, err := coerceFilterValue(raw, op, info, dates)
 		if err != nil {
 			return nil, &ErrInvalidValue{Field: info.structField, Value: raw, ExpectedType: info.fieldType.String()}
 		}
 
-		result.filters = append(result.filters, parsedFilter{
+		pf := parsedFilter{
 			field:    info.structField,
 			operator: op,
 			value:    coerced,
-		})
+		}
+		if op != "contains" && op != "fuzzy" {
+			pf.dates = info.dateOptions(dates.loc)
+		}
+		result.filters = append(result.filters, pf)
+	}
+
+	// String fields holding dates sort by date
+	sortField := result.sortField
+	if sortField == "" {
+		sortField = opts.defaultSort
+	}
+	for _, info := range registry.fields {
+		if info.structField == sortField {
+			result.sortDates = info.dateOptions(dates.loc)
+		}
+	}
+
+	// Parameters arrive in map order; sort filters so plans are reproducible
+	sort.SliceStable(result.filters, func(i, j int) bool {
+		a, b := result.filters[i], result.filters[j]
+		if a.field != b.field {
+			return a.field < b.field
+		}
+		return a.operator < b.operator
+	})
+
+	if result.sortScore && result.search == "" && len(result.fuzzyFilters()) == 0 {
+		return nil, &ErrInvalidValue{Field: "sort", Value: scoreSort, ExpectedType: "a q or _fuzzy parameter to rank by"}
 	}
 
 	return result, nil
 }
 
+// limitOrDefault returns the page size of the query
+func (q *parsedQuery) limitOrDefault(o options) int {
+	if q.limit <= 0 {
+		return o.defaultLimit
+	}
+	return q.limit
+}
+
+// pageEnd returns the number of results up to the end of the requested page
+func (q *parsedQuery) pageEnd(o options) int {
+	return q.page * q.limitOrDefault(o)
+}
+
+// fuzzyFilters returns the fuzzy filters of the query ordered by field,
+// so that relevance sorting does not depend on parameter order.
+func (q *parsedQuery) fuzzyFilters() []parsedFilter {
+	var fuzzy []parsedFilter
+	for _, pf := range q.filters {
+		if pf.operator == "fuzzy" {
+			fuzzy = append(fuzzy, pf)
+		}
+	}
+	sort.Slice(fuzzy, func(i, j int) bool {
+		return fuzzy[i].field < fuzzy[j].field
+	})
+	return fuzzy
+}
+
 func parseSortParam(raw string, registry *fieldRegistry) (string, bool, error) {
 	asc := true
 	field := raw
@@ -125,13 +253,13 @@ func parseSortParam(raw string, registry *fieldRegistry) (string, bool, error) {
 	return info.structField, asc, nil
 }
 
-func coerceFilterValue(raw, op string, info fieldInfo) (interface{}, error) {
+func coerceFilterValue(raw, op string, info fieldInfo, dates dateContext) (interface{}, error) {
 	switch op {
 	case "in":
 		parts := strings.Split(raw, ",")
 		vals := make([]interface{}, 0, len(parts))
 		for _, p := range parts {
-			v, err := coerceValue(strings.TrimSpace(p), info.fieldType)
+			v, err := coerceField(strings.TrimSpace(p), info, dates)
 			if err != nil {
 				return nil, err
 			}
@@ -143,16 +271,36 @@ func coerceFilterValue(raw, op string, info fieldInfo) (interface{}, error) {
 		if len(parts) != 2 {
 			return nil, fmt.Errorf("between requires exactly 2 comma-separated values")
 		}
-		min, err := coerceValue(strings.TrimSpace(parts[0]), info.fieldType)
+		min, err := coerceField(strings.TrimSpace(parts[0]), info, dates)
 		if err != nil {
 			return nil, err
 		}
-		max, err := coerceValue(strings.TrimSpace(parts[1]), info.fieldType)
+		max, err := coerceField(strings.TrimSpace(parts[1]), info, dates)
 		if err != nil {
 			return nil, err
 		}
 		return [2]interface{}{min, max}, nil
+	case "year", "month", "weekday", "day", "hour", "minute":
+		if info.fieldType != timeType && len(info.layouts) == 0 {
+			return nil, fmt.Errorf("%s requires a time field", op)
+		}
+		part := dateParts[op]
+		values, err := part.parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		return filter.DatePartCondition{Part: part.part, Op: filter.OpIn, Value: values, Location: dates.loc}, nil
+	case "contains":
+		if len(info.layouts) > 0 {
+			return raw, nil
+		}
+		return coerceField(raw, info, dates)
+	case "fuzzy":
+		if info.fieldType.Kind() != reflect.String {
+			return nil, fmt.Errorf("fuzzy requires a string field")
+		}
+		return raw, nil
 	default:
-		return coerceValue(raw, info.fieldType)
+		return coerceField(raw, info, dates)
 	}
 }
@@ -42,6 +42,7 @@ func TestParseOperatorSuffix(t *testing.T) {
 		{"name_contains", "contains", "name"},
 		{"city_in", "in", "city"},
 		{"age_between", "between", "age"},
+		{"name_fuzzy", "fuzzy", "name"},
 		{"name", "eq", "name"},
 	}
 	for _, tt := range tests {
@@ -179,3 +180,29 @@ func TestParseBetweenOperator(t *testing.T) {
 		t.Errorf("expected [18, 30], got %v", vals)
 	}
 }
+
+func TestParseDistinct(t *testing.T) {
+	params := url.Values{"distinct": {"city"}}
+	parsed, err := parseParams[ParserTestUser](params, defaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.distinctField != "City" {
+		t.Errorf("expected distinct field 'City', got %q", parsed.distinctField)
+	}
+	if len(parsed.filters) != 0 {
+		t.Errorf("expected distinct to be reserved, got %d filters", len(parsed.filters))
+	}
+}
+
+func TestParseDistinctNotFilterable(t *testing.T) {
+	type Restricted struct {
+		Name string `gofilter:"filterable"`
+		SSN  string
+	}
+	params := url.Values{"distinct": {"ssn"}}
+	_, err := parseParams[Restricted](params, defaultOptions())
+	if _, ok := err.(*ErrFieldNotFilterable); !ok {
+		t.Errorf("expected ErrFieldNotFilterable, got %T: %v", err, err)
+	}
+}
@@ -16,18 +16,24 @@
 package query
 
 import (
+	"context"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/sidneip/gofilter/filter"
+	"github.com/sidneip/gofilter/index"
 )
 
 // PageResult represents a paginated response containing filtered items
 // along with pagination metadata. It is designed to be JSON-serialized
 // directly in HTTP responses.
 type PageResult[T any] struct {
-	// Items contains the filtered and paginated slice of results
+	// Items contains the filtered and paginated slice of results. It is empty
+	// for count_only=true queries.
 	Items []T `json:"items"`
-	// Total is the count of all items matching the filter (before pagination)
+	// Total is the count of all items matching the filter (before pagination),
+	// or -1 when ApplyPaginatedSeq stopped reading before the last item
 	Total int `json:"total"`
 	// Page is the current page number (1-based)
 	Page int `json:"page"`
@@ -35,6 +41,9 @@ type PageResult[T any] struct {
 	Limit int `json:"limit"`
 	// HasNext indicates whether there are more pages available
 	HasNext bool `json:"has_next"`
+	// Explain reports how the query was executed. It is only set for
+	// explain=true queries in builds with the gofilter_debug tag.
+	Explain *Explanation `json:"explain,omitempty"`
 }
 
 type options struct {
@@ -42,6 +51,14 @@ type options struct {
 	maxLimit       int
 	defaultSort    string
 	defaultSortAsc bool
+	index          interface{}
+	parallelism    int
+	maxScanned     int
+	timeout        time.Duration
+	registry       *fieldRegistry
+	jsonColumns    bool
+	location       *time.Location
+	clock          func() time.Time
 }
 
 // Option is a functional option for configuring query behavior.
@@ -92,6 +109,110 @@ func WithDefaultLimit(limit int) Option {
 	}
 }
 
+// WithJSONColumns names query parameters after the json tags of fields
+// instead of the snake_case of their Go names, so a field tagged
+// `json:"createdAt" gofilter:"filterable"` is filtered with ?createdAt_gte=...
+// Fields without a json name keep the snake_case column, and column= in the
+// gofilter tag still takes precedence. Types with a registered Schema keep
+// its columns.
+//
+// Example:
+//
+//	query.Apply(items, params, query.WithJSONColumns())
+func WithJSONColumns() Option {
+	return func(o *options) {
+		o.jsonColumns = true
+	}
+}
+
+// WithLocation sets the time zone of dates in query values: dates and times
+// without a zone offset, and relative dates such as today or startOf(month).
+// The default is UTC. A tz parameter (?tz=America/Sao_Paulo) overrides it.
+//
+// Example:
+//
+//	loc, _ := time.LoadLocation("America/Sao_Paulo")
+//	query.Apply(items, params, query.WithLocation(loc))
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// WithClock sets the function relative dates such as now-7d are resolved
+// against, instead of time.Now. It is read once per query. Use it to make
+// tests deterministic.
+//
+// Example:
+//
+//	fixed := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+//	query.Apply(items, params, query.WithClock(func() time.Time { return fixed }))
+func WithClock(clock func() time.Time) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithIndex answers query filters from an index set instead of scanning the
+// slice; filters on fields without a suitable index are still scanned.
+//
+// The set must hold the same items as the slice passed to Apply or
+// ApplyPaginated: results are read from the set, not from the slice. A set
+// built for another item type fails the query with ErrIndexMismatch, and a
+// set holding a different number of items with ErrStaleIndex, but a set of
+// the same length built from other items returns them without error.
+//
+// Example:
+//
+//	byCity, _ := index.NewHashIndex(users, "City")
+//	set := index.NewSet(users, byCity)
+//	query.Apply(users, params, query.WithIndex(set))
+func WithIndex[T any](set *index.Set[T]) Option {
+	return func(o *options) {
+		o.index = set
+	}
+}
+
+// WithParallelism filters slices of filter.DefaultParallelThreshold items or
+// more with n goroutines, using filter.ApplyParallel. Results keep their
+// order. Smaller slices, and queries answered by WithIndex, are filtered
+// sequentially.
+//
+// Example:
+//
+//	query.Apply(items, params, query.WithParallelism(runtime.GOMAXPROCS(0)))
+func WithParallelism(n int) Option {
+	return func(o *options) {
+		o.parallelism = n
+	}
+}
+
+// WithMaxScanned rejects queries that would check more than n items against
+// their filters with ErrQueryBudgetExceeded, before any item is checked.
+// With WithIndex, only the candidates left by the indexes count.
+//
+// Example:
+//
+//	query.Apply(items, params, query.WithMaxScanned(50_000))
+func WithMaxScanned(n int) Option {
+	return func(o *options) {
+		o.maxScanned = n
+	}
+}
+
+// WithTimeout stops queries that run longer than d while filtering or
+// sorting, and returns ErrQueryBudgetExceeded. It applies to Apply and
+// ApplyPaginated as well as their Context variants.
+//
+// Example:
+//
+//	query.ApplyContext(r.Context(), items, params, query.WithTimeout(50*time.Millisecond))
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
 // Apply filters and sorts a slice based on URL query parameters.
 // It parses the query string for filter operators (eq, gt, lt, contains, etc.),
 // applies them to the slice, and returns the filtered result.
@@ -104,13 +225,34 @@ func WithDefaultLimit(limit int) Option {
 //   - field_lte=value    → less than or equal
 //   - field_ne=value     → not equal
 //   - field_contains=val → substring match
+//   - field_fuzzy=val    → approximate match tolerating typos
 //   - field_in=a,b,c     → value in list
 //   - field_between=a,b  → value between a and b
 //   - sort=field         → sort ascending
 //   - sort=-field        → sort descending
+//   - distinct=field     → keep only the first item for each value of field
+//   - q=text             → full-text search across "searchable" fields
+//   - sort=_score        → sort by search relevance (requires q or a fuzzy filter)
+//   - count_only=true    → only count matches (see ApplyPaginated); ignored by Apply
+//
+// Distinct is applied after sorting, so ?distinct=city&sort=-score returns
+// the highest scoring item of each city.
 //
 // Returns an error if the query contains invalid parameters or values.
 func Apply[T any](items []T, params url.Values, opts ...Option) ([]T, error) {
+	return ApplyContext(context.Background(), items, params, opts...)
+}
+
+// ApplyContext is like Apply, but stops filtering and sorting soon after ctx
+// is canceled or its deadline passes, and returns the context's error. Use it
+// with the request context so that queries stop when clients disconnect.
+//
+// Example:
+//
+//	result, err := query.ApplyContext(r.Context(), users, r.URL.Query(),
+//	    query.WithTimeout(100*time.Millisecond),
+//	)
+func ApplyContext[T any](ctx context.Context, items []T, params url.Values, opts ...Option) ([]T, error) {
 	o := defaultOptions()
 	for _, opt := range opts {
 		opt(&o)
@@ -121,27 +263,36 @@ func Apply[T any](items []T, params url.Values, opts ...Option) ([]T, error) {
 		return nil, err
 	}
 
-	result := items
-	if len(parsed.filters) > 0 {
-		filters := make([]filter.Filter[T], 0, len(parsed.filters))
-		for _, pf := range parsed.filters {
-			f := buildFilter[T](pf)
-			filters = append(filters, f)
-		}
-		result = filter.Apply(result, filter.And(filters...))
-	}
+	ctx, cancel := o.budget(ctx)
+	defer cancel()
 
-	sortField := parsed.sortField
-	sortAsc := parsed.sortAsc
-	if sortField == "" && o.defaultSort != "" {
-		sortField = o.defaultSort
-		sortAsc = o.defaultSortAsc
+	result, _, err := execute(ctx, items, parsed, o, -1)
+	return result, err
+}
+
+// BuildFilter parses the filter parameters of a query string into a single
+// filter.Filter, with the same syntax, validation and options as Apply.
+// Sort, pagination and distinct parameters are validated but have no effect.
+// Use it to reuse query string filters outside of Apply, for example with
+// index sets or standing queries. The filter is optimized with filter.Optimize.
+//
+// Example:
+//
+//	// GET /users?city=SP&age_gte=18
+//	f, err := query.BuildFilter[User](r.URL.Query())
+//	adults := filter.Apply(users, f)
+func BuildFilter[T any](params url.Values, opts ...Option) (filter.Filter[T], error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
 	}
-	if sortField != "" {
-		result = filter.Sort(result, sortField, sortAsc)
+
+	parsed, err := parseParams[T](params, o)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return filter.Optimize(filter.And(compileFilters[T](parsed)...)), nil
 }
 
 // ApplyPaginated filters, sorts, and paginates a slice based on URL query parameters.
@@ -152,6 +303,12 @@ func Apply[T any](items []T, params url.Values, opts ...Option) ([]T, error) {
 //   - page=N  → page number (1-based, default: 1)
 //   - limit=N → items per page (default: 20)
 //
+// With count_only=true, only Total is computed: matches are counted without
+// being collected, sorted or paginated, and Items is empty.
+//
+// In builds with the gofilter_debug tag, explain=true also attaches an
+// Explanation of the query execution to the result (see Explain).
+//
 // Example:
 //
 //	// GET /users?city=SP&sort=-age&page=2&limit=10
@@ -162,6 +319,17 @@ func Apply[T any](items []T, params url.Values, opts ...Option) ([]T, error) {
 //	// result.Total contains the total count matching the filter
 //	// result.HasNext indicates if there are more pages
 func ApplyPaginated[T any](items []T, params url.Values, opts ...Option) (*PageResult[T], error) {
+	return ApplyPaginatedContext(context.Background(), items, params, opts...)
+}
+
+// ApplyPaginatedContext is like ApplyPaginated, but stops filtering and
+// sorting soon after ctx is canceled or its deadline passes, and returns the
+// context's error.
+//
+// Example:
+//
+//	result, err := query.ApplyPaginatedContext(r.Context(), users, r.URL.Query())
+func ApplyPaginatedContext[T any](ctx context.Context, items []T, params url.Values, opts ...Option) (*PageResult[T], error) {
 	o := defaultOptions()
 	for _, opt := range opts {
 		opt(&o)
@@ -172,52 +340,243 @@ func ApplyPaginated[T any](items []T, params url.Values, opts ...Option) (*PageR
 		return nil, err
 	}
 
-	result := items
-	if len(parsed.filters) > 0 {
-		filters := make([]filter.Filter[T], 0, len(parsed.filters))
-		for _, pf := range parsed.filters {
-			f := buildFilter[T](pf)
-			filters = append(filters, f)
+	ctx, cancel := o.budget(ctx)
+	defer cancel()
+
+	if parsed.countOnly {
+		total, err := count(ctx, items, parsed, o)
+		if err != nil {
+			return nil, err
 		}
-		result = filter.Apply(result, filter.And(filters...))
+		return countPage[T](total, parsed, o), nil
 	}
 
-	sortField := parsed.sortField
-	sortAsc := parsed.sortAsc
-	if sortField == "" && o.defaultSort != "" {
-		sortField = o.defaultSort
-		sortAsc = o.defaultSortAsc
-	}
-	if sortField != "" {
-		result = filter.Sort(result, sortField, sortAsc)
+	if parsed.explain {
+		page, explanation, err := explain(ctx, items, parsed, o)
+		if err != nil {
+			return nil, err
+		}
+		page.Explain = explanation
+		return page, nil
 	}
 
-	total := len(result)
-	page := parsed.page
-	limit := parsed.limit
-	if limit <= 0 {
-		limit = o.defaultLimit
+	result, total, err := execute(ctx, items, parsed, o, parsed.pageEnd(o))
+	if err != nil {
+		return nil, err
 	}
+	return paginate(result, total, parsed, o), nil
+}
 
-	start := (page - 1) * limit
-	if start > total {
-		start = total
-	}
-	end := start + limit
-	if end > total {
-		end = total
+// budget returns a context that is canceled with ErrQueryBudgetExceeded once
+// the WithTimeout budget runs out.
+func (o options) budget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout <= 0 {
+		return ctx, func() {}
 	}
+	return context.WithTimeoutCause(ctx, o.timeout, &ErrQueryBudgetExceeded{Budget: "time", Limit: o.timeout.String()})
+}
+
+// paginate returns the requested page of the query results. result holds at
+// least the items up to the end of the page, in order, out of total results.
+func paginate[T any](result []T, total int, parsed *parsedQuery, o options) *PageResult[T] {
+	page := parsed.page
+	limit := parsed.limitOrDefault(o)
+
+	start := min((page-1)*limit, len(result))
+	end := min(start+limit, len(result))
 
 	return &PageResult[T]{
 		Items:   result[start:end],
 		Total:   total,
 		Page:    page,
 		Limit:   limit,
-		HasNext: end < total,
-	}, nil
+		HasNext: page*limit < total,
+	}
+}
+
+// countPage returns the result of a count_only query
+func countPage[T any](total int, parsed *parsedQuery, o options) *PageResult[T] {
+	return &PageResult[T]{
+		Items: []T{},
+		Total: total,
+		Page:  parsed.page,
+		Limit: parsed.limitOrDefault(o),
+	}
+}
+
+// count returns the number of results of a parsed query without collecting
+// them, unless distinct needs the matching items.
+func count[T any](ctx context.Context, items []T, parsed *parsedQuery, o options) (int, error) {
+	if parsed.distinctField != "" {
+		filtered, err := filterItems(ctx, items, parsed, o)
+		if err != nil {
+			return 0, err
+		}
+		return len(filter.DistinctOn(filtered, parsed.distinctField)), nil
+	}
+
+	f, set, err := queryFilter(items, parsed, o)
+	if err != nil {
+		return 0, err
+	}
+	if f == nil {
+		return len(items), nil
+	}
+
+	if set != nil {
+		if err := context.Cause(ctx); err != nil {
+			return 0, err
+		}
+		return len(set.Apply(f)), nil
+	}
+
+	total := 0
+	for start := 0; start < len(items); start += 1024 {
+		if err := context.Cause(ctx); err != nil {
+			return 0, err
+		}
+		total += filter.Count(items[start:min(start+1024, len(items))], f)
+	}
+	return total, nil
+}
+
+// execute runs the filter, search, sort and distinct stages of a parsed
+// query. It returns the results in order, or only the first needed of them
+// when needed is not negative, and the total number of results.
+func execute[T any](ctx context.Context, items []T, parsed *parsedQuery, o options, needed int) ([]T, int, error) {
+	filtered, err := filterItems(ctx, items, parsed, o)
+	if err != nil {
+		return nil, 0, err
+	}
+	return order(ctx, filtered, parsed, o, needed)
+}
+
+// filterItems runs the filter stage of a parsed query, using the index set
+// of the options when there is one.
+func filterItems[T any](ctx context.Context, items []T, parsed *parsedQuery, o options) ([]T, error) {
+	f, set, err := queryFilter(items, parsed, o)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return items, nil
+	}
+
+	if set != nil {
+		if err := context.Cause(ctx); err != nil {
+			return nil, err
+		}
+		return set.Apply(f), nil
+	}
+	return filter.ApplyContext(ctx, items, f, filter.Workers(o.parallelism))
+}
+
+// queryFilter returns the optimized filter of a parsed query, or nil when it
+// has none, with the index set of the options. It enforces WithMaxScanned.
+func queryFilter[T any](items []T, parsed *parsedQuery, o options) (filter.Filter[T], *index.Set[T], error) {
+	set, _ := o.index.(*index.Set[T])
+	if set != nil && set.Len() != len(items) {
+		return nil, nil, &ErrStaleIndex{Indexed: set.Len(), Items: len(items)}
+	}
+
+	filters := compileFilters[T](parsed)
+	if len(filters) == 0 {
+		return nil, nil, nil
+	}
+
+	f := filter.Optimize(filter.And(filters...))
+
+	if o.maxScanned > 0 {
+		scanned := len(items)
+		if set != nil {
+			if plan := set.Plan(f); plan.Indexed {
+				scanned = plan.Candidates
+			}
+		}
+		if scanned > o.maxScanned {
+			return nil, nil, &ErrQueryBudgetExceeded{Budget: "scanned items", Limit: strconv.Itoa(o.maxScanned)}
+		}
+	}
+
+	return f, set, nil
+}
+
+// order runs the sort and distinct stages of a parsed query and returns the
+// results with the total number of results. When needed is not negative and
+// the sort is the last stage, only the first needed results are selected,
+// with a partial sort.
+func order[T any](ctx context.Context, result []T, parsed *parsedQuery, o options, needed int) ([]T, int, error) {
+	var err error
+	total := len(result)
+
+	sortBy := func(keys ...filter.SortKey[T]) ([]T, error) {
+		if needed >= 0 && parsed.distinctField == "" {
+			return filter.TopKContext(ctx, result, needed, keys...)
+		}
+		return filter.SortByContext(ctx, result, keys...)
+	}
+
+	sortField := parsed.sortField
+	sortAsc := parsed.sortAsc
+	if sortField == "" && !parsed.sortScore && o.defaultSort != "" {
+		sortField = o.defaultSort
+		sortAsc = o.defaultSortAsc
+	}
+	if parsed.sortScore && parsed.search != "" {
+		if err := context.Cause(ctx); err != nil {
+			return nil, 0, err
+		}
+		ranked := filter.Rank(result, parsed.search, parsed.searchFields...)
+		result = make([]T, len(ranked))
+		for i, r := range ranked {
+			result[i] = r.Item
+		}
+	} else if parsed.sortScore {
+		fuzzy := parsed.fuzzyFilters()
+		keys := make([]filter.SortKey[T], 0, len(fuzzy))
+		for _, pf := range fuzzy {
+			keys = append(keys, filter.ClosestTo[T](pf.field, pf.value.(string)))
+		}
+		result, err = sortBy(keys...)
+	} else if sortField != "" {
+		key := filter.Asc[T](sortField)
+		if !sortAsc {
+			key = filter.Desc[T](sortField)
+		}
+		if parsed.sortDates != nil {
+			key = key.ByDate(*parsed.sortDates)
+		}
+		result, err = sortBy(key)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if parsed.distinctField != "" {
+		result = filter.DistinctOn(result, parsed.distinctField)
+		total = len(result)
+	}
+
+	return result, total, nil
+}
+
+// compileFilters builds the filters of a parsed query, including full-text search.
+func compileFilters[T any](parsed *parsedQuery) []filter.Filter[T] {
+	filters := make([]filter.Filter[T], 0, len(parsed.filters)+1)
+	for _, pf := range parsed.filters {
+		filters = append(filters, buildFilter[T](pf))
+	}
+	if parsed.search != "" {
+		filters = append(filters, filter.Search[T](parsed.search, parsed.searchFields...))
+	}
+	return filters
 }
 
 func buildFilter[T any](pf parsedFilter) filter.Filter[T] {
+	if pf.dates != nil {
+		return buildDateFilter[T](pf)
+	}
+
 	switch pf.operator {
 	case "eq":
 		return filter.Eq[T](pf.field, pf.value)
@@ -233,6 +592,12 @@ func buildFilter[T any](pf parsedFilter) filter.Filter[T] {
 		return filter.Lte[T](pf.field, pf.value)
 	case "contains":
 		return filter.Contains[T](pf.field, pf.value)
+	case "fuzzy":
+		term, ok := pf.value.(string)
+		if !ok {
+			return filter.FilterFunc[T](func(T) bool { return false })
+		}
+		return filter.Fuzzy[T](pf.field, term, filter.AutoDistance(term))
 	case "in":
 		vals, ok := pf.value.([]interface{})
 		if !ok {
@@ -245,6 +610,67 @@ func buildFilter[T any](pf parsedFilter) filter.Filter[T] {
 			return filter.FilterFunc[T](func(T) bool { return false })
 		}
 		return filter.Between[T](pf.field, vals[0], vals[1])
+	case "year", "month", "weekday", "day", "hour", "minute":
+		c, ok := pf.value.(filter.DatePartCondition)
+		if !ok {
+			return filter.FilterFunc[T](func(T) bool { return false })
+		}
+		return filter.DatePartIn[T](pf.field, c.Part, c.Location, c.Op, c.Value)
+	default:
+		return filter.FilterFunc[T](func(T) bool { return false })
+	}
+}
+
+// buildDateFilter builds the filter of a comparison on a string field holding
+// dates, which compares the times the strings hold
+func buildDateFilter[T any](pf parsedFilter) filter.Filter[T] {
+	opts := *pf.dates
+	at := func(value interface{}) filter.Filter[T] {
+		t, _ := value.(time.Time)
+		return filter.DateRange[T](pf.field, filter.TimeRange{Start: t, End: t}, opts)
+	}
+
+	switch pf.operator {
+	case "eq":
+		return at(pf.value)
+	case "ne":
+		return filter.Not(at(pf.value))
+	case "in":
+		vals, _ := pf.value.([]interface{})
+		filters := make([]filter.Filter[T], len(vals))
+		for i, v := range vals {
+			filters[i] = at(v)
+		}
+		return filter.Or(filters...)
+	case "between":
+		vals, ok := pf.value.([2]interface{})
+		if !ok {
+			return filter.FilterFunc[T](func(T) bool { return false })
+		}
+		start, _ := vals[0].(time.Time)
+		end, _ := vals[1].(time.Time)
+		return filter.DateBetween[T](pf.field, start, end, opts)
+	case "year", "month", "weekday", "day", "hour", "minute":
+		c, ok := pf.value.(filter.DatePartCondition)
+		if !ok {
+			return filter.FilterFunc[T](func(T) bool { return false })
+		}
+		return filter.DatePartIn[T](pf.field, c.Part, c.Location, c.Op, c.Value, opts)
+	}
+
+	t, ok := pf.value.(time.Time)
+	if !ok {
+		return filter.FilterFunc[T](func(T) bool { return false })
+	}
+	switch pf.operator {
+	case "gt":
+		return filter.DateAfter[T](pf.field, t, opts)
+	case "gte":
+		return filter.DateRange[T](pf.field, filter.TimeRange{Start: t}, opts)
+	case "lt":
+		return filter.DateBefore[T](pf.field, t, opts)
+	case "lte":
+		return filter.DateRange[T](pf.field, filter.TimeRange{End: t}, opts)
 	default:
 		return filter.FilterFunc[T](func(T) bool { return false })
 	}
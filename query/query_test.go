@@ -1,8 +1,16 @@
 package query
 
 import (
+	"context"
+	"errors"
 	"net/url"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/sidneip/gofilter/filter"
+	"github.com/sidneip/gofilter/index"
 )
 
 type User struct {
@@ -11,6 +19,20 @@ type User struct {
 	City string `gofilter:"filterable,sortable"`
 }
 
+type Place struct {
+	Name string `gofilter:"filterable,sortable,searchable"`
+	Bio  string `gofilter:"searchable"`
+	City string `gofilter:"filterable"`
+}
+
+func testPlaces() []Place {
+	return []Place{
+		{Name: "Café Paulista", Bio: "Coffee in São Paulo", City: "SP"},
+		{Name: "Bar do Zé", Bio: "Drinks and snacks", City: "RJ"},
+		{Name: "São Paulo Grill", Bio: "Steaks from São Paulo, for São Paulo", City: "SP"},
+	}
+}
+
 func testUsers() []User {
 	return []User{
 		{Name: "Ana", Age: 20, City: "SP"},
@@ -248,3 +270,522 @@ func TestApplyMaxLimit(t *testing.T) {
 		t.Fatal("expected error for limit > maxLimit")
 	}
 }
+
+func TestApplyDistinct(t *testing.T) {
+	params := url.Values{"distinct": {"city"}, "sort": {"-age"}}
+	result, err := Apply(testUsers(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 3 {
+		t.Fatalf("expected 3 users (one per city), got %d", len(result))
+	}
+	if result[0].Name != "Daniel" || result[1].Name != "Carla" || result[2].Name != "Elena" {
+		t.Errorf("expected oldest user of each city, got %v", result)
+	}
+}
+
+func TestApplyPaginatedDistinct(t *testing.T) {
+	params := url.Values{"distinct": {"city"}, "limit": {"2"}}
+	page, err := ApplyPaginated(testUsers(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 {
+		t.Errorf("expected total 3 distinct cities, got %d", page.Total)
+	}
+	if !page.HasNext {
+		t.Error("expected HasNext to be true")
+	}
+}
+
+func TestApplySearch(t *testing.T) {
+	params := url.Values{"q": {"sao paulo"}}
+	result, err := Apply(testPlaces(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Errorf("expected 2 places matching 'sao paulo', got %d", len(result))
+	}
+}
+
+func TestApplySearchSortScore(t *testing.T) {
+	params := url.Values{"q": {"sao paulo"}, "sort": {"_score"}}
+	result, err := Apply(testPlaces(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 places, got %d", len(result))
+	}
+	if result[0].Name != "São Paulo Grill" {
+		t.Errorf("expected São Paulo Grill to rank first, got %s", result[0].Name)
+	}
+}
+
+func TestApplySortScoreWithoutSearch(t *testing.T) {
+	params := url.Values{"sort": {"_score"}}
+	_, err := Apply(testPlaces(), params)
+	if _, ok := err.(*ErrInvalidValue); !ok {
+		t.Errorf("expected ErrInvalidValue, got %T: %v", err, err)
+	}
+}
+
+func TestApplySearchWithoutSearchableFields(t *testing.T) {
+	params := url.Values{"q": {"ana"}}
+	_, err := Apply(testUsers(), params)
+	if _, ok := err.(*ErrFieldNotFilterable); !ok {
+		t.Errorf("expected ErrFieldNotFilterable, got %T: %v", err, err)
+	}
+}
+
+func TestApplySearchableOnlyFieldNotFilterable(t *testing.T) {
+	params := url.Values{"bio": {"Drinks"}}
+	_, err := Apply(testPlaces(), params)
+	if _, ok := err.(*ErrFieldNotFilterable); !ok {
+		t.Errorf("expected ErrFieldNotFilterable, got %T: %v", err, err)
+	}
+}
+
+func TestApplyFuzzy(t *testing.T) {
+	params := url.Values{"name_fuzzy": {"carlla"}}
+	result, err := Apply(testUsers(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].Name != "Carla" {
+		t.Errorf("expected Carla, got %v", result)
+	}
+}
+
+func TestApplyFuzzySortScore(t *testing.T) {
+	users := []User{
+		{Name: "Anne", City: "SP"},
+		{Name: "Bruno", City: "SP"},
+		{Name: "Anna", City: "RJ"},
+	}
+	params := url.Values{"name_fuzzy": {"anna"}, "sort": {"_score"}}
+	result, err := Apply(users, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(result))
+	}
+	if result[0].Name != "Anna" {
+		t.Errorf("expected exact match Anna first, got %s", result[0].Name)
+	}
+}
+
+func TestApplyFuzzyNonStringField(t *testing.T) {
+	params := url.Values{"age_fuzzy": {"20"}}
+	_, err := Apply(testUsers(), params)
+	if _, ok := err.(*ErrInvalidValue); !ok {
+		t.Errorf("expected ErrInvalidValue, got %T: %v", err, err)
+	}
+}
+
+func TestApplyWithIndex(t *testing.T) {
+	users := testUsers()
+	byCity, err := index.NewHashIndex(users, "City")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := index.NewSet(users, byCity)
+
+	params := url.Values{"city": {"SP"}, "age_gt": {"20"}}
+	result, err := Apply(users, params, WithIndex(set))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].Name != "Carla" {
+		t.Errorf("expected Carla, got %v", result)
+	}
+
+	places := index.NewSet(testPlaces())
+	_, err = Apply(users, params, WithIndex(places))
+	if _, ok := err.(*ErrIndexMismatch); !ok {
+		t.Errorf("expected ErrIndexMismatch for a set of places, got %T: %v", err, err)
+	}
+
+	grown := append(users, User{Name: "Zed", City: "SP", Age: 50})
+	_, err = Apply(grown, params, WithIndex(set))
+	if _, ok := err.(*ErrStaleIndex); !ok {
+		t.Errorf("expected ErrStaleIndex for a set built before Zed was added, got %T: %v", err, err)
+	}
+}
+
+func TestApplyWithParallelism(t *testing.T) {
+	users := generateUsers(25_000)
+	params := url.Values{"city": {"SP"}, "age_gte": {"40"}, "page": {"3"}, "limit": {"50"}}
+
+	want, err := ApplyPaginated(users, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ApplyPaginated(users, params, WithParallelism(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Total != want.Total || len(got.Items) != len(want.Items) {
+		t.Fatalf("expected %d of %d items, got %d of %d", len(want.Items), want.Total, len(got.Items), got.Total)
+	}
+	for i := range got.Items {
+		if got.Items[i] != want.Items[i] {
+			t.Errorf("expected %v at %d, got %v", want.Items[i], i, got.Items[i])
+		}
+	}
+}
+
+func TestApplyContext(t *testing.T) {
+	params := url.Values{"city": {"SP"}, "sort": {"-age"}}
+	result, err := ApplyContext(context.Background(), testUsers(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].Name != "Carla" {
+		t.Errorf("expected Carla first, got %v", result)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ApplyContext(ctx, testUsers(), params); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := ApplyPaginatedContext(ctx, testUsers(), params); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWithMaxScanned(t *testing.T) {
+	users := testUsers()
+	params := url.Values{"city": {"SP"}}
+
+	_, err := Apply(users, params, WithMaxScanned(4))
+	var budget *ErrQueryBudgetExceeded
+	if !errors.As(err, &budget) || budget.Budget != "scanned items" || budget.Limit != "4" {
+		t.Errorf("expected a scanned items budget error, got %v", err)
+	}
+
+	if _, err := Apply(users, params, WithMaxScanned(5)); err != nil {
+		t.Errorf("expected 5 items to fit the budget, got %v", err)
+	}
+
+	// Only the index candidates are scanned
+	byCity, err := index.NewHashIndex(users, "City")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Apply(users, params, WithIndex(index.NewSet(users, byCity)), WithMaxScanned(2))
+	if err != nil || len(result) != 2 {
+		t.Errorf("expected 2 users within the budget, got %v (%v)", result, err)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	users := generateUsers(500_000)
+	params := url.Values{"city": {"SP"}, "sort": {"-score"}}
+
+	_, err := ApplyPaginated(users, params, WithTimeout(time.Microsecond))
+	var budget *ErrQueryBudgetExceeded
+	if !errors.As(err, &budget) || budget.Budget != "time" {
+		t.Errorf("expected a time budget error, got %v", err)
+	}
+
+	if _, err := ApplyPaginated(users[:1000], params, WithTimeout(time.Minute)); err != nil {
+		t.Errorf("expected the query to fit the budget, got %v", err)
+	}
+}
+
+func TestApplyPaginatedPartialSort(t *testing.T) {
+	users := generateUsers(2000)
+
+	for _, params := range []url.Values{
+		{"sort": {"-score"}, "page": {"1"}, "limit": {"10"}},
+		{"sort": {"age"}, "city": {"SP"}, "page": {"3"}, "limit": {"25"}},
+		{"sort": {"-age"}, "page": {"200"}, "limit": {"10"}},
+		{"sort": {"-age"}, "page": {"500"}, "limit": {"10"}},
+		{"sort": {"-age"}, "distinct": {"city"}, "limit": {"2"}},
+	} {
+		all, err := Apply(users, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := ApplyPaginated(users, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := min((page.Page-1)*page.Limit, len(all))
+		end := min(start+page.Limit, len(all))
+		if page.Total != len(all) || page.HasNext != (end < len(all)) {
+			t.Errorf("%v: expected total %d, got %d (has next %v)", params, len(all), page.Total, page.HasNext)
+		}
+		if len(page.Items) != end-start {
+			t.Errorf("%v: expected %d items, got %d", params, end-start, len(page.Items))
+			continue
+		}
+		for i, item := range page.Items {
+			if item != all[start+i] {
+				t.Errorf("%v: expected %v at %d, got %v", params, all[start+i], i, item)
+			}
+		}
+	}
+}
+
+func TestApplyPaginatedCountOnly(t *testing.T) {
+	users := generateUsers(3000)
+	byCity, err := index.NewHashIndex(users, "City")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := index.NewSet(users, byCity)
+
+	for _, params := range []url.Values{
+		{},
+		{"city": {"SP"}, "age_gte": {"40"}},
+		{"sort": {"-age"}, "distinct": {"city"}},
+	} {
+		want, err := ApplyPaginated(users, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counted := url.Values{"count_only": {"true"}}
+		for key, values := range params {
+			counted[key] = values
+		}
+		for _, opts := range [][]Option{nil, {WithIndex(set)}} {
+			got, err := ApplyPaginated(users, counted, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Total != want.Total || len(got.Items) != 0 || got.Items == nil {
+				t.Errorf("%v: expected a total of %d and no items, got %d and %d items", params, want.Total, got.Total, len(got.Items))
+			}
+		}
+	}
+
+	_, err = ApplyPaginated(users, url.Values{"count_only": {"maybe"}})
+	if _, ok := err.(*ErrInvalidValue); !ok {
+		t.Errorf("expected ErrInvalidValue for count_only=maybe, got %T: %v", err, err)
+	}
+}
+
+func TestWithJSONColumns(t *testing.T) {
+	type Event struct {
+		Title     string `json:"title" gofilter:"filterable"`
+		StartHour int    `json:"startHour" gofilter:"filterable,sortable"`
+	}
+	events := []Event{{"Standup", 9}, {"Lunch", 12}, {"Retro", 16}}
+
+	params := url.Values{"startHour_gte": {"10"}, "sort": {"-startHour"}}
+	result, err := Apply(events, params, WithJSONColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].Title != "Retro" || result[1].Title != "Lunch" {
+		t.Errorf("expected Retro and Lunch, got %v", result)
+	}
+
+	if _, err := Apply(events, params); err == nil {
+		t.Error("expected json names to be rejected without WithJSONColumns")
+	}
+}
+
+func TestRelativeDates(t *testing.T) {
+	type Post struct {
+		Title   string    `gofilter:"filterable"`
+		Created time.Time `gofilter:"filterable,sortable"`
+	}
+	now := time.Date(2024, 3, 15, 1, 30, 0, 0, time.UTC)
+	posts := []Post{
+		{"Old", now.AddDate(0, -2, 0)},
+		{"Last week", now.AddDate(0, 0, -6)},
+		{"Yesterday", now.Add(-20 * time.Hour)},
+		{"Tonight", now.Add(-1 * time.Hour)},
+	}
+	clock := WithClock(func() time.Time { return now })
+
+	tests := []struct {
+		params url.Values
+		opts   []Option
+		want   int
+	}{
+		{url.Values{"created_gte": {"now-7d"}}, nil, 3},
+		{url.Values{"created_gte": {"now-P1M"}}, nil, 3},
+		{url.Values{"created_gte": {"today"}}, nil, 1},
+		{url.Values{"created_gte": {"startOf(month)"}}, nil, 3},
+		{url.Values{"created_lt": {"2024-01-31"}}, nil, 1},
+		{url.Values{"created_between": {"now-P10D,yesterday"}}, nil, 1},
+		// At 01:30 UTC it is still the 14th in São Paulo
+		{url.Values{"created_gte": {"today"}, "tz": {"America/Sao_Paulo"}}, nil, 2},
+		{url.Values{"created_gte": {"today"}}, []Option{WithLocation(time.FixedZone("UTC-3", -3*60*60))}, 2},
+	}
+
+	for _, tt := range tests {
+		result, err := Apply(posts, tt.params, append(tt.opts, clock)...)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", tt.params, err)
+			continue
+		}
+		if len(result) != tt.want {
+			t.Errorf("%v: expected %d posts, got %v", tt.params, tt.want, result)
+		}
+	}
+
+	_, err := Apply(posts, url.Values{"tz": {"Mars/Olympus"}})
+	if _, ok := err.(*ErrInvalidValue); !ok {
+		t.Errorf("expected ErrInvalidValue for unknown time zone, got %T: %v", err, err)
+	}
+
+	_, err = Apply(posts, url.Values{"created_gte": {"now-7q"}})
+	if _, ok := err.(*ErrInvalidValue); !ok {
+		t.Errorf("expected ErrInvalidValue for unknown unit, got %T: %v", err, err)
+	}
+}
+
+func TestDatePartOperators(t *testing.T) {
+	type Shift struct {
+		Name     string    `gofilter:"filterable"`
+		Start    time.Time `gofilter:"filterable"`
+		StartDay int       `gofilter:"filterable"` // day of the rotation
+	}
+	shifts := []Shift{
+		{"saturday night", time.Date(2024, 3, 16, 23, 0, 0, 0, time.UTC), 1},
+		{"monday early", time.Date(2024, 3, 18, 2, 0, 0, 0, time.UTC), 2},
+		{"monday morning", time.Date(2024, 3, 18, 9, 30, 0, 0, time.UTC), 2},
+		{"april", time.Date(2024, 4, 3, 14, 0, 0, 0, time.UTC), 3},
+	}
+
+	tests := []struct {
+		params url.Values
+		want   int
+	}{
+		{url.Values{"start_weekday": {"sat,sun"}}, 1},
+		{url.Values{"start_weekday": {"Mon-Fri"}}, 3},
+		{url.Values{"start_weekday": {"1"}}, 2},
+		{url.Values{"start_month": {"apr"}}, 1},
+		{url.Values{"start_year": {"2020-2024"}}, 4},
+		{url.Values{"start_hour": {"22-2"}}, 2},
+		{url.Values{"start_hour": {"9-17"}, "start_minute": {"30"}}, 1},
+		// In São Paulo, the Monday 02:00 UTC shift starts on Sunday at 23:00
+		{url.Values{"start_weekday": {"sun"}, "tz": {"America/Sao_Paulo"}}, 1},
+		{url.Values{"start_hour": {"23"}, "tz": {"America/Sao_Paulo"}}, 1},
+		// start_day is a column, not the day of start
+		{url.Values{"start_day": {"3"}}, 1},
+		{url.Values{"start_day_in": {"1,2"}}, 3},
+	}
+
+	for _, tt := range tests {
+		result, err := Apply(shifts, tt.params)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", tt.params, err)
+			continue
+		}
+		if len(result) != tt.want {
+			t.Errorf("%v: expected %d shifts, got %v", tt.params, tt.want, result)
+		}
+	}
+
+	for _, params := range []url.Values{
+		{"start_weekday": {"someday"}},
+		{"start_hour": {"24"}},
+		{"start_year": {"2024-2020"}},
+		{"start_year": {strings.Repeat("1-9999,", maxDatePartItems) + "1"}},
+		{"name_weekday": {"mon"}},
+	} {
+		_, err := Apply(shifts, params)
+		if _, ok := err.(*ErrInvalidValue); !ok {
+			t.Errorf("%v: expected ErrInvalidValue, got %T: %v", params, err, err)
+		}
+	}
+}
+
+func TestDateLayouts(t *testing.T) {
+	type Invoice struct {
+		Number   int    `gofilter:"filterable,sortable"`
+		IssuedOn string `gofilter:"filterable,sortable,layout=02/01/2006"`
+	}
+	invoices := []Invoice{
+		{1, "05/03/2024"}, // Tuesday
+		{2, "16/03/2024"}, // Saturday
+		{3, "not a date"},
+		{4, "01/02/2024"}, // Thursday
+		{5, "15/03/2024"},
+	}
+	clock := WithClock(func() time.Time { return time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC) })
+
+	tests := []struct {
+		params url.Values
+		want   []int
+	}{
+		{url.Values{"issued_on": {"05/03/2024"}}, []int{1}},
+		{url.Values{"issued_on_gte": {"05/03/2024"}}, []int{1, 2, 5}},
+		{url.Values{"issued_on_lt": {"2024-03-05"}}, []int{4}},
+		{url.Values{"issued_on_between": {"01/03/2024,15/03/2024"}}, []int{1, 5}},
+		{url.Values{"issued_on_in": {"01/02/2024,16/03/2024"}}, []int{2, 4}},
+		{url.Values{"issued_on_ne": {"05/03/2024"}}, []int{2, 3, 4, 5}},
+		{url.Values{"issued_on_gte": {"now-7d"}}, []int{2, 5}},
+		{url.Values{"issued_on_weekday": {"sat"}}, []int{2}},
+		{url.Values{"issued_on_contains": {"/03/"}}, []int{1, 2, 5}},
+		{url.Values{"sort": {"issued_on"}}, []int{4, 1, 5, 2, 3}},
+		{url.Values{"sort": {"-issued_on"}}, []int{2, 5, 1, 4, 3}},
+	}
+
+	for _, tt := range tests {
+		result, err := Apply(invoices, tt.params, clock)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", tt.params, err)
+			continue
+		}
+		var got []int
+		for _, inv := range result {
+			got = append(got, inv.Number)
+		}
+		if len(tt.params["sort"]) == 0 {
+			slices.Sort(got)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%v: expected invoices %v, got %v", tt.params, tt.want, got)
+		}
+	}
+
+	_, err := Apply(invoices, url.Values{"issued_on_gte": {"32/13/2024"}})
+	if _, ok := err.(*ErrInvalidValue); !ok {
+		t.Errorf("expected ErrInvalidValue for a bad date, got %T: %v", err, err)
+	}
+}
+
+func TestBuildFilter(t *testing.T) {
+	params := url.Values{"city": {"SP"}, "age_gt": {"20"}, "sort": {"-age"}}
+	f, err := BuildFilter[User](params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := filter.Apply(testUsers(), f)
+	if len(result) != 1 || result[0].Name != "Carla" {
+		t.Errorf("expected Carla, got %v", result)
+	}
+
+	if _, err := BuildFilter[User](url.Values{"email": {"x"}}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestBuildFilterOptimized(t *testing.T) {
+	f, err := BuildFilter[User](url.Values{"age_gte": {"18"}, "age_lte": {"25"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := filter.Describe(f)
+	if e.Op != filter.OpAnd || len(e.Children) != 2 || e.Children[0].Field != "Age" || e.Children[1].Field != "Age" {
+		t.Errorf("expected a single range on Age, got %v", e)
+	}
+	if got := filter.Apply(testUsers(), f); len(got) != 3 {
+		t.Errorf("expected 3 users between 18 and 25, got %v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// Schema describes the query fields of T so that queries can be parsed and
+// run without reflection. Schemas are generated from gofilter struct tags by
+// cmd/gofilter-gen:
+//
+//	//go:generate go run github.com/sidneip/gofilter/cmd/gofilter-gen -type=User
+//
+// Types without a registered schema are read with reflection.
+type Schema[T any] struct {
+	// Fields lists the tagged fields of T in declaration order
+	Fields []SchemaField[T]
+}
+
+// SchemaField describes one field of a Schema.
+type SchemaField[T any] struct {
+	// Name is the struct field name
+	Name string
+	// Column is the query parameter name of the field
+	Column string
+	// Type is the type of the field
+	Type reflect.Type
+	// Filterable, Sortable and Searchable mirror the gofilter tag options
+	Filterable bool
+	Sortable   bool
+	Searchable bool
+	// Layouts are the layout= tag options of a string field holding dates
+	Layouts []string
+	// Coerce converts a query parameter value to Type. When nil, values are
+	// converted with reflection.
+	Coerce func(raw string) (interface{}, error)
+	// Accessor reads and compares the field without reflection. It is
+	// registered with filter.RegisterAccessors when its Get is set.
+	Accessor filter.Accessor[T]
+}
+
+// schemas holds the field registry built from each registered schema
+var schemas sync.Map // reflect.Type -> *fieldRegistry
+
+// RegisterSchema registers the schema of T. Apply, ApplyPaginated, BuildFilter
+// and Explain use it instead of reading struct tags, and the filters they
+// build use its accessors instead of reflection. Generated code calls it from
+// an init function; registering a type again replaces its schema.
+func RegisterSchema[T any](s Schema[T]) {
+	reg := &fieldRegistry{
+		byColumn: make(map[string]fieldInfo),
+	}
+	fieldAccessors := make(map[string]filter.Accessor[T])
+
+	for _, f := range s.Fields {
+		if f.Accessor.Get != nil {
+			fieldAccessors[f.Name] = f.Accessor
+		}
+
+		info := fieldInfo{
+			structField: f.Name,
+			column:      f.Column,
+			filterable:  f.Filterable,
+			sortable:    f.Sortable,
+			searchable:  f.Searchable,
+			fieldType:   f.Type,
+			coerce:      f.Coerce,
+			layouts:     f.Layouts,
+		}
+		reg.add(info)
+	}
+
+	filter.RegisterAccessors(fieldAccessors)
+	schemas.Store(reflect.TypeFor[T](), reg)
+}
+
+// registeredSchema returns the field registry of T's registered schema
+func registeredSchema[T any]() (*fieldRegistry, bool) {
+	reg, ok := schemas.Load(reflect.TypeFor[T]())
+	if !ok {
+		return nil, false
+	}
+	return reg.(*fieldRegistry), true
+}
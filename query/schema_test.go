@@ -0,0 +1,137 @@
+package query
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// SchemaUser mirrors User with a registered schema, in the shape gofilter-gen emits
+type SchemaUser struct {
+	Name string `gofilter:"filterable,sortable,column=name"`
+	Age  int    `gofilter:"filterable,sortable"`
+	City string `gofilter:"filterable,sortable"`
+}
+
+// schemaCoercions counts the calls to the schema's Coerce functions
+var schemaCoercions int
+
+func init() {
+	RegisterSchema(Schema[SchemaUser]{
+		Fields: []SchemaField[SchemaUser]{
+			{
+				Name:       "Name",
+				Column:     "name",
+				Type:       reflect.TypeFor[string](),
+				Filterable: true,
+				Sortable:   true,
+				Coerce: func(raw string) (interface{}, error) {
+					schemaCoercions++
+					return raw, nil
+				},
+				Accessor: filter.Accessor[SchemaUser]{
+					Type:         reflect.TypeFor[string](),
+					Get:          func(item SchemaUser) interface{} { return item.Name },
+					Equal:        func(item SchemaUser, value interface{}) bool { return item.Name == value.(string) },
+					Compare:      func(item SchemaUser, value interface{}) int { return cmp.Compare(item.Name, value.(string)) },
+					CompareItems: func(a, b SchemaUser) int { return cmp.Compare(a.Name, b.Name) },
+				},
+			},
+			{
+				Name:       "Age",
+				Column:     "age",
+				Type:       reflect.TypeFor[int](),
+				Filterable: true,
+				Sortable:   true,
+				Coerce: func(raw string) (interface{}, error) {
+					schemaCoercions++
+					v, err := strconv.ParseInt(raw, 10, 64)
+					if err != nil {
+						return nil, fmt.Errorf("cannot parse %q as int: %w", raw, err)
+					}
+					return int(v), nil
+				},
+				Accessor: filter.Accessor[SchemaUser]{
+					Type:         reflect.TypeFor[int](),
+					Get:          func(item SchemaUser) interface{} { return item.Age },
+					Equal:        func(item SchemaUser, value interface{}) bool { return item.Age == value.(int) },
+					Compare:      func(item SchemaUser, value interface{}) int { return cmp.Compare(item.Age, value.(int)) },
+					CompareItems: func(a, b SchemaUser) int { return cmp.Compare(a.Age, b.Age) },
+				},
+			},
+			{
+				// No accessor: City is read with reflection
+				Name:       "City",
+				Column:     "city",
+				Type:       reflect.TypeFor[string](),
+				Filterable: true,
+				Sortable:   true,
+			},
+		},
+	})
+}
+
+func TestRegisterSchema(t *testing.T) {
+	users := testUsers()
+	schemaUsers := make([]SchemaUser, len(users))
+	for i, u := range users {
+		schemaUsers[i] = SchemaUser(u)
+	}
+
+	queries := []url.Values{
+		{"city": {"SP"}},
+		{"age_gt": {"20"}, "sort": {"-age"}},
+		{"age_between": {"18,25"}, "sort": {"name"}},
+		{"name_in": {"Ana,Elena"}, "city_ne": {"MG"}},
+		{"sort": {"city"}},
+	}
+
+	for _, params := range queries {
+		schemaCoercions = 0
+
+		want, err := Apply(users, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Apply(schemaUsers, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != len(want) {
+			t.Errorf("%v: expected %d users, got %d", params, len(want), len(got))
+			continue
+		}
+		for i := range got {
+			if User(got[i]) != want[i] {
+				t.Errorf("%v: expected %v at %d, got %v", params, want[i], i, got[i])
+			}
+		}
+	}
+
+	schemaCoercions = 0
+	if _, err := Apply(schemaUsers, url.Values{"age_in": {"20,22"}}); err != nil {
+		t.Fatal(err)
+	}
+	if schemaCoercions != 2 {
+		t.Errorf("expected the schema to coerce 2 values, got %d", schemaCoercions)
+	}
+
+	_, err := Apply(schemaUsers, url.Values{"age": {"old"}})
+	var invalid *ErrInvalidValue
+	if !errors.As(err, &invalid) || invalid.ExpectedType != "int" {
+		t.Errorf("expected ErrInvalidValue for int, got %v", err)
+	}
+
+	_, err = Apply(schemaUsers, url.Values{"email": {"x"}})
+	var notFilterable *ErrFieldNotFilterable
+	if !errors.As(err, &notFilterable) {
+		t.Errorf("expected ErrFieldNotFilterable, got %v", err)
+	}
+}
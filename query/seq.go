@@ -0,0 +1,148 @@
+//go:build go1.23
+
+package query
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+// ApplySeq is like Apply for items read from an iterator instead of a slice,
+// such as a generator, a database cursor or a file reader. Items are
+// filtered as they are read, so only matching items are kept in memory.
+//
+// WithIndex and WithParallelism do not apply to iterators; WithMaxScanned
+// counts the items read from seq.
+//
+// Example:
+//
+//	result, err := query.ApplySeq(readUsers(file), r.URL.Query())
+func ApplySeq[T any](seq iter.Seq[T], params url.Values, opts ...Option) ([]T, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	parsed, err := parseParams[T](params, o)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := o.budget(context.Background())
+	defer cancel()
+
+	matched, err := collectSeq(ctx, seq, parsed, o, -1)
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := order(ctx, matched, parsed, o, -1)
+	return result, err
+}
+
+// ApplyPaginatedSeq is like ApplyPaginated for items read from an iterator.
+// When the query has no sort, relevance ranking or distinct, it stops reading
+// seq as soon as the requested page and one more match have been found; since
+// the remaining items are never read, Total is then -1. Otherwise it reads
+// every item, like ApplySeq, and Total is the number of matches.
+// count_only=true reads every item and counts matches without keeping them.
+//
+// Example:
+//
+//	// GET /logs?level=error&limit=50
+//	page, err := query.ApplyPaginatedSeq(readLogs(file), r.URL.Query())
+func ApplyPaginatedSeq[T any](seq iter.Seq[T], params url.Values, opts ...Option) (*PageResult[T], error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	parsed, err := parseParams[T](params, o)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := o.budget(context.Background())
+	defer cancel()
+
+	if parsed.countOnly && parsed.distinctField == "" {
+		total := 0
+		err := scanSeq(ctx, seq, parsed, o, func(T) bool {
+			total++
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return countPage[T](total, parsed, o), nil
+	}
+
+	if parsed.sortField != "" || parsed.sortScore || parsed.distinctField != "" || o.defaultSort != "" {
+		matched, err := collectSeq(ctx, seq, parsed, o, -1)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.countOnly {
+			return countPage[T](len(filter.DistinctOn(matched, parsed.distinctField)), parsed, o), nil
+		}
+		ordered, total, err := order(ctx, matched, parsed, o, parsed.pageEnd(o))
+		if err != nil {
+			return nil, err
+		}
+		return paginate(ordered, total, parsed, o), nil
+	}
+
+	matched, err := collectSeq(ctx, seq, parsed, o, parsed.pageEnd(o)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// With one match past the page, seq was not read to the end
+	page := paginate(matched, len(matched), parsed, o)
+	if page.HasNext {
+		page.Total = -1
+	}
+	return page, nil
+}
+
+// collectSeq reads the items of seq that pass the query filters, stopping
+// after max matches when max is not negative.
+func collectSeq[T any](ctx context.Context, seq iter.Seq[T], parsed *parsedQuery, o options, max int) ([]T, error) {
+	matched := make([]T, 0)
+	err := scanSeq(ctx, seq, parsed, o, func(item T) bool {
+		matched = append(matched, item)
+		return len(matched) != max
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matched, nil
+}
+
+// scanSeq reads the items of seq and calls match with each item that passes
+// the query filters, until match returns false.
+func scanSeq[T any](ctx context.Context, seq iter.Seq[T], parsed *parsedQuery, o options, match func(item T) bool) error {
+	f := filter.Optimize(filter.And(compileFilters[T](parsed)...))
+
+	scanned := 0
+	for item := range seq {
+		if scanned%1024 == 0 {
+			if err := context.Cause(ctx); err != nil {
+				return err
+			}
+		}
+		scanned++
+		if o.maxScanned > 0 && scanned > o.maxScanned {
+			return &ErrQueryBudgetExceeded{Budget: "scanned items", Limit: strconv.Itoa(o.maxScanned)}
+		}
+
+		if f.Apply(item) && !match(item) {
+			break
+		}
+	}
+
+	return nil
+}
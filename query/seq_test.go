@@ -0,0 +1,128 @@
+//go:build go1.23
+
+package query
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+// countingSeq yields users and counts how many were read
+func countingSeq(users []BenchUser, read *int) func(yield func(BenchUser) bool) {
+	return func(yield func(BenchUser) bool) {
+		for _, u := range users {
+			*read++
+			if !yield(u) {
+				return
+			}
+		}
+	}
+}
+
+func TestApplySeq(t *testing.T) {
+	users := generateUsers(1000)
+	params := url.Values{"city": {"SP"}, "age_gte": {"30"}, "sort": {"-score"}}
+
+	want, err := Apply(users, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ApplySeq(slices.Values(users), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %d users in the order of Apply, got %d", len(want), len(got))
+	}
+
+	if _, err := ApplySeq(slices.Values(users), url.Values{"email": {"x"}}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestApplyPaginatedSeqStopsEarly(t *testing.T) {
+	users := generateUsers(1000)
+	params := url.Values{"city": {"SP"}, "page": {"2"}, "limit": {"10"}}
+
+	want, err := ApplyPaginated(users, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := 0
+	got, err := ApplyPaginatedSeq(countingSeq(users, &read), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(got.Items, want.Items) || !got.HasNext || got.Total != -1 {
+		t.Errorf("expected page 2 with more pages and an unknown total, got %+v", got)
+	}
+	// One SP user in five: 21 matches are found by the 101st user
+	if read != 101 {
+		t.Errorf("expected to stop after 101 users, read %d", read)
+	}
+}
+
+func TestApplyPaginatedSeqLastPage(t *testing.T) {
+	users := generateUsers(100)
+	params := url.Values{"city": {"SP"}, "page": {"2"}, "limit": {"15"}}
+
+	read := 0
+	got, err := ApplyPaginatedSeq(countingSeq(users, &read), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Items) != 5 || got.HasNext || got.Total != 20 || read != 100 {
+		t.Errorf("expected the last 5 of 20 users after reading all 100, got %d of %d after %d", len(got.Items), got.Total, read)
+	}
+}
+
+func TestApplyPaginatedSeqSorted(t *testing.T) {
+	users := generateUsers(1000)
+	params := url.Values{"city": {"SP"}, "sort": {"-age"}, "limit": {"5"}}
+
+	want, err := ApplyPaginated(users, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := 0
+	got, err := ApplyPaginatedSeq(countingSeq(users, &read), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Items, want.Items) || got.Total != want.Total || read != len(users) {
+		t.Errorf("expected sorted queries to read every item, got %+v after %d", got, read)
+	}
+}
+
+func TestApplySeqMaxScanned(t *testing.T) {
+	users := generateUsers(100)
+
+	_, err := ApplySeq(slices.Values(users), url.Values{"city": {"SP"}}, WithMaxScanned(50))
+	var budget *ErrQueryBudgetExceeded
+	if !errors.As(err, &budget) {
+		t.Errorf("expected ErrQueryBudgetExceeded, got %v", err)
+	}
+
+	if _, err := ApplyPaginatedSeq(slices.Values(users), url.Values{"city": {"SP"}, "limit": {"5"}}, WithMaxScanned(50)); err != nil {
+		t.Errorf("expected the first page to be found within the budget, got %v", err)
+	}
+}
+
+func TestApplyPaginatedSeqCountOnly(t *testing.T) {
+	users := generateUsers(1000)
+	params := url.Values{"city": {"SP"}, "limit": {"5"}, "count_only": {"true"}}
+
+	read := 0
+	got, err := ApplyPaginatedSeq(countingSeq(users, &read), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Total != 200 || len(got.Items) != 0 || read != 1000 {
+		t.Errorf("expected 200 matches and no items after reading all 1000 users, got %d and %d items after %d", got.Total, len(got.Items), read)
+	}
+}
@@ -3,7 +3,10 @@ package query
 import (
 	"reflect"
 	"strings"
+	"time"
 	"unicode"
+
+	"github.com/sidneip/gofilter/filter"
 )
 
 type fieldInfo struct {
@@ -11,15 +14,50 @@ type fieldInfo struct {
 	column      string
 	filterable  bool
 	sortable    bool
+	searchable  bool
 	fieldType   reflect.Type
+	coerce      func(raw string) (interface{}, error)
+	// layouts are the layouts of a string field holding dates
+	layouts []string
+}
+
+// dateOptions returns how filters read the field's strings as dates, in
+// time zone loc, or nil when the field has no layouts
+func (info fieldInfo) dateOptions(loc *time.Location) *filter.DateOptions {
+	if len(info.layouts) == 0 {
+		return nil
+	}
+	return &filter.DateOptions{Layouts: info.layouts, Location: loc}
 }
 
 type fieldRegistry struct {
-	fields   []fieldInfo
-	byColumn map[string]fieldInfo
+	fields     []fieldInfo
+	byColumn   map[string]fieldInfo
+	searchable []string
 }
 
-func parseStructTags[T any]() (*fieldRegistry, error) {
+// add registers a field, indexing it by column when it is filterable
+func (reg *fieldRegistry) add(info fieldInfo) {
+	if info.searchable {
+		reg.searchable = append(reg.searchable, info.structField)
+	}
+
+	if !info.filterable {
+		return
+	}
+
+	reg.fields = append(reg.fields, info)
+	reg.byColumn[info.column] = info
+}
+
+// parseStructTags builds the field registry of T from its gofilter tags.
+// Columns default to the snake_case of the field name, or to the json tag
+// name when jsonColumns is set.
+func parseStructTags[T any](jsonColumns bool) (*fieldRegistry, error) {
+	if reg, ok := registeredSchema[T](); ok {
+		return reg, nil
+	}
+
 	var zero T
 	t := reflect.TypeOf(zero)
 	if t.Kind() == reflect.Ptr {
@@ -43,6 +81,11 @@ func parseStructTags[T any]() (*fieldRegistry, error) {
 			column:      toSnakeCase(sf.Name),
 			fieldType:   sf.Type,
 		}
+		if jsonColumns {
+			if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+				info.column = name
+			}
+		}
 
 		for _, part := range parts {
 			part = strings.TrimSpace(part)
@@ -51,17 +94,16 @@ func parseStructTags[T any]() (*fieldRegistry, error) {
 				info.filterable = true
 			case part == "sortable":
 				info.sortable = true
+			case part == "searchable":
+				info.searchable = true
 			case strings.HasPrefix(part, "column="):
 				info.column = strings.TrimPrefix(part, "column=")
+			case strings.HasPrefix(part, "layout="):
+				info.layouts = append(info.layouts, strings.TrimPrefix(part, "layout="))
 			}
 		}
 
-		if !info.filterable {
-			continue
-		}
-
-		reg.fields = append(reg.fields, info)
-		reg.byColumn[info.column] = info
+		reg.add(info)
 	}
 
 	return reg, nil
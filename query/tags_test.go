@@ -1,6 +1,7 @@
 package query
 
 import (
+	"slices"
 	"testing"
 	"time"
 )
@@ -16,7 +17,7 @@ type TagTestUser struct {
 }
 
 func TestParseStructTags(t *testing.T) {
-	registry, err := parseStructTags[TagTestUser]()
+	registry, err := parseStructTags[TagTestUser](false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -77,3 +78,76 @@ func TestToSnakeCase(t *testing.T) {
 		}
 	}
 }
+
+func TestParseStructTagsSearchable(t *testing.T) {
+	type Doc struct {
+		Title string `gofilter:"filterable,searchable"`
+		Body  string `gofilter:"searchable"`
+		Year  int    `gofilter:"filterable"`
+	}
+	registry, err := parseStructTags[Doc](false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(registry.searchable) != 2 || registry.searchable[0] != "Title" || registry.searchable[1] != "Body" {
+		t.Errorf("expected searchable fields [Title Body], got %v", registry.searchable)
+	}
+	if _, ok := registry.byColumn["body"]; ok {
+		t.Error("Body is only searchable and should not be filterable")
+	}
+}
+
+func TestParseStructTagsJSONColumns(t *testing.T) {
+	type Post struct {
+		Title     string    `json:"title" gofilter:"filterable"`
+		CreatedAt time.Time `json:"createdAt,omitempty" gofilter:"filterable,sortable"`
+		AuthorID  int       `json:"-" gofilter:"filterable"`
+		Views     int       `json:",omitempty" gofilter:"filterable"`
+		Slug      string    `json:"slug" gofilter:"filterable,column=path"`
+	}
+
+	tests := []struct {
+		jsonColumns bool
+		want        []string
+	}{
+		{false, []string{"title", "created_at", "author_id", "views", "path"}},
+		{true, []string{"title", "createdAt", "author_id", "views", "path"}},
+	}
+
+	for _, tt := range tests {
+		registry, err := parseStructTags[Post](tt.jsonColumns)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i, f := range registry.fields {
+			if f.column != tt.want[i] {
+				t.Errorf("jsonColumns=%v: expected column %q for %s, got %q", tt.jsonColumns, tt.want[i], f.structField, f.column)
+			}
+		}
+	}
+}
+
+func TestParseStructTagsLayouts(t *testing.T) {
+	type Invoice struct {
+		IssuedOn string `gofilter:"filterable,layout=02/01/2006,layout=2006-01-02"`
+		DueOn    string `gofilter:"filterable"`
+	}
+
+	registry, err := parseStructTags[Invoice](false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	issued := registry.byColumn["issued_on"]
+	if want := []string{"02/01/2006", "2006-01-02"}; !slices.Equal(issued.layouts, want) {
+		t.Errorf("expected layouts %v, got %v", want, issued.layouts)
+	}
+	opts := issued.dateOptions(time.UTC)
+	if opts == nil || opts.Location != time.UTC {
+		t.Errorf("expected date options in UTC, got %+v", opts)
+	}
+	if opts := registry.byColumn["due_on"].dateOptions(time.UTC); opts != nil {
+		t.Errorf("expected no date options without layouts, got %+v", opts)
+	}
+}
@@ -0,0 +1,19 @@
+package store
+
+import "fmt"
+
+// ErrDuplicateID is returned by Insert when an item with the same id
+// is already stored.
+type ErrDuplicateID struct{ ID interface{} }
+
+func (e *ErrDuplicateID) Error() string {
+	return fmt.Sprintf("an item with id %v already exists", e.ID)
+}
+
+// ErrInvalidID is returned when an item's id cannot be read, or an id
+// cannot be converted to the type of the id field.
+type ErrInvalidID struct{ ID interface{} }
+
+func (e *ErrInvalidID) Error() string {
+	return fmt.Sprintf("invalid id %v", e.ID)
+}
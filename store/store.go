@@ -0,0 +1,351 @@
+// Package store provides a concurrency-safe, mutable collection of structs
+// with automatically maintained secondary indexes. It reuses the filter and
+// query packages, so the same filters and query strings work against a
+// collection that changes over time.
+//
+// Example:
+//
+//	type User struct {
+//	    ID   int    `gofilter:"id"`
+//	    Name string `gofilter:"filterable,sortable"`
+//	    City string `gofilter:"filterable"`
+//	}
+//
+//	users, err := store.New[User](store.WithHashIndex("City"))
+//	users.Insert(User{ID: 1, Name: "Ana", City: "SP"})
+//	page, err := users.Query(r.URL.Query())
+//
+// Watch turns a filter into a standing query that reports items entering and
+// leaving the filtered view, and SSEHandler streams those events over HTTP.
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/sidneip/gofilter/filter"
+	"github.com/sidneip/gofilter/index"
+	"github.com/sidneip/gofilter/query"
+)
+
+type indexSpec struct {
+	field string
+	kind  string
+}
+
+type options struct {
+	idField string
+	indexes []indexSpec
+}
+
+// Option is a functional option for configuring a Collection.
+// Use WithIDField, WithHashIndex, WithRangeIndex and WithPrefixIndex to create options.
+type Option func(*options)
+
+// WithIDField sets the field used as the item id, instead of the field
+// tagged with `gofilter:"id"`.
+//
+// Example:
+//
+//	store.New[User](store.WithIDField("Email"))
+func WithIDField(field string) Option {
+	return func(o *options) {
+		o.idField = field
+	}
+}
+
+// WithHashIndex maintains an index.HashIndex on a field.
+//
+// Example:
+//
+//	store.New[User](store.WithHashIndex("City"))
+func WithHashIndex(field string) Option {
+	return func(o *options) {
+		o.indexes = append(o.indexes, indexSpec{field: field, kind: "hash"})
+	}
+}
+
+// WithRangeIndex maintains an index.RangeIndex on a field.
+//
+// Example:
+//
+//	store.New[User](store.WithRangeIndex("Age"))
+func WithRangeIndex(field string) Option {
+	return func(o *options) {
+		o.indexes = append(o.indexes, indexSpec{field: field, kind: "range"})
+	}
+}
+
+// WithPrefixIndex maintains an index.PrefixIndex on a string field.
+//
+// Example:
+//
+//	store.New[User](store.WithPrefixIndex("Name"))
+func WithPrefixIndex(field string) Option {
+	return func(o *options) {
+		o.indexes = append(o.indexes, indexSpec{field: field, kind: "prefix"})
+	}
+}
+
+// Collection is a set of items keyed by id, safe for concurrent use.
+// Reads run in parallel with each other and are serialized with writes.
+type Collection[T any] struct {
+	mu       sync.RWMutex
+	idField  string
+	idType   reflect.Type
+	set      *index.Set[T]
+	byID     map[interface{}]int
+	watchers map[*watcher[T]]bool
+}
+
+// New creates an empty collection. The id field is the field tagged with
+// `gofilter:"id"` unless WithIDField is given; it returns an error if there
+// is no id field or an index cannot be built.
+//
+// Example:
+//
+//	users, err := store.New[User](
+//	    store.WithHashIndex("City"),
+//	    store.WithRangeIndex("Age"),
+//	)
+func New[T any](opts ...Option) (*Collection[T], error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("store: %s is not a struct", t)
+	}
+
+	idField := o.idField
+	if idField == "" {
+		idField = taggedIDField(t)
+	}
+	if idField == "" {
+		return nil, fmt.Errorf(`store: %s has no field tagged gofilter:"id"`, t)
+	}
+	sf, ok := t.FieldByName(idField)
+	if !ok {
+		return nil, fmt.Errorf("store: id field %s not found in %s", idField, t)
+	}
+	if !sf.Type.Comparable() {
+		return nil, fmt.Errorf("store: id field %s of type %s is not comparable", idField, sf.Type)
+	}
+
+	indexes := make([]index.Index[T], 0, len(o.indexes))
+	for _, spec := range o.indexes {
+		var idx index.Index[T]
+		var err error
+		switch spec.kind {
+		case "hash":
+			idx, err = index.NewHashIndex[T](nil, spec.field)
+		case "range":
+			idx, err = index.NewRangeIndex[T](nil, spec.field)
+		case "prefix":
+			idx, err = index.NewPrefixIndex[T](nil, spec.field)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("store: %w", err)
+		}
+		indexes = append(indexes, idx)
+	}
+
+	return &Collection[T]{
+		idField: idField,
+		idType:  sf.Type,
+		set:     index.NewSet[T](nil, indexes...),
+		byID:    make(map[interface{}]int),
+	}, nil
+}
+
+// taggedIDField returns the name of the field tagged with "id"
+func taggedIDField(t reflect.Type) string {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		for _, part := range strings.Split(sf.Tag.Get("gofilter"), ",") {
+			if strings.TrimSpace(part) == "id" {
+				return sf.Name
+			}
+		}
+	}
+	return ""
+}
+
+// itemID returns the id of an item
+func (c *Collection[T]) itemID(item T) (interface{}, error) {
+	v, err := filter.ExportedGetFieldValue(item, c.idField)
+	if err != nil || !v.CanInterface() {
+		return nil, &ErrInvalidID{ID: c.idField}
+	}
+	return v.Interface(), nil
+}
+
+// normalizeID converts an id to the type of the id field. Ids the field
+// cannot hold exactly, such as 1.9 or -1 for an unsigned id, are invalid.
+func (c *Collection[T]) normalizeID(id interface{}) (interface{}, error) {
+	v := reflect.ValueOf(id)
+	if !v.IsValid() {
+		return nil, &ErrInvalidID{ID: id}
+	}
+	if v.Type() == c.idType {
+		return id, nil
+	}
+	if (v.Kind() == reflect.String) != (c.idType.Kind() == reflect.String) {
+		return nil, &ErrInvalidID{ID: id}
+	}
+	converted, ok := filter.ExportedConvertExact(v, c.idType)
+	if !ok {
+		return nil, &ErrInvalidID{ID: id}
+	}
+	return converted.Interface(), nil
+}
+
+// Insert adds an item to the collection.
+// It returns ErrDuplicateID if an item with the same id exists.
+func (c *Collection[T]) Insert(item T) error {
+	id, err := c.itemID(item)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.byID[id]; exists {
+		return &ErrDuplicateID{ID: id}
+	}
+	c.byID[id] = c.set.Insert(item)
+
+	var zero T
+	c.notify(zero, false, item, true)
+	return nil
+}
+
+// Upsert adds an item to the collection, replacing any item with the same id.
+func (c *Collection[T]) Upsert(item T) error {
+	id, err := c.itemID(item)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if pos, exists := c.byID[id]; exists {
+		old, _ := c.set.At(pos)
+		c.set.Update(pos, item)
+		c.notify(old, true, item, true)
+		return nil
+	}
+	c.byID[id] = c.set.Insert(item)
+
+	var zero T
+	c.notify(zero, false, item, true)
+	return nil
+}
+
+// Delete removes the item with the given id and reports whether it existed.
+func (c *Collection[T]) Delete(id interface{}) bool {
+	id, err := c.normalizeID(id)
+	if err != nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pos, exists := c.byID[id]
+	if !exists {
+		return false
+	}
+	old, _ := c.set.At(pos)
+	c.set.Delete(pos)
+	delete(c.byID, id)
+
+	var zero T
+	c.notify(old, true, zero, false)
+	return true
+}
+
+// Get returns the item with the given id.
+func (c *Collection[T]) Get(id interface{}) (T, bool) {
+	var zero T
+	id, err := c.normalizeID(id)
+	if err != nil {
+		return zero, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pos, exists := c.byID[id]
+	if !exists {
+		return zero, false
+	}
+	return c.set.At(pos)
+}
+
+// Len returns the number of items in the collection.
+func (c *Collection[T]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.set.Len()
+}
+
+// All returns a copy of all items in the collection.
+func (c *Collection[T]) All() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	items := c.set.Items()
+	result := make([]T, len(items))
+	copy(result, items)
+	return result
+}
+
+// Find returns the items passing the filter, answered from the collection's
+// indexes where possible.
+//
+// Example:
+//
+//	adults := users.Find(filter.Gte[User]("Age", 18))
+func (c *Collection[T]) Find(f filter.Filter[T]) []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.set.Apply(f)
+}
+
+// Query filters, sorts and paginates the collection from URL query parameters,
+// with the same syntax and options as query.ApplyPaginated. Filters are
+// answered from the collection's indexes where possible.
+//
+// Example:
+//
+//	// GET /users?city=SP&sort=-age&page=1&limit=10
+//	page, err := users.Query(r.URL.Query(), query.WithMaxLimit(100))
+func (c *Collection[T]) Query(params url.Values, opts ...query.Option) (*query.PageResult[T], error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	opts = append(opts[:len(opts):len(opts)], query.WithIndex(c.set))
+	page, err := query.ApplyPaginated(c.set.Items(), params, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The page may share memory with the collection, which changes once the lock is released
+	items := make([]T, len(page.Items))
+	copy(items, page.Items)
+	page.Items = items
+	return page, nil
+}
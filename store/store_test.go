@@ -0,0 +1,215 @@
+package store
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+type User struct {
+	ID   int    `gofilter:"id"`
+	Name string `gofilter:"filterable,sortable"`
+	Age  int    `gofilter:"filterable,sortable"`
+	City string `gofilter:"filterable"`
+}
+
+func testCollection(t *testing.T) *Collection[User] {
+	t.Helper()
+	c, err := New[User](WithHashIndex("City"), WithRangeIndex("Age"), WithPrefixIndex("Name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := []User{
+		{ID: 1, Name: "Ana", Age: 20, City: "SP"},
+		{ID: 2, Name: "Bruno", Age: 17, City: "RJ"},
+		{ID: 3, Name: "Carla", Age: 25, City: "SP"},
+		{ID: 4, Name: "Daniel", Age: 30, City: "MG"},
+	}
+	for _, u := range users {
+		if err := c.Insert(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func TestNewErrors(t *testing.T) {
+	type NoID struct {
+		Name string
+	}
+	if _, err := New[NoID](); err == nil {
+		t.Error("expected error for type without id field")
+	}
+	if _, err := New[NoID](WithIDField("Name")); err != nil {
+		t.Errorf("unexpected error with WithIDField: %v", err)
+	}
+	if _, err := New[User](WithHashIndex("Missing")); err == nil {
+		t.Error("expected error for index on missing field")
+	}
+	if _, err := New[int](); err == nil {
+		t.Error("expected error for non-struct type")
+	}
+}
+
+func TestInsertAndGet(t *testing.T) {
+	c := testCollection(t)
+
+	if c.Len() != 4 {
+		t.Errorf("expected 4 items, got %d", c.Len())
+	}
+
+	u, ok := c.Get(3)
+	if !ok || u.Name != "Carla" {
+		t.Errorf("expected Carla, got %v (ok=%v)", u, ok)
+	}
+
+	if _, ok := c.Get(int64(3)); !ok {
+		t.Error("expected ids of a convertible type to be found")
+	}
+	if _, ok := c.Get("3"); ok {
+		t.Error("expected string id not to match an int id field")
+	}
+
+	err := c.Insert(User{ID: 3, Name: "Other"})
+	var dup *ErrDuplicateID
+	if !errors.As(err, &dup) {
+		t.Errorf("expected ErrDuplicateID, got %T: %v", err, err)
+	}
+}
+
+func TestNormalizeID(t *testing.T) {
+	c := testCollection(t)
+
+	if _, err := c.normalizeID(1.9); err == nil {
+		t.Error("expected ErrInvalidID for a fractional id")
+	}
+	if id, err := c.normalizeID(2.0); err != nil || id != 2 {
+		t.Errorf("expected id 2 for 2.0, got %v, %v", id, err)
+	}
+	if _, ok := c.Get(1.9); ok {
+		t.Error("expected Get(1.9) to miss instead of returning id 1")
+	}
+
+	type Counter struct {
+		ID uint8 `gofilter:"id"`
+	}
+	counters, err := New[Counter]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := counters.Insert(Counter{ID: 255}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []interface{}{-1, 511, uint64(1) << 40} {
+		var invalid *ErrInvalidID
+		if _, err := counters.normalizeID(id); !errors.As(err, &invalid) {
+			t.Errorf("expected ErrInvalidID for %v, got %v", id, err)
+		}
+	}
+	if _, ok := counters.Get(-1); ok {
+		t.Error("expected Get(-1) to miss instead of wrapping to 255")
+	}
+}
+
+func TestUpsertReindexes(t *testing.T) {
+	c := testCollection(t)
+
+	if err := c.Upsert(User{ID: 1, Name: "Ana", Age: 21, City: "RJ"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Upsert(User{ID: 5, Name: "Elena", Age: 22, City: "RJ"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.Find(filter.Eq[User]("City", "SP")); len(got) != 1 || got[0].Name != "Carla" {
+		t.Errorf("expected only Carla in SP after moving Ana, got %v", got)
+	}
+	if got := c.Find(filter.Eq[User]("City", "RJ")); len(got) != 3 {
+		t.Errorf("expected 3 users in RJ, got %v", got)
+	}
+	if c.Len() != 5 {
+		t.Errorf("expected 5 items, got %d", c.Len())
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c := testCollection(t)
+
+	if !c.Delete(1) {
+		t.Fatal("expected Delete to report an existing item")
+	}
+	if c.Delete(1) {
+		t.Error("expected second Delete to report a missing item")
+	}
+	if _, ok := c.Get(1); ok {
+		t.Error("expected deleted item to be gone")
+	}
+	if got := c.Find(filter.Eq[User]("City", "SP")); len(got) != 1 {
+		t.Errorf("expected 1 user in SP after delete, got %v", got)
+	}
+	if got := c.Find(filter.Contains[User]("Name", "a")); len(got) != 2 {
+		t.Errorf("expected scans to skip deleted items, got %v", got)
+	}
+
+	// Freed positions are reused
+	if err := c.Insert(User{ID: 6, Name: "Felipe", Age: 28, City: "SP"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Find(filter.Gte[User]("Age", 25)); len(got) != 3 {
+		t.Errorf("expected 3 users aged 25+, got %v", got)
+	}
+	if len(c.All()) != 4 {
+		t.Errorf("expected 4 items, got %d", len(c.All()))
+	}
+}
+
+func TestQuery(t *testing.T) {
+	c := testCollection(t)
+
+	params := url.Values{"city": {"SP"}, "sort": {"-age"}}
+	page, err := c.Query(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || page.Items[0].Name != "Carla" {
+		t.Errorf("expected Carla first of 2, got %+v", page)
+	}
+
+	if _, err := c.Query(url.Values{"id": {"1"}}); err == nil {
+		t.Error("expected error for non-filterable id")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	c := testCollection(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id := 100 + i*100 + j
+				c.Upsert(User{ID: id, Name: "User", Age: j, City: "SP"})
+				if j%2 == 0 {
+					c.Delete(id)
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Find(filter.Eq[User]("City", "SP"))
+				c.Query(url.Values{"age_gte": {"10"}})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.Len() != 4+8*50 {
+		t.Errorf("expected %d items, got %d", 4+8*50, c.Len())
+	}
+}
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sidneip/gofilter/filter"
+	"github.com/sidneip/gofilter/query"
+)
+
+// EventType identifies how a write changed a watched view.
+type EventType string
+
+const (
+	// Added means an item started matching the watched filter
+	Added EventType = "added"
+	// Updated means an item that matched the filter changed and still matches
+	Updated EventType = "updated"
+	// Removed means an item stopped matching the filter or was deleted
+	Removed EventType = "removed"
+	// Overflow means the watcher fell too far behind; it is the last event
+	// before the channel is closed, and the view must be reloaded
+	Overflow EventType = "overflow"
+)
+
+// Event describes a change to the items matching a watched filter.
+type Event[T any] struct {
+	// Type is the kind of change
+	Type EventType
+	// Item is the new item for Added and Updated, and the last matching
+	// version of the item for Removed
+	Item T
+}
+
+type watchOptions struct {
+	buffer int
+}
+
+// WatchOption is a functional option for configuring Watch.
+type WatchOption func(*watchOptions)
+
+// WithBuffer sets how many undelivered events a watcher may hold before it
+// overflows. The default is 64.
+//
+// Example:
+//
+//	ch := users.Watch(ctx, f, store.WithBuffer(1024))
+func WithBuffer(n int) WatchOption {
+	return func(o *watchOptions) {
+		o.buffer = n
+	}
+}
+
+// watcher is a subscriber to the changes matching a filter
+type watcher[T any] struct {
+	filter filter.Filter[T]
+	events chan Event[T]
+	buffer int
+	closed bool
+	// done is closed with events, so the goroutine waiting on the watch's
+	// context exits when the watcher overflows
+	done chan struct{}
+}
+
+// send delivers an event without blocking. When the buffer is full, the
+// watcher receives an Overflow event and is closed, and the caller must
+// remove it from c.watchers. Must hold c.mu.
+func (w *watcher[T]) send(e Event[T]) {
+	if w.closed {
+		return
+	}
+	if len(w.events) < w.buffer {
+		w.events <- e
+		return
+	}
+	w.events <- Event[T]{Type: Overflow}
+	w.close()
+}
+
+// close closes the events channel once. Must hold c.mu.
+func (w *watcher[T]) close() {
+	if !w.closed {
+		w.closed = true
+		close(w.events)
+		close(w.done)
+	}
+}
+
+// Watch subscribes to the items matching a filter. Every write that makes an
+// item start matching, change while matching, or stop matching sends an event.
+// Items already in the collection are not reported; use Find for the initial view.
+//
+// Writes never wait for watchers. A watcher that falls WithBuffer events behind
+// receives an Overflow event and its channel is closed. The channel is also
+// closed when ctx is done.
+//
+// Example:
+//
+//	for e := range users.Watch(ctx, filter.Eq[User]("City", "SP")) {
+//	    fmt.Println(e.Type, e.Item.Name)
+//	}
+func (c *Collection[T]) Watch(ctx context.Context, f filter.Filter[T], opts ...WatchOption) <-chan Event[T] {
+	o := watchOptions{buffer: 64}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.buffer < 1 {
+		o.buffer = 1
+	}
+
+	// One extra slot is reserved for the Overflow event
+	w := &watcher[T]{
+		filter: f,
+		events: make(chan Event[T], o.buffer+1),
+		buffer: o.buffer,
+		done:   make(chan struct{}),
+	}
+
+	c.mu.Lock()
+	if c.watchers == nil {
+		c.watchers = make(map[*watcher[T]]bool)
+	}
+	c.watchers[w] = true
+	c.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-w.done:
+			// Overflowed: notify already removed the watcher
+			return
+		}
+		c.mu.Lock()
+		delete(c.watchers, w)
+		w.close()
+		c.mu.Unlock()
+	}()
+
+	return w.events
+}
+
+// notify sends the events caused by a write replacing before with after to
+// every watcher. hadBefore and hasAfter report whether each version exists.
+// Must hold c.mu.
+func (c *Collection[T]) notify(before T, hadBefore bool, after T, hasAfter bool) {
+	for w := range c.watchers {
+		matchedBefore := hadBefore && w.filter.Apply(before)
+		matchesAfter := hasAfter && w.filter.Apply(after)
+
+		switch {
+		case !matchedBefore && matchesAfter:
+			w.send(Event[T]{Type: Added, Item: after})
+		case matchedBefore && matchesAfter:
+			w.send(Event[T]{Type: Updated, Item: after})
+		case matchedBefore && !matchesAfter:
+			w.send(Event[T]{Type: Removed, Item: before})
+		}
+		if w.closed {
+			delete(c.watchers, w)
+		}
+	}
+}
+
+// SSEHandler returns an http.Handler that streams the changes to a collection
+// as Server-Sent Events. The request's query parameters select the items to
+// watch, with the same filter syntax and options as query.Apply; sort and
+// pagination parameters are accepted but have no effect on the stream.
+// Each event is named after its EventType and carries the item as JSON.
+//
+// Example:
+//
+//	// GET /users/events?city=SP
+//	http.Handle("GET /users/events", store.SSEHandler(users))
+func SSEHandler[T any](c *Collection[T], opts ...query.Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := query.BuildFilter[T](r.URL.Query(), opts...)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for e := range c.Watch(r.Context(), f) {
+			data := []byte("null")
+			if e.Type != Overflow {
+				if data, err = json.Marshal(e.Item); err != nil {
+					return
+				}
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sidneip/gofilter/filter"
+)
+
+func nextEvent(t *testing.T, ch <-chan Event[User]) Event[User] {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed unexpectedly")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event[User]{}
+}
+
+func TestWatch(t *testing.T) {
+	c := testCollection(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := c.Watch(ctx, filter.Eq[User]("City", "SP"))
+
+	c.Insert(User{ID: 10, Name: "Rita", City: "RJ"})        // never matches
+	c.Insert(User{ID: 11, Name: "Sara", City: "SP"})        // added
+	c.Upsert(User{ID: 11, Name: "Sara Lima", City: "SP"})   // updated
+	c.Upsert(User{ID: 10, Name: "Rita", City: "SP"})        // added
+	c.Upsert(User{ID: 1, Name: "Ana", Age: 20, City: "MG"}) // removed
+	c.Delete(11)                                            // removed
+	c.Delete(2)                                             // never matched
+
+	expected := []struct {
+		typ  EventType
+		name string
+	}{
+		{Added, "Sara"},
+		{Updated, "Sara Lima"},
+		{Added, "Rita"},
+		{Removed, "Ana"},
+		{Removed, "Sara Lima"},
+	}
+	for _, want := range expected {
+		e := nextEvent(t, ch)
+		if e.Type != want.typ || e.Item.Name != want.name {
+			t.Errorf("expected %s %s, got %s %s", want.typ, want.name, e.Type, e.Item.Name)
+		}
+	}
+
+	select {
+	case e := <-ch:
+		t.Errorf("unexpected event %s %s", e.Type, e.Item.Name)
+	default:
+	}
+}
+
+func TestWatchClosesOnCancel(t *testing.T) {
+	c := testCollection(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := c.Watch(ctx, filter.Eq[User]("City", "SP"))
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("expected no events after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+
+	// Writes after the watcher is gone must not block or panic
+	c.Insert(User{ID: 20, City: "SP"})
+}
+
+func TestWatchOverflow(t *testing.T) {
+	c := testCollection(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := c.Watch(ctx, filter.Eq[User]("City", "SP"), WithBuffer(2))
+	for id := 100; id < 110; id++ {
+		c.Insert(User{ID: id, City: "SP"})
+	}
+
+	var types []EventType
+	for e := range ch {
+		types = append(types, e.Type)
+	}
+
+	if len(types) != 3 || types[0] != Added || types[1] != Added || types[2] != Overflow {
+		t.Errorf("expected 2 added events and an overflow, got %v", types)
+	}
+
+	// A watcher with a context that is never done is still released
+	c.Watch(context.Background(), filter.Eq[User]("City", "SP"), WithBuffer(1))
+	for id := 200; id < 203; id++ {
+		c.Insert(User{ID: id, City: "SP"})
+	}
+	c.mu.Lock()
+	watchers := len(c.watchers)
+	c.mu.Unlock()
+	if watchers != 0 {
+		t.Errorf("expected overflowed watchers to be removed, got %d", watchers)
+	}
+}
+
+func TestSSEHandler(t *testing.T) {
+	c := testCollection(t)
+	server := httptest.NewServer(SSEHandler(c))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?city=SP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+
+	go func() {
+		// Wait for the handler to subscribe before writing
+		for {
+			c.mu.RLock()
+			n := len(c.watchers)
+			c.mu.RUnlock()
+			if n > 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		c.Insert(User{ID: 30, Name: "Tina", City: "RJ"})
+		c.Insert(User{ID: 31, Name: "Vera", City: "SP"})
+	}()
+
+	reader := bufio.NewReader(resp.Body)
+	event, _ := reader.ReadString('\n')
+	data, _ := reader.ReadString('\n')
+
+	if event != "event: added\n" {
+		t.Errorf("expected added event, got %q", event)
+	}
+	if !strings.Contains(data, `"Name":"Vera"`) {
+		t.Errorf("expected Vera in data, got %q", data)
+	}
+}
+
+func TestSSEHandlerInvalidQuery(t *testing.T) {
+	c := testCollection(t)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/?id=1", nil)
+
+	SSEHandler(c).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}